@@ -0,0 +1,199 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DumpNode is a serialisable description of a node, used to inspect the output of the parser. Its JSON encoding is
+// stable: fields are always written in the same order, and attributes are sorted by name
+type DumpNode struct {
+	Type       NodeType          `json:"type"`
+	Field      string            `json:"field,omitempty"` // the field of the parent node holding this node
+	File       string            `json:"file"`
+	Line       int               `json:"line"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Children   []*DumpNode       `json:"children,omitempty"`
+}
+
+// Dump describes the tree rooted at node, children are listed in source order
+func Dump(node Node) *DumpNode {
+	d := &DumpNode{
+		Type:       node.Type(),
+		File:       node.FileName(),
+		Line:       node.LineNumber(),
+		Attributes: make(map[string]string),
+		Children:   make([]*DumpNode, 0),
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		d.addStatements("statements", n.Statements)
+
+	// expressions
+	case *IdentifierExpression:
+		d.Attributes["name"] = n.Name
+	case *IntegerExpression:
+		d.Attributes["value"] = n.String()
+	case *FloatingPointExpression:
+		d.Attributes["value"] = strconv.FormatFloat(n.Value, 'g', -1, 64)
+	case *StringExpression:
+		d.Attributes["value"] = n.Literal
+	case *InterpolatedStringExpression:
+		// the position of each expression is marked by ${}
+		d.Attributes["format"] = strings.Join(n.Segments, "${}")
+		d.addExpressions("expressions", n.Expressions)
+	case *BooleanExpression:
+		d.Attributes["value"] = n.String()
+	case *PrefixExpression:
+		d.Attributes["operator"] = n.Literal()
+		d.addChild("expression", n.Expression)
+	case *SafeCallExpression:
+		d.addChild("call", n.Call)
+	case *InfixExpression:
+		d.Attributes["operator"] = n.Literal()
+		d.addChild("left", n.LeftExpression)
+		d.addChild("right", n.RightExpression)
+	case *ArrayExpression:
+		d.addExpressions("elements", n.Data)
+	case *ArrayIndexExpression:
+		d.Attributes["array"] = n.ArrayName
+		d.addChild("index", n.IndexExpr)
+	case *FunctionCallExpression:
+		d.Attributes["name"] = n.FunctionName
+		if n.Module != "" {
+			d.Attributes["module"] = n.Module
+		}
+		d.addExpressions("arguments", n.Parameters)
+	case *StructExpression:
+		d.Attributes["name"] = n.Name
+		for i := range n.Fields {
+			d.addChild("fields", &n.Fields[i])
+			d.addChild("values", n.Values[i])
+		}
+	case *FieldExpression:
+		d.Attributes["field"] = n.Field
+		d.addChild("target", n.Target)
+
+	// statements
+	case *VarStatement:
+		if n.Exported {
+			d.Attributes["exported"] = "true"
+		}
+		if n.TypeAnnotation != nil {
+			d.Attributes["annotation"] = n.TypeAnnotation.String()
+		}
+		d.addChild("identifier", n.IdentifierNode)
+		d.addChild("expression", n.Expression)
+	case *AssignmentStatement:
+		d.addChild("identifier", n.IdentifierNode)
+		d.addChild("expression", n.Expression)
+	case *FieldAssignmentStatement:
+		d.addChild("field", n.Field)
+		d.addChild("expression", n.Expression)
+	case *ReturnStatement:
+		if n.Expression != nil {
+			d.addChild("expression", n.Expression)
+		}
+	case *IfStatement:
+		d.addChild("condition", n.Condition)
+		d.addStatements("if", n.IfBlock)
+		d.addStatements("else", n.ElseBlock)
+	case *WhileStatement:
+		d.addChild("condition", n.Condition)
+		d.addStatements("body", n.Block)
+	case *ThrowStatement:
+		d.addChild("expression", n.Expression)
+	case *TryStatement:
+		d.addStatements("try", n.TryBlock)
+		if n.CatchName != nil {
+			d.Attributes["catch"] = n.CatchName.Name
+		}
+		d.addStatements("catch", n.CatchBlock)
+		d.addStatements("finally", n.FinallyBlock)
+	case *FunctionDeclarationStatement:
+		d.Attributes["name"] = n.Name
+		if n.Exported {
+			d.Attributes["exported"] = "true"
+		}
+		if n.ReturnType != nil {
+			d.Attributes["returns"] = n.ReturnType.String()
+		}
+		for i := range n.Parameters {
+			d.addChild("parameters", &n.Parameters[i])
+			if n.ParameterTypes[i] != nil {
+				d.Children[i].Attributes["annotation"] = n.ParameterTypes[i].String()
+			}
+		}
+		d.addStatements("body", n.Body)
+	case *StructDeclarationStatement:
+		d.Attributes["name"] = n.Name
+		for i := range n.Fields {
+			d.addChild("fields", &n.Fields[i])
+		}
+	case *FunctionCallStatement:
+		d.addChild("call", n.FunctionCallExpression)
+	case *ImportStatement:
+		d.Attributes["path"] = n.Path
+		if n.Alias != "" {
+			d.Attributes["alias"] = n.Alias
+		}
+	}
+
+	return d
+}
+
+func (d *DumpNode) addChild(field string, n Node) {
+	c := Dump(n)
+	c.Field = field
+	d.Children = append(d.Children, c)
+}
+
+func (d *DumpNode) addStatements(field string, stmts []Statement) {
+	for _, s := range stmts {
+		d.addChild(field, s)
+	}
+}
+
+func (d *DumpNode) addExpressions(field string, exprs []Expression) {
+	for _, e := range exprs {
+		d.addChild(field, e)
+	}
+}
+
+// Fprint writes the tree described by d as indented text, one node per line
+func (d *DumpNode) Fprint(w io.Writer) error {
+	return d.fprint(w, 0)
+}
+
+func (d *DumpNode) fprint(w io.Writer, depth int) error {
+	line := strings.Repeat("  ", depth)
+	if d.Field != "" {
+		line += d.Field + ": "
+	}
+	line += fmt.Sprintf("%v %v:%v", d.Type, d.File, d.Line)
+
+	names := make([]string, 0, len(d.Attributes))
+	for name := range d.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		line += fmt.Sprintf(" %v=%q", name, d.Attributes[name])
+	}
+
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+
+	for _, c := range d.Children {
+		if err := c.fprint(w, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package ast_test
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"bytes"
+	"encoding/json"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestDumpText(t *testing.T) {
+	input := "func f(a: int): [int] { if (!a) { return [a]; } else { return g(a, 2.5); }; };\n" +
+		"var x = f(1);\nwhile (x[0] < 2) { x = \"s\"; };"
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "test.txt", []byte(input))
+
+	expected := `program expression test.txt:4
+  statements: function declaration statement test.txt:1 name="f" returns="[int]"
+    parameters: identifier expression test.txt:1 annotation="int" name="a"
+    body: if statement test.txt:1
+      condition: prefix expression test.txt:1 operator="!"
+        expression: identifier expression test.txt:1 name="a"
+      if: return statement test.txt:1
+        expression: array expression test.txt:1
+          elements: identifier expression test.txt:1 name="a"
+      else: return statement test.txt:1
+        expression: function call expression test.txt:1 name="g"
+          arguments: identifier expression test.txt:1 name="a"
+          arguments: floating point expression test.txt:1 value="2.5"
+  statements: variable declaration statement test.txt:2
+    identifier: identifier expression test.txt:2 name="x"
+    expression: function call expression test.txt:2 name="f"
+      arguments: integer expression test.txt:2 value="1"
+  statements: while statement test.txt:3
+    condition: infix expression test.txt:3 operator="<"
+      left: array index expression test.txt:3 array="x"
+        index: integer expression test.txt:3 value="0"
+      right: integer expression test.txt:3 value="2"
+    body: assignment statement test.txt:3
+      identifier: identifier expression test.txt:3 name="x"
+      expression: string expression test.txt:3 value="s"
+`
+
+	buff := bytes.Buffer{}
+	if err := ast.Dump(prog).Fprint(&buff); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if buff.String() != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, buff.String())
+	}
+}
+
+func TestDumpInterpolatedString(t *testing.T) {
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "test.txt", []byte("var s = \"a ${b} c ${1}\";"))
+
+	expected := `program expression test.txt:2
+  statements: variable declaration statement test.txt:1
+    identifier: identifier expression test.txt:1 name="s"
+    expression: interpolated string expression test.txt:1 format="a ${} c ${}"
+      expressions: identifier expression test.txt:1 name="b"
+      expressions: integer expression test.txt:1 value="1"
+`
+
+	buff := bytes.Buffer{}
+	if err := ast.Dump(prog).Fprint(&buff); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if buff.String() != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, buff.String())
+	}
+}
+
+func TestDumpField(t *testing.T) {
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "test.txt", []byte("ps[0].a.x = m.pi;"))
+
+	expected := `program expression test.txt:2
+  statements: field assignment statement test.txt:1
+    field: field expression test.txt:1 field="x"
+      target: field expression test.txt:1 field="a"
+        target: array index expression test.txt:1 array="ps"
+          index: integer expression test.txt:1 value="0"
+    expression: field expression test.txt:1 field="pi"
+      target: identifier expression test.txt:1 name="m"
+`
+
+	buff := bytes.Buffer{}
+	if err := ast.Dump(prog).Fprint(&buff); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if buff.String() != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, buff.String())
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "test.txt", []byte("print(1 + x, true);"))
+
+	expected := `{"type":"program expression","file":"test.txt","line":2,"children":[` +
+		`{"type":"function call statement","field":"statements","file":"test.txt","line":1,"children":[` +
+		`{"type":"function call expression","field":"call","file":"test.txt","line":1,"attributes":{"name":"print"},` +
+		`"children":[` +
+		`{"type":"infix expression","field":"arguments","file":"test.txt","line":1,"attributes":{"operator":"+"},` +
+		`"children":[` +
+		`{"type":"integer expression","field":"left","file":"test.txt","line":1,"attributes":{"value":"1"}},` +
+		`{"type":"identifier expression","field":"right","file":"test.txt","line":1,"attributes":{"name":"x"}}]},` +
+		`{"type":"boolean expression","field":"arguments","file":"test.txt","line":1,"attributes":{"value":"true"}}` +
+		`]}]}]}`
+
+	encoded, err := json.Marshal(ast.Dump(prog))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(encoded) != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, string(encoded))
+	}
+
+	// the encoding round trips
+	var decoded ast.DumpNode
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if reencoded, _ := json.Marshal(&decoded); string(reencoded) != expected {
+		t.Errorf("expected decoding and encoding to be lossless, got\n%v", string(reencoded))
+	}
+}
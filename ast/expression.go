@@ -30,6 +30,30 @@ func (p *PrefixExpression) Type() NodeType {
 
 func (p *PrefixExpression) expressionFunction() {}
 
+// SafeCallExpression evaluates a function call, whose value is the error the call raises, if any, rather than the
+// error ending the program
+type SafeCallExpression struct {
+	token.Metadata
+	Call *FunctionCallExpression
+}
+
+func NewSafeCallExpression(t token.Token, c *FunctionCallExpression) *SafeCallExpression {
+	return &SafeCallExpression{
+		Metadata: t.Data(),
+		Call:     c,
+	}
+}
+
+func (s *SafeCallExpression) String() string {
+	return fmt.Sprintf("(try %v)", s.Call.String())
+}
+
+func (s *SafeCallExpression) Type() NodeType {
+	return SafeCallExpressionNode
+}
+
+func (s *SafeCallExpression) expressionFunction() {}
+
 type InfixExpression struct {
 	token.Token
 	LeftExpression  Expression
@@ -125,6 +149,7 @@ func (be *BooleanExpression) expressionFunction() {}
 
 type FunctionCallExpression struct {
 	token.Metadata
+	Module       string // the qualifier of a function declared in an imported module, empty otherwise
 	FunctionName string
 	Parameters   []Expression
 }
@@ -138,7 +163,7 @@ func NewFunctionCallExpression(md token.Metadata, fName string, params ...Expres
 }
 
 func (fc *FunctionCallExpression) String() string {
-	return fmt.Sprintf("%v(%v)", fc.FunctionName, expressionArrayToString(fc.Parameters))
+	return fmt.Sprintf("%v(%v)", qualify(fc.Module, fc.FunctionName), expressionArrayToString(fc.Parameters))
 }
 
 func (fc *FunctionCallExpression) Type() NodeType {
@@ -169,6 +194,14 @@ func (i *IdentifierExpression) Type() NodeType {
 
 func (i *IdentifierExpression) expressionFunction() {}
 
+// qualify prefixes name with the qualifier of the module it is declared in, if any
+func qualify(module, name string) string {
+	if module == "" {
+		return name
+	}
+	return module + "." + name
+}
+
 func expressionArrayToString(staArr []Expression) string {
 	var strArr = make([]string, len(staArr))
 	for i, sta := range staArr {
@@ -188,8 +221,22 @@ func NewStringExpression(md token.Metadata, l string) *StringExpression {
 		Literal:  l}
 }
 
+// escaper replaces the characters of a string that are written as escape sequences
+var escaper = func() *strings.Replacer {
+	oldNew := make([]string, 0, 2*len(token.Escapes))
+	for seq, c := range token.Escapes {
+		oldNew = append(oldNew, c, seq)
+	}
+	return strings.NewReplacer(oldNew...)
+}()
+
+// Escape returns s as it is written between the quotation marks of a string literal
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
 func (s *StringExpression) String() string {
-	return fmt.Sprintf("\"%v\"", s.Literal)
+	return fmt.Sprintf("\"%v\"", Escape(s.Literal))
 }
 
 func (s *StringExpression) Type() NodeType {
@@ -198,6 +245,41 @@ func (s *StringExpression) Type() NodeType {
 
 func (s *StringExpression) expressionFunction() {}
 
+// InterpolatedStringExpression is a string with embedded expressions, such as "total: ${a + b}". Its text is split into
+// segments around the expressions, so there is always one more segment than there are expressions
+type InterpolatedStringExpression struct {
+	token.Metadata
+	Segments    []string
+	Expressions []Expression
+}
+
+func NewInterpolatedStringExpression(md token.Metadata, segs []string, exprs []Expression) *InterpolatedStringExpression {
+	return &InterpolatedStringExpression{
+		Metadata:    md,
+		Segments:    segs,
+		Expressions: exprs,
+	}
+}
+
+func (s *InterpolatedStringExpression) String() string {
+	buff := bytes.Buffer{}
+	buff.WriteString("\"")
+	for i, seg := range s.Segments {
+		buff.WriteString(Escape(seg))
+		if i < len(s.Expressions) {
+			buff.WriteString(fmt.Sprintf("${%v}", s.Expressions[i]))
+		}
+	}
+	buff.WriteString("\"")
+	return buff.String()
+}
+
+func (s *InterpolatedStringExpression) Type() NodeType {
+	return InterpolatedStringExpressionNode
+}
+
+func (s *InterpolatedStringExpression) expressionFunction() {}
+
 type ArrayExpression struct {
 	token.Metadata
 	Data   []Expression
@@ -246,7 +328,7 @@ func NewArrayIndexExpression(md token.Metadata, n string, e Expression) *ArrayIn
 }
 
 func (a *ArrayIndexExpression) String() string {
-	return fmt.Sprintf("%v[%v];", a.ArrayName, a.IndexExpr)
+	return fmt.Sprintf("%v[%v]", a.ArrayName, a.IndexExpr)
 }
 
 func (a *ArrayIndexExpression) Type() NodeType {
@@ -254,3 +336,71 @@ func (a *ArrayIndexExpression) Type() NodeType {
 }
 
 func (a *ArrayIndexExpression) expressionFunction() {}
+
+// StructExpression constructs an instance of a declared struct, such as Point{x: 1, y: 2}. Fields and Values are
+// parallel, the fields that are not listed are null
+type StructExpression struct {
+	token.Metadata
+	Name   string
+	Fields []IdentifierExpression
+	Values []Expression
+}
+
+func NewStructExpression(md token.Metadata, n string, fs []IdentifierExpression, vs []Expression) *StructExpression {
+	return &StructExpression{
+		Metadata: md,
+		Name:     n,
+		Fields:   fs,
+		Values:   vs,
+	}
+}
+
+func (s *StructExpression) String() string {
+	var fields = make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		fields[i] = fmt.Sprintf("%v: %v", f.Name, s.Values[i].String())
+	}
+	return fmt.Sprintf("%v{%v}", s.Name, strings.Join(fields, ", "))
+}
+
+func (s *StructExpression) Type() NodeType {
+	return StructExpressionNode
+}
+
+func (s *StructExpression) expressionFunction() {}
+
+// FieldExpression reads the field of the struct that Target evaluates to, such as p.x or ps[0].x. When Target is the
+// name of an imported module, such as m.pi, it reads the module's variable instead
+type FieldExpression struct {
+	token.Metadata
+	Target Expression
+	Field  string
+}
+
+func NewFieldExpression(md token.Metadata, target Expression, f string) *FieldExpression {
+	return &FieldExpression{
+		Metadata: md,
+		Target:   target,
+		Field:    f,
+	}
+}
+
+// Qualifier returns the name of Target, ok is false if Target is not an identifier. A field expression qualified by
+// the name of an import reads the module's variable
+func (f *FieldExpression) Qualifier() (name string, ok bool) {
+	iden, ok := f.Target.(*IdentifierExpression)
+	if !ok {
+		return "", false
+	}
+	return iden.Name, true
+}
+
+func (f *FieldExpression) String() string {
+	return fmt.Sprintf("%v.%v", f.Target.String(), f.Field)
+}
+
+func (f *FieldExpression) Type() NodeType {
+	return FieldExpressionNode
+}
+
+func (f *FieldExpression) expressionFunction() {}
@@ -7,18 +7,27 @@ const (
 	IdentifierExpressionNode         = "identifier expression"
 	ArrayExpressionNode              = "array expression"
 	ArrayIndexExpressionNode         = "array index expression"
+	StructExpressionNode             = "struct expression"
+	FieldExpressionNode              = "field expression"
 	PrefixExpressionNode             = "prefix expression"
 	InfixExpressionNode              = "infix expression"
 	IntegerExpressionNode            = "integer expression"
 	FloatingPointExpressionNode      = "floating point expression"
 	StringExpressionNode             = "string expression"
+	InterpolatedStringExpressionNode = "interpolated string expression"
 	BooleanExpressionNode            = "boolean expression"
 	FunctionCallExpressionNode       = "function call expression"
+	SafeCallExpressionNode           = "safe call expression"
 	VarStatementNode                 = "variable declaration statement"
 	AssignmentStatementNode          = "assignment statement"
+	FieldAssignmentStatementNode     = "field assignment statement"
 	ReturnStatementNode              = "return statement"
 	WhileStatementNode               = "while statement"
 	IfStatementNode                  = "if statement"
+	ThrowStatementNode               = "throw statement"
+	TryStatementNode                 = "try statement"
 	FunctionDeclarationStatementNode = "function declaration statement"
+	StructDeclarationStatementNode   = "struct declaration statement"
 	FunctionCallStatementNode        = "function call statement"
+	ImportStatementNode              = "import statement"
 )
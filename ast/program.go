@@ -8,6 +8,7 @@ import (
 type Program struct {
 	token.Metadata
 	Statements []Statement
+	Comments   []*Comment // in source order, only used for formatting
 }
 
 func NewProgram(m token.Metadata, s ...Statement) *Program {
@@ -17,26 +18,43 @@ func NewProgram(m token.Metadata, s ...Statement) *Program {
 	}
 }
 
-// moves imports, followed by func declarations to the start of the ProgramNode
+// moves imports, followed by struct and func declarations to the start of the ProgramNode
 func (p *Program) Hoist() {
 	var (
+		hoistedImports        = make([]Statement, 0)
 		hoistedStatementsDecs = make([]Statement, 0)
 		remainingStatements   = make([]Statement, 0)
 	)
 
 	for i := range p.Statements {
-		if p.Statements[i].Type() == FunctionDeclarationStatementNode {
+		switch p.Statements[i].Type() {
+		case ImportStatementNode:
+			hoistedImports = append(hoistedImports, p.Statements[i])
+		case StructDeclarationStatementNode, FunctionDeclarationStatementNode:
 			hoistedStatementsDecs = append(hoistedStatementsDecs, p.Statements[i])
-		} else {
+		default:
 			remainingStatements = append(remainingStatements, p.Statements[i])
 		}
 
 	}
 
-	p.Statements = append(hoistedStatementsDecs, remainingStatements...)
+	p.Statements = append(append(hoistedImports, hoistedStatementsDecs...), remainingStatements...)
 	return
 }
 
+// Comment is a line comment, Text includes the leading //
+type Comment struct {
+	token.Metadata
+	Text string
+}
+
+func NewComment(md token.Metadata, text string) *Comment {
+	return &Comment{
+		Metadata: md,
+		Text:     text,
+	}
+}
+
 func (p *Program) String() string {
 	lBuff := bytes.Buffer{}
 	for _, s := range p.Statements {
@@ -4,21 +4,26 @@ import (
 	"github.com/EricNRodriguez/yum/token"
 	"bytes"
 	"fmt"
+	"path"
 	"strings"
 )
 
 type VarStatement struct {
 	*AssignmentStatement
+	TypeAnnotation *TypeAnnotation // nil if the variable is not annotated
+	Exported       bool            // visible to the files that import the declaring file
 }
 
-func NewVarStatement(md token.Metadata, i *IdentifierExpression, e Expression) *VarStatement {
+func NewVarStatement(md token.Metadata, i *IdentifierExpression, ta *TypeAnnotation, e Expression) *VarStatement {
 	return &VarStatement{
 		AssignmentStatement: NewAssignmentStatement(md, i, e),
+		TypeAnnotation:      ta,
 	}
 }
 
 func (v *VarStatement) String() string {
-	return fmt.Sprintf("var %v", v.AssignmentStatement.String())
+	return export(fmt.Sprintf("var %v = %v;", annotate(v.IdentifierNode.String(), v.TypeAnnotation),
+		v.Expression.String()), v.Exported)
 }
 
 func (v *VarStatement) Type() NodeType {
@@ -49,6 +54,31 @@ func (as *AssignmentStatement) Type() NodeType {
 
 func (as *AssignmentStatement) statementFunction() {}
 
+// FieldAssignmentStatement assigns a field of a struct, such as p.x = 1 or ps[0].x = 1
+type FieldAssignmentStatement struct {
+	token.Metadata
+	Field      *FieldExpression
+	Expression Expression
+}
+
+func NewFieldAssignmentStatement(md token.Metadata, f *FieldExpression, e Expression) *FieldAssignmentStatement {
+	return &FieldAssignmentStatement{
+		Metadata:   md,
+		Field:      f,
+		Expression: e,
+	}
+}
+
+func (fas *FieldAssignmentStatement) String() string {
+	return fmt.Sprintf("%v = %v;", fas.Field.String(), fas.Expression.String())
+}
+
+func (fas *FieldAssignmentStatement) Type() NodeType {
+	return FieldAssignmentStatementNode
+}
+
+func (fas *FieldAssignmentStatement) statementFunction() {}
+
 type ReturnStatement struct {
 	token.Token
 	Expression Expression
@@ -100,9 +130,13 @@ type IfStatement struct {
 	Condition Expression // Should make a boolean expression type to classify expressions with conditionals
 	IfBlock   []Statement
 	ElseBlock []Statement
+
+	// positions of the closing braces, nil if unknown
+	IfBlockEnd   token.Metadata
+	ElseBlockEnd token.Metadata
 }
 
-func NewIfStatement(t token.Token, c Expression, tb, fb []Statement) Statement {
+func NewIfStatement(t token.Token, c Expression, tb, fb []Statement) *IfStatement {
 	return &IfStatement{
 		Metadata:  t.Data(),
 		Condition: c,
@@ -126,10 +160,75 @@ func (ifs *IfStatement) Type() NodeType {
 
 func (ifs *IfStatement) statementFunction() {}
 
+type ThrowStatement struct {
+	token.Token
+	Expression Expression
+}
+
+func NewThrowStatement(t token.Token, e Expression) *ThrowStatement {
+	return &ThrowStatement{
+		Token:      t,
+		Expression: e,
+	}
+}
+
+func (t *ThrowStatement) String() string {
+	return fmt.Sprintf("throw %v;", t.Expression.String())
+}
+
+func (t *ThrowStatement) Type() NodeType {
+	return ThrowStatementNode
+}
+
+func (t *ThrowStatement) statementFunction() {}
+
+// TryStatement evaluates its try block, passing an error raised by it to the catch block, then evaluates its finally
+// block. At least one of the catch and finally blocks is present
+type TryStatement struct {
+	token.Metadata
+	TryBlock     []Statement
+	CatchName    *IdentifierExpression // the variable the caught error is bound to, nil without a catch block
+	CatchBlock   []Statement
+	FinallyBlock []Statement // nil without a finally block
+
+	// positions of the closing braces, nil if unknown
+	TryBlockEnd     token.Metadata
+	CatchBlockEnd   token.Metadata
+	FinallyBlockEnd token.Metadata
+}
+
+func NewTryStatement(t token.Token, tb []Statement, cn *IdentifierExpression, cb, fb []Statement) *TryStatement {
+	return &TryStatement{
+		Metadata:     t.Data(),
+		TryBlock:     tb,
+		CatchName:    cn,
+		CatchBlock:   cb,
+		FinallyBlock: fb,
+	}
+}
+
+func (t *TryStatement) String() string {
+	s := fmt.Sprintf("try { %v }", statementArrayNodeToString(t.TryBlock))
+	if t.CatchName != nil {
+		s += fmt.Sprintf(" catch (%v) { %v }", t.CatchName.String(), statementArrayNodeToString(t.CatchBlock))
+	}
+	if t.FinallyBlock != nil {
+		s += fmt.Sprintf(" finally { %v }", statementArrayNodeToString(t.FinallyBlock))
+	}
+	return s + ";"
+}
+
+func (t *TryStatement) Type() NodeType {
+	return TryStatementNode
+}
+
+func (t *TryStatement) statementFunction() {}
+
 type WhileStatement struct {
 	token.Metadata
 	Condition Expression // Should make a boolean expression type to classify expressions with conditionals
 	Block     []Statement
+	BlockEnd  token.Metadata // position of the closing brace, nil if unknown
 }
 
 func NewWhileStatement(md token.Metadata, c Expression, b []Statement) *WhileStatement {
@@ -152,27 +251,37 @@ func (w *WhileStatement) statementFunction() {}
 
 type FunctionDeclarationStatement struct {
 	token.Metadata
-	Name       string
-	Parameters []IdentifierExpression
-	Body       []Statement
+	Name           string
+	Parameters     []IdentifierExpression
+	ParameterTypes []*TypeAnnotation // parallel to Parameters, nil entries for unannotated parameters
+	ReturnType     *TypeAnnotation   // nil if the return type is not annotated
+	Body           []Statement
+	BodyEnd        token.Metadata // position of the closing brace, nil if unknown
+	Exported       bool           // visible to the files that import the declaring file
 }
 
-func NewFuntionDeclarationStatement(t token.Token, n string, b []Statement, ps []IdentifierExpression) Statement {
+func NewFuntionDeclarationStatement(t token.Token, n string, b []Statement, ps []IdentifierExpression,
+	pts []*TypeAnnotation, rt *TypeAnnotation) *FunctionDeclarationStatement {
+	if pts == nil {
+		pts = make([]*TypeAnnotation, len(ps))
+	}
 	return &FunctionDeclarationStatement{
-		Metadata:   t.Data(),
-		Name:       n,
-		Parameters: ps,
-		Body:       b,
+		Metadata:       t.Data(),
+		Name:           n,
+		Parameters:     ps,
+		ParameterTypes: pts,
+		ReturnType:     rt,
+		Body:           b,
 	}
 }
 
 func (fds *FunctionDeclarationStatement) String() string {
 	var IdentifierNodeNames = make([]string, len(fds.Parameters))
 	for i, p := range fds.Parameters {
-		IdentifierNodeNames[i] = p.String()
+		IdentifierNodeNames[i] = annotate(p.String(), fds.ParameterTypes[i])
 	}
-	return fmt.Sprintf("func %v(%v) { %v };", fds.Name, strings.Join(IdentifierNodeNames, ", "),
-		statementArrayNodeToString(fds.Body))
+	return export(fmt.Sprintf("%v { %v };", annotate(fmt.Sprintf("func %v(%v)", fds.Name,
+		strings.Join(IdentifierNodeNames, ", ")), fds.ReturnType), statementArrayNodeToString(fds.Body)), fds.Exported)
 }
 
 func (fds *FunctionDeclarationStatement) Type() NodeType {
@@ -181,6 +290,48 @@ func (fds *FunctionDeclarationStatement) Type() NodeType {
 
 func (fds *FunctionDeclarationStatement) statementFunction() {}
 
+// StructDeclarationStatement declares a struct, whose instances hold a value for each of its fields
+type StructDeclarationStatement struct {
+	token.Metadata
+	Name   string
+	Fields []IdentifierExpression
+}
+
+func NewStructDeclarationStatement(t token.Token, n string, fs []IdentifierExpression) *StructDeclarationStatement {
+	return &StructDeclarationStatement{
+		Metadata: t.Data(),
+		Name:     n,
+		Fields:   fs,
+	}
+}
+
+// FieldNames returns the names of the struct's fields, in declaration order
+func (sds *StructDeclarationStatement) FieldNames() []string {
+	var names = make([]string, len(sds.Fields))
+	for i, f := range sds.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func (sds *StructDeclarationStatement) String() string {
+	return fmt.Sprintf("struct %v { %v };", sds.Name, strings.Join(sds.FieldNames(), ", "))
+}
+
+func (sds *StructDeclarationStatement) Type() NodeType {
+	return StructDeclarationStatementNode
+}
+
+func (sds *StructDeclarationStatement) statementFunction() {}
+
+// export prefixes the declaration s with the export keyword, if it is exported
+func export(s string, exported bool) string {
+	if !exported {
+		return s
+	}
+	return "export " + s
+}
+
 func statementArrayNodeToString(staArr []Statement) string {
 	strBuff := bytes.Buffer{}
 
@@ -190,3 +341,42 @@ func statementArrayNodeToString(staArr []Statement) string {
 
 	return strBuff.String()
 }
+
+// ImportStatement makes the functions and variables of another file available as Name().function(), Program is set
+// once the import has been resolved
+type ImportStatement struct {
+	token.Metadata
+	Path    string
+	Alias   string // empty if the import is not renamed
+	Program *Program
+}
+
+func NewImportStatement(md token.Metadata, path, alias string) *ImportStatement {
+	return &ImportStatement{
+		Metadata: md,
+		Path:     path,
+		Alias:    alias,
+	}
+}
+
+// Name returns the qualifier of the imported module: its alias, or the name of its file without the extension
+func (is *ImportStatement) Name() string {
+	if is.Alias != "" {
+		return is.Alias
+	}
+	base := path.Base(is.Path)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+func (is *ImportStatement) String() string {
+	if is.Alias == "" {
+		return fmt.Sprintf("import \"%v\";", is.Path)
+	}
+	return fmt.Sprintf("import \"%v\" as %v;", is.Path, is.Alias)
+}
+
+func (is *ImportStatement) Type() NodeType {
+	return ImportStatementNode
+}
+
+func (is *ImportStatement) statementFunction() {}
@@ -0,0 +1,66 @@
+package ast
+
+import (
+	"github.com/EricNRodriguez/yum/token"
+	"fmt"
+)
+
+// built in type names that may appear in a type annotation
+const (
+	IntTypeName    = "int"
+	FloatTypeName  = "float"
+	BoolTypeName   = "bool"
+	StringTypeName = "string"
+	NullTypeName   = "null"
+	AnyTypeName    = "any"
+)
+
+var BuiltinTypeNames = map[string]bool{
+	IntTypeName:    true,
+	FloatTypeName:  true,
+	BoolTypeName:   true,
+	StringTypeName: true,
+	NullTypeName:   true,
+	AnyTypeName:    true,
+}
+
+// TypeAnnotation is an optional declared type, such as int or [int]. Elem is set for array types, in which case
+// Name is empty
+type TypeAnnotation struct {
+	token.Metadata
+	Name string
+	Elem *TypeAnnotation
+}
+
+func NewTypeAnnotation(md token.Metadata, n string) *TypeAnnotation {
+	return &TypeAnnotation{
+		Metadata: md,
+		Name:     n,
+	}
+}
+
+func NewArrayTypeAnnotation(md token.Metadata, elem *TypeAnnotation) *TypeAnnotation {
+	return &TypeAnnotation{
+		Metadata: md,
+		Elem:     elem,
+	}
+}
+
+func (ta *TypeAnnotation) IsArray() bool {
+	return ta.Elem != nil
+}
+
+func (ta *TypeAnnotation) String() string {
+	if ta.IsArray() {
+		return fmt.Sprintf("[%v]", ta.Elem.String())
+	}
+	return ta.Name
+}
+
+// annotate appends the annotation to s, if present
+func annotate(s string, ta *TypeAnnotation) string {
+	if ta == nil {
+		return s
+	}
+	return fmt.Sprintf("%v: %v", s, ta.String())
+}
@@ -0,0 +1,119 @@
+package ast
+
+// Visitor's Visit method is called for each node encountered by Walk. If the returned visitor w is not nil, Walk
+// visits each of the node's children with w, followed by a call of w.Visit(nil)
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth first order. It starts by calling v.Visit(node), node must not be
+// nil. Children are visited in source order
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	// expressions
+	case *IdentifierExpression, *IntegerExpression, *FloatingPointExpression, *StringExpression, *BooleanExpression:
+		// leaves
+	case *PrefixExpression:
+		Walk(v, n.Expression)
+	case *SafeCallExpression:
+		Walk(v, n.Call)
+	case *InfixExpression:
+		Walk(v, n.LeftExpression)
+		Walk(v, n.RightExpression)
+	case *InterpolatedStringExpression:
+		walkExpressions(v, n.Expressions)
+	case *ArrayExpression:
+		walkExpressions(v, n.Data)
+	case *ArrayIndexExpression:
+		Walk(v, n.IndexExpr)
+	case *FunctionCallExpression:
+		walkExpressions(v, n.Parameters)
+	case *StructExpression:
+		for i := range n.Fields {
+			Walk(v, &n.Fields[i])
+			Walk(v, n.Values[i])
+		}
+	case *FieldExpression:
+		Walk(v, n.Target)
+
+	// statements
+	case *VarStatement:
+		Walk(v, n.IdentifierNode)
+		Walk(v, n.Expression)
+	case *AssignmentStatement:
+		Walk(v, n.IdentifierNode)
+		Walk(v, n.Expression)
+	case *FieldAssignmentStatement:
+		Walk(v, n.Field)
+		Walk(v, n.Expression)
+	case *ReturnStatement:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
+	case *IfStatement:
+		Walk(v, n.Condition)
+		walkStatements(v, n.IfBlock)
+		walkStatements(v, n.ElseBlock)
+	case *WhileStatement:
+		Walk(v, n.Condition)
+		walkStatements(v, n.Block)
+	case *ThrowStatement:
+		Walk(v, n.Expression)
+	case *TryStatement:
+		walkStatements(v, n.TryBlock)
+		if n.CatchName != nil {
+			Walk(v, n.CatchName)
+		}
+		walkStatements(v, n.CatchBlock)
+		walkStatements(v, n.FinallyBlock)
+	case *FunctionDeclarationStatement:
+		for i := range n.Parameters {
+			Walk(v, &n.Parameters[i])
+		}
+		walkStatements(v, n.Body)
+	case *StructDeclarationStatement:
+		for i := range n.Fields {
+			Walk(v, &n.Fields[i])
+		}
+	case *FunctionCallStatement:
+		Walk(v, n.FunctionCallExpression)
+	case *ImportStatement:
+		// the imported program is a separate tree, in another file
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, s := range stmts {
+		Walk(v, s)
+	}
+}
+
+func walkExpressions(v Visitor, exprs []Expression) {
+	for _, e := range exprs {
+		Walk(v, e)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in depth first order, calling f(node) for each node. If f returns true,
+// Inspect invokes f for each of the node's children, followed by a call of f(nil)
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
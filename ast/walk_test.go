@@ -0,0 +1,113 @@
+package ast_test
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/spf13/afero"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	var (
+		fs    = afero.NewMemMapFs()
+		fp    = "test_files/walk/test.txt"
+		input = []byte("func f(a) { if (a) { return -a; }; while (a) { a = [a, g(1)]; }; return b[a + 1]; };" +
+			"var x = f(1);")
+		f    afero.File
+		l    lexer.Lexer
+		p    parser.Parser
+		prog *ast.Program
+		err  error
+		errs []error
+	)
+
+	if err = afero.WriteFile(fs, fp, input, 0644); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if f, err = fs.Open(fp); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if l, err = lexer.NewLexer(f); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if p, err = parser.NewRecursiveDescentParser(l); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if prog, errs = p.Parse(); len(errs) != 0 {
+		t.Fatalf("%v | invalid test case, syntax errors occurred: %v", fp, errs)
+	}
+
+	expected := []ast.NodeType{
+		ast.ProgramNode,
+		ast.FunctionDeclarationStatementNode,
+		ast.IdentifierExpressionNode,
+		ast.IfStatementNode,
+		ast.IdentifierExpressionNode,
+		ast.ReturnStatementNode,
+		ast.PrefixExpressionNode,
+		ast.IdentifierExpressionNode,
+		ast.WhileStatementNode,
+		ast.IdentifierExpressionNode,
+		ast.AssignmentStatementNode,
+		ast.IdentifierExpressionNode,
+		ast.ArrayExpressionNode,
+		ast.IdentifierExpressionNode,
+		ast.FunctionCallExpressionNode,
+		ast.IntegerExpressionNode,
+		ast.ReturnStatementNode,
+		ast.ArrayIndexExpressionNode,
+		ast.InfixExpressionNode,
+		ast.IdentifierExpressionNode,
+		ast.IntegerExpressionNode,
+		ast.VarStatementNode,
+		ast.IdentifierExpressionNode,
+		ast.FunctionCallExpressionNode,
+		ast.IntegerExpressionNode,
+	}
+
+	visited := make([]ast.NodeType, 0)
+	depth, maxDepth := 0, 0
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		visited = append(visited, n.Type())
+		if depth++; depth > maxDepth {
+			maxDepth = depth
+		}
+		return true
+	})
+
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected nodes %v, visited %v", expected, visited)
+	}
+
+	if depth != 0 {
+		t.Errorf("expected a nil visit after the children of each node, %v unmatched", depth)
+	}
+
+	// program, function, while, assignment, array, call, integer
+	if maxDepth != 7 {
+		t.Errorf("expected a maximum depth of 7, got %v", maxDepth)
+	}
+
+	// pruned subtrees are not visited
+	visited = visited[:0]
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if n != nil {
+			visited = append(visited, n.Type())
+		}
+		return n != nil && n.Type() != ast.FunctionDeclarationStatementNode
+	})
+
+	if !reflect.DeepEqual(visited, append(expected[:2:2], expected[21:]...)) {
+		t.Errorf("expected the function body to be skipped, visited %v", visited)
+	}
+}
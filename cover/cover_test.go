@@ -0,0 +1,165 @@
+package cover
+
+import (
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"bytes"
+	"github.com/spf13/afero"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testProgram = `func sign(n) {
+    if (n < 0) {
+        return -1;
+    };
+    if (n == 0) {
+        return 0;
+    } else {
+        return 1;
+    };
+};
+var x = sign(3);
+var y = sign(-3);
+`
+
+func TestRecorder(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	p := recordTestProgram(t, fs)
+
+	expected := `mode: count
+sign.yum:2 stmt 1 2
+sign.yum:2 if 1 1
+sign.yum:2 else 1 1
+sign.yum:3 stmt 1 1
+sign.yum:5 stmt 1 1
+sign.yum:5 if 1 0
+sign.yum:5 else 1 1
+sign.yum:6 stmt 1 0
+sign.yum:8 stmt 1 1
+sign.yum:11 stmt 1 1
+sign.yum:12 stmt 1 1
+`
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected profile\n%v\ngot\n%v", expected, buf.String())
+	}
+
+	parsed, err := ParseProfile(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed.Blocks(), p.Blocks()) {
+		t.Errorf("expected the parsed profile to match the written profile, got %v", parsed.Blocks())
+	}
+
+	statements, branches := p.Coverage("sign.yum")
+	if statements != 6.0/7.0 || branches != 0.75 {
+		t.Errorf("expected coverage 6/7 of statements and 3/4 of branches, got %v and %v", statements, branches)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	p := recordTestProgram(t, fs)
+	if err := p.Merge(recordTestProgram(t, fs)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range p.Blocks() {
+		if b.Line == 2 && b.Kind == StatementBlock && b.Count != 4 {
+			t.Errorf("expected the merged count of line 2 to be 4, got %v", b.Count)
+		}
+	}
+
+	other, err := ParseProfile(strings.NewReader("mode: count\nsign.yum:2 if 2 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Merge(other); err == nil {
+		t.Error("expected profiles of different sources to fail to merge")
+	}
+
+	if _, err = ParseProfile(strings.NewReader("mode: count\nsign.yum stmt 1 1\n")); err == nil {
+		t.Error("expected a block without a line to fail to parse")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	p := recordTestProgram(t, fs)
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, fs, p); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `sign.yum: 85.7% of statements, 75.0% of branches
+       | func sign(n) {
+     2 |     if (n < 0) {  [if 1, else 1]
+     1 |         return -1;
+       |     };
+     1 |     if (n == 0) {  [if 0, else 1]
+     0 |         return 0;
+       |     } else {
+     1 |         return 1;
+       |     };
+       | };
+     1 | var x = sign(3);
+     1 | var y = sign(-3);
+`
+	if buf.String() != expected {
+		t.Errorf("expected report\n%v\ngot\n%v", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteHTML(&buf, fs, p); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<span class="covered" title="1 runs">        return -1;</span>`,
+		`<span class="partial" title="1 runs, if 0, else 1">    if (n == 0) {</span>`,
+		`<span class="uncovered" title="0 runs">        return 0;</span>`,
+		"    } else {\n",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected html report to contain %q", s)
+		}
+	}
+}
+
+func recordTestProgram(t *testing.T, fs afero.Fs) *Profile {
+	if err := afero.WriteFile(fs, "sign.yum", []byte(testProgram), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("sign.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	r := NewRecorder(prog)
+	eval.NewEvaluator(r.Options()...).Evaluate(prog)
+	return r.Profile()
+}
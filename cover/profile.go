@@ -0,0 +1,197 @@
+package cover
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const modeLine = "mode: count"
+
+// Kind distinguishes the statements of a line from the branches of an if statement
+type Kind string
+
+const (
+	StatementBlock Kind = "stmt"
+	IfBranch       Kind = "if"
+	ElseBranch     Kind = "else" // taken when the condition is false, even if there is no else block
+)
+
+// Block is a unit of coverage: the statements on a line, or one branch of an if statement
+type Block struct {
+	File  string
+	Line  int
+	Kind  Kind
+	Num   int // the number of statements or branches
+	Count int // the number of times the block ran
+}
+
+type blockKey struct {
+	file string
+	line int
+	kind Kind
+}
+
+// Profile holds the coverage of one or more runs. Its text format has a header followed by a line per block:
+//
+//	mode: count
+//	fib.yum:2 stmt 1 9
+//	fib.yum:2 if 1 5
+//	fib.yum:2 else 1 4
+type Profile struct {
+	blocks map[blockKey]*Block
+}
+
+func NewProfile() *Profile {
+	return &Profile{blocks: make(map[blockKey]*Block)}
+}
+
+// add records a block, so that it is reported even if it never runs
+func (p *Profile) add(file string, line int, kind Kind) *Block {
+	key := blockKey{file, line, kind}
+	b, ok := p.blocks[key]
+	if !ok {
+		b = &Block{File: file, Line: line, Kind: kind}
+		p.blocks[key] = b
+	}
+	return b
+}
+
+// Blocks returns the profile's blocks ordered by file, line and kind
+func (p *Profile) Blocks() []Block {
+	blocks := make([]Block, 0, len(p.blocks))
+	for _, b := range p.blocks {
+		blocks = append(blocks, *b)
+	}
+
+	order := map[Kind]int{StatementBlock: 0, IfBranch: 1, ElseBranch: 2}
+	sort.Slice(blocks, func(i, j int) bool {
+		switch {
+		case blocks[i].File != blocks[j].File:
+			return blocks[i].File < blocks[j].File
+		case blocks[i].Line != blocks[j].Line:
+			return blocks[i].Line < blocks[j].Line
+		default:
+			return order[blocks[i].Kind] < order[blocks[j].Kind]
+		}
+	})
+	return blocks
+}
+
+// Files returns the files covered by the profile, in order
+func (p *Profile) Files() []string {
+	files := make([]string, 0)
+	for _, b := range p.Blocks() {
+		if len(files) == 0 || files[len(files)-1] != b.File {
+			files = append(files, b.File)
+		}
+	}
+	return files
+}
+
+// Merge adds the counts of o to p. Profiles of different versions of a file can not be merged
+func (p *Profile) Merge(o *Profile) error {
+	for key, b := range o.blocks {
+		if pB, ok := p.blocks[key]; ok && pB.Num != b.Num {
+			return fmt.Errorf(internal.ErrInconsistentCoverage, fmt.Sprintf("%v:%v", b.File, b.Line))
+		}
+	}
+
+	for key, b := range o.blocks {
+		pB := p.add(key.file, key.line, key.kind)
+		pB.Num = b.Num
+		pB.Count += b.Count
+	}
+	return nil
+}
+
+// Coverage returns the fraction of statements and of branches in file that ran, 1 if there are none
+func (p *Profile) Coverage(file string) (statements, branches float64) {
+	var (
+		stmts, coveredStmts        int
+		branchNum, coveredBranches int
+	)
+
+	for _, b := range p.blocks {
+		if b.File != file {
+			continue
+		}
+
+		if b.Kind == StatementBlock {
+			stmts += b.Num
+			if b.Count > 0 {
+				coveredStmts += b.Num
+			}
+		} else {
+			branchNum += b.Num
+			if b.Count > 0 {
+				coveredBranches += b.Num
+			}
+		}
+	}
+
+	return fraction(coveredStmts, stmts), fraction(coveredBranches, branchNum)
+}
+
+func fraction(n, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(n) / float64(total)
+}
+
+func (p *Profile) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, modeLine)
+	for _, b := range p.Blocks() {
+		fmt.Fprintf(bw, "%v:%v %v %v %v\n", b.File, b.Line, b.Kind, b.Num, b.Count)
+	}
+	return bw.Flush()
+}
+
+// ParseProfile reads a profile written by Write
+func ParseProfile(r io.Reader) (*Profile, error) {
+	p := NewProfile()
+	s := bufio.NewScanner(r)
+
+	if !s.Scan() || s.Text() != modeLine {
+		return nil, fmt.Errorf(internal.ErrInvalidCoverage, 1)
+	}
+
+	for n := 2; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// file names may contain colons, so the line is taken from the last
+		var (
+			sep                   = -1
+			line, num, count      int
+			lineErr, numErr, cErr error
+		)
+		if len(fields) == 4 {
+			sep = strings.LastIndex(fields[0], ":")
+		}
+		if sep < 0 {
+			return nil, fmt.Errorf(internal.ErrInvalidCoverage, n)
+		}
+
+		line, lineErr = strconv.Atoi(fields[0][sep+1:])
+		num, numErr = strconv.Atoi(fields[2])
+		count, cErr = strconv.Atoi(fields[3])
+		kind := Kind(fields[1])
+		if lineErr != nil || numErr != nil || cErr != nil ||
+			(kind != StatementBlock && kind != IfBranch && kind != ElseBranch) {
+			return nil, fmt.Errorf(internal.ErrInvalidCoverage, n)
+		}
+
+		b := p.add(fields[0][:sep], line, kind)
+		b.Num, b.Count = num, b.Count+count
+	}
+	return p, s.Err()
+}
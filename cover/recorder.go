@@ -0,0 +1,65 @@
+package cover
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/token"
+)
+
+// Recorder records the statements and branches of a program that run
+type Recorder interface {
+	// Options installs the recorder in an evaluator
+	Options() []eval.Option
+	// Profile returns the coverage recorded so far
+	Profile() *Profile
+}
+
+type recorder struct {
+	profile *Profile
+}
+
+func NewRecorder(prog *ast.Program) Recorder {
+	r := &recorder{profile: NewProfile()}
+
+	// every statement and branch is reported, including those that never run and those of imported modules
+	for _, m := range module.Programs(prog) {
+		ast.Inspect(m, func(n ast.Node) bool {
+			if _, ok := n.(ast.Statement); !ok || n.Type() == ast.FunctionDeclarationStatementNode ||
+				n.Type() == ast.StructDeclarationStatementNode {
+				return true
+			}
+
+			r.profile.add(n.FileName(), n.LineNumber(), StatementBlock).Num++
+			if n.Type() == ast.IfStatementNode {
+				r.profile.add(n.FileName(), n.LineNumber(), IfBranch).Num++
+				r.profile.add(n.FileName(), n.LineNumber(), ElseBranch).Num++
+			}
+			return true
+		})
+	}
+
+	return r
+}
+
+func (r *recorder) Options() []eval.Option {
+	return []eval.Option{eval.WithHook(r.statement), eval.WithBranchHook(r.branch)}
+}
+
+func (r *recorder) Profile() *Profile {
+	return r.profile
+}
+
+// statement counts the runs of a line, rather than of each statement on it
+func (r *recorder) statement(md token.Metadata, s eval.State) {
+	b := r.profile.add(md.FileName(), md.LineNumber(), StatementBlock)
+	b.Count++
+}
+
+func (r *recorder) branch(stmt *ast.IfStatement, taken bool) {
+	kind := IfBranch
+	if !taken {
+		kind = ElseBranch
+	}
+	r.profile.add(stmt.FileName(), stmt.LineNumber(), kind).Count++
+}
@@ -0,0 +1,160 @@
+package cover
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/afero"
+	"html"
+	"io"
+	"strings"
+)
+
+// line summarises the blocks of a source line
+type line struct {
+	statements    *Block
+	ifs, elses    *Block
+	covered, seen bool
+}
+
+// partial reports whether the line ran but one of its branches did not
+func (l line) partial() bool {
+	return l.covered && ((l.ifs != nil && l.ifs.Count == 0) || (l.elses != nil && l.elses.Count == 0))
+}
+
+func (l line) annotation() string {
+	if l.ifs == nil {
+		return ""
+	}
+	return fmt.Sprintf("if %v, else %v", l.ifs.Count, l.elses.Count)
+}
+
+func (p *Profile) lines(file string) map[int]*line {
+	lines := make(map[int]*line)
+	for key, b := range p.blocks {
+		if key.file != file {
+			continue
+		}
+
+		l, ok := lines[key.line]
+		if !ok {
+			l = &line{}
+			lines[key.line] = l
+		}
+
+		switch key.kind {
+		case StatementBlock:
+			l.statements, l.seen, l.covered = b, true, b.Count > 0
+		case IfBranch:
+			l.ifs = b
+		case ElseBranch:
+			l.elses = b
+		}
+	}
+	return lines
+}
+
+func readSource(fs afero.Fs, file string) ([]string, error) {
+	b, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"), nil
+}
+
+func summary(p *Profile, file string) string {
+	statements, branches := p.Coverage(file)
+	return fmt.Sprintf("%.1f%% of statements, %.1f%% of branches", statements*100, branches*100)
+}
+
+// WriteText writes a summary of each file followed by its source, with the number of times each line ran
+func WriteText(w io.Writer, fs afero.Fs, p *Profile) error {
+	bw := bufio.NewWriter(w)
+	for i, file := range p.Files() {
+		src, err := readSource(fs, file)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "%v: %v\n", file, summary(p, file))
+
+		lines := p.lines(file)
+		for n, text := range src {
+			count := ""
+			l, ok := lines[n+1]
+			if ok && l.seen {
+				count = fmt.Sprint(l.statements.Count)
+			}
+
+			fmt.Fprintf(bw, "%6v | %v", count, text)
+			if ok && l.annotation() != "" {
+				fmt.Fprintf(bw, "  [%v]", l.annotation())
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>yum coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { line-height: 1.4; }
+.covered { background: #c8f0c8; }
+.uncovered { background: #f4c2c2; }
+.partial { background: #f8e8a8; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+// WriteHTML writes each file's source, highlighting the lines that ran, did not run and ran without taking every branch
+func WriteHTML(w io.Writer, fs afero.Fs, p *Profile) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, htmlHeader)
+	for _, file := range p.Files() {
+		src, err := readSource(fs, file)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(bw, "<h2>%v</h2>\n<p>%v</p>\n<pre>\n", html.EscapeString(file), summary(p, file))
+
+		lines := p.lines(file)
+		for n, text := range src {
+			text = html.EscapeString(text)
+			l, ok := lines[n+1]
+			if !ok || !l.seen {
+				fmt.Fprintln(bw, text)
+				continue
+			}
+
+			class := "uncovered"
+			switch {
+			case l.partial():
+				class = "partial"
+			case l.covered:
+				class = "covered"
+			}
+
+			title := fmt.Sprintf("%v runs", l.statements.Count)
+			if l.annotation() != "" {
+				title += ", " + l.annotation()
+			}
+			fmt.Fprintf(bw, "<span class=\"%v\" title=\"%v\">%v</span>\n", class, title, text)
+		}
+		fmt.Fprint(bw, "</pre>\n")
+	}
+	fmt.Fprint(bw, htmlFooter)
+	return bw.Flush()
+}
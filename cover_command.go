@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/cover"
+	"github.com/EricNRodriguez/yum/internal"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"log"
+	"os"
+)
+
+// coverCommand implements yum cover, merging coverage profiles and reporting on the source they cover
+func coverCommand(args []string) int {
+	flags := flag.NewFlagSet("cover", flag.ExitOnError)
+	htmlPath := flags.String("html", "", "write an html report to this file instead of printing a text report")
+	outPath := flags.String("o", "", "write the merged profile to this file")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yum cover [-html file] [-o file] profile...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Println(internal.ErrNoCoverageProfiles)
+		return 2
+	}
+
+	appFs := afero.NewOsFs()
+	merged := cover.NewProfile()
+	for _, fp := range flags.Args() {
+		p, err := readCoverage(appFs, fp)
+		if err != nil {
+			log.Println(fmt.Sprintf(internal.ErrLoadFile, fp, err))
+			return 1
+		}
+		if err = merged.Merge(p); err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
+	if *outPath != "" {
+		if err := writeCoverage(appFs, *outPath, merged); err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
+	var err error
+	if *htmlPath != "" {
+		err = writeHTMLReport(appFs, *htmlPath, merged)
+	} else if *outPath == "" {
+		err = cover.WriteText(os.Stdout, appFs, merged)
+	}
+
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
+
+func readCoverage(fs afero.Fs, fp string) (*cover.Profile, error) {
+	f, err := fs.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return cover.ParseProfile(f)
+}
+
+func writeHTMLReport(fs afero.Fs, fp string, p *cover.Profile) error {
+	f, err := fs.Create(fp)
+	if err != nil {
+		return err
+	}
+
+	if err = cover.WriteHTML(f, fs, p); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
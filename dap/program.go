@@ -0,0 +1,79 @@
+package dap
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/EricNRodriguez/yum/semantic"
+	"errors"
+	"fmt"
+	"github.com/spf13/afero"
+	"strings"
+)
+
+const expressionFile = "<evaluate>"
+
+// loadProgram parses and analyses the program at fp and its imports, any errors are joined into one
+func loadProgram(fs afero.Fs, fp string) (prog *ast.Program, err error) {
+	prog, errs := module.Load(fs, fp)
+	if len(errs) != 0 {
+		return nil, joinErrors(errs)
+	}
+
+	if errs := semantic.NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+		return nil, joinErrors(errs)
+	}
+	return prog, nil
+}
+
+// parseExpression parses text as the expression of a var statement, as the language has no expression statements
+func parseExpression(text string) (expr ast.Expression, err error) {
+	fs := afero.NewMemMapFs()
+	if err = afero.WriteFile(fs, expressionFile, []byte(fmt.Sprintf("var expression = %v;", text)), 0644); err != nil {
+		return nil, err
+	}
+
+	prog, err := parse(fs, expressionFile)
+	if err != nil {
+		return nil, fmt.Errorf(internal.ErrInvalidExpression, text)
+	}
+
+	if len(prog.Statements) != 1 || prog.Statements[0].Type() != ast.VarStatementNode {
+		return nil, fmt.Errorf(internal.ErrInvalidExpression, text)
+	}
+	return prog.Statements[0].(*ast.VarStatement).Expression, nil
+}
+
+func parse(fs afero.Fs, fp string) (*ast.Program, error) {
+	f, err := fs.Open(fp)
+	if err != nil {
+		return nil, fmt.Errorf(internal.ErrFailedToReadFile, fp, err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		return nil, joinErrors(errs)
+	}
+	return prog, nil
+}
+
+func joinErrors(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
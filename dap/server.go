@@ -0,0 +1,632 @@
+package dap
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/token"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Server is a debug adapter for yum, communicating with a single client
+type Server interface {
+	// Serve handles requests until the client disconnects or closes the stream
+	Serve() error
+}
+
+type Option func(*server)
+
+// WithEvaluatorOptions configures the evaluator running the program
+func WithEvaluatorOptions(opts ...eval.Option) Option {
+	return func(s *server) {
+		s.evalOpts = append(s.evalOpts, opts...)
+	}
+}
+
+// WithProgramOutput forwards what is read from r to the client as output events. It is intended to be the read end of
+// a pipe the program writes its output to, see eval.WithOutput, which would otherwise be interleaved with the
+// protocol's messages
+func WithProgramOutput(r io.Reader) Option {
+	return func(s *server) {
+		s.programOutput = r
+	}
+}
+
+type requestMethod func(args json.RawMessage) (interface{}, error)
+
+type stepMode int
+
+const (
+	stepInto stepMode = iota // stop at the next statement
+	stepOver                 // stop at the next statement that is not in a deeper call
+	stepOut                  // stop at the next statement of a caller
+	resume                   // stop only at breakpoints
+)
+
+// terminated is panicked to abandon the program
+type terminated struct{}
+
+// scopeReference identifies a scope of a frame, numbered as by eval.State.Frames
+type scopeReference struct {
+	frame, scope int
+}
+
+type server struct {
+	fs            afero.Fs
+	in            *bufio.Reader
+	evalOpts      []eval.Option
+	programOutput io.Reader
+	requestRouter map[string]requestMethod
+	pending       []func() // run once the response to the current request has been written
+	disconnected  bool
+
+	// guards writes to the client, which are made by both the request loop and the program
+	writeMu sync.Mutex
+	out     io.Writer
+	seq     int
+
+	// guards the session's state, which is shared with the program
+	mu          sync.Mutex
+	prog        *ast.Program
+	launch      LaunchArguments
+	configured  bool
+	breakpoints map[string]map[int]bool // lines by file
+	mode        stepMode
+	reason      string // reported when a step completes
+	depth       int    // call depth of the last stop
+	state       eval.State
+	pos         token.Metadata
+	references  []interface{} // scopes and arrays, valid until the program resumes
+	terminating bool
+
+	resume   chan struct{}
+	quit     chan struct{}
+	quitOnce sync.Once
+	done     chan struct{} // closed when the program ends, nil if it has not started
+}
+
+func NewServer(fs afero.Fs, r io.Reader, w io.Writer, opts ...Option) Server {
+	s := &server{
+		fs:          fs,
+		in:          bufio.NewReader(r),
+		out:         w,
+		breakpoints: make(map[string]map[int]bool),
+		resume:      make(chan struct{}),
+		quit:        make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.requestRouter = map[string]requestMethod{
+		"initialize":              s.initialize,
+		"launch":                  s.launchProgram,
+		"setBreakpoints":          s.setBreakpoints,
+		"setExceptionBreakpoints": s.ignore,
+		"configurationDone":       s.configurationDone,
+		"threads":                 s.threads,
+		"stackTrace":              s.stackTrace,
+		"scopes":                  s.scopes,
+		"variables":               s.variables,
+		"continue":                s.step(resume),
+		"next":                    s.step(stepOver),
+		"stepIn":                  s.step(stepInto),
+		"stepOut":                 s.step(stepOut),
+		"pause":                   s.pause,
+		"evaluate":                s.evaluate,
+		"terminate":               s.terminate,
+		"disconnect":              s.disconnect,
+	}
+
+	return s
+}
+
+func (s *server) Serve() error {
+	if s.programOutput != nil {
+		go s.forwardOutput()
+	}
+	defer s.stop()
+
+	for !s.disconnected {
+		body, err := internal.ReadMessage(s.in)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var msg message
+		if err = json.Unmarshal(body, &msg); err != nil {
+			return err
+		}
+
+		// the client's responses to reverse requests are not needed
+		if msg.Type != requestType {
+			continue
+		}
+
+		var result interface{}
+		if method, ok := s.requestRouter[msg.Command]; ok {
+			result, err = method(msg.Arguments)
+		} else {
+			err = fmt.Errorf(internal.ErrUnknownMethod, msg.Command)
+		}
+
+		if err = s.respond(msg, result, err); err != nil {
+			return err
+		}
+
+		for _, f := range s.pending {
+			f()
+		}
+		s.pending = nil
+	}
+	return nil
+}
+
+func (s *server) write(v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.seq++
+	switch m := v.(type) {
+	case *response:
+		m.Seq = s.seq
+	case *event:
+		m.Seq = s.seq
+	}
+	return internal.WriteMessage(s.out, v)
+}
+
+func (s *server) respond(req message, body interface{}, err error) error {
+	r := &response{
+		Type:       responseType,
+		RequestSeq: req.Seq,
+		Success:    err == nil,
+		Command:    req.Command,
+		Body:       body,
+	}
+	if err != nil {
+		r.Message = err.Error()
+	}
+	return s.write(r)
+}
+
+func (s *server) event(name string, body interface{}) error {
+	return s.write(&event{Type: eventType, Event: name, Body: body})
+}
+
+func (s *server) forwardOutput() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.programOutput.Read(buf)
+		if n > 0 {
+			s.event("output", OutputEventBody{Category: "stdout", Output: string(buf[:n])})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// lifecycle
+
+func (s *server) initialize(args json.RawMessage) (interface{}, error) {
+	s.pending = append(s.pending, func() {
+		s.event("initialized", nil)
+	})
+	return Capabilities{
+		SupportsConfigurationDoneRequest: true,
+		SupportsEvaluateForHovers:        true,
+		SupportsTerminateRequest:         true,
+	}, nil
+}
+
+func (s *server) launchProgram(args json.RawMessage) (interface{}, error) {
+	var a LaunchArguments
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+	a.Program = filepath.Clean(a.Program)
+
+	prog, err := loadProgram(s.fs, a.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prog != nil {
+		return nil, errors.New(internal.ErrLaunched)
+	}
+
+	s.prog, s.launch = prog, a
+	s.mode, s.reason = resume, stepReason
+	if a.StopOnEntry {
+		s.mode, s.reason = stepInto, entryReason
+	}
+	s.start()
+	return nil, nil
+}
+
+func (s *server) configurationDone(args json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configured = true
+	s.start()
+	return nil, nil
+}
+
+// start runs the program once it has been launched and the client has finished configuring breakpoints, in either
+// order
+func (s *server) start() {
+	if s.prog == nil || !s.configured || s.done != nil {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.pending = append(s.pending, func() {
+		go s.run()
+	})
+}
+
+func (s *server) run() {
+	defer close(s.done)
+
+	exitCode := 0
+	func() {
+		defer func() {
+			switch r := recover().(type) {
+			case nil, terminated:
+			case error:
+				exitCode = 1
+				s.event("output", OutputEventBody{Category: "stderr", Output: r.Error() + "\n"})
+			default:
+				panic(r)
+			}
+		}()
+
+		opts := append(s.evalOpts, eval.WithRecoverableErrors(), eval.WithHook(s.hook))
+		e := eval.NewEvaluator(opts...)
+		e.Evaluate(s.prog)
+		exitCode = e.ExitCode()
+	}()
+
+	s.event("exited", ExitedEventBody{ExitCode: exitCode})
+	s.event("terminated", nil)
+}
+
+// stop abandons the program, if it is running, and waits for it to end
+func (s *server) stop() {
+	s.mu.Lock()
+	s.terminating = true
+	done := s.done
+	s.mu.Unlock()
+
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+	if done != nil {
+		<-done
+	}
+}
+
+func (s *server) terminate(args json.RawMessage) (interface{}, error) {
+	s.pending = append(s.pending, s.stop)
+	return nil, nil
+}
+
+func (s *server) disconnect(args json.RawMessage) (interface{}, error) {
+	s.pending = append(s.pending, s.stop)
+	s.disconnected = true
+	return nil, nil
+}
+
+func (s *server) ignore(args json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+// execution
+
+// hook is called by the evaluator before each statement, pausing the program when it reaches a breakpoint or
+// completes a step
+func (s *server) hook(md token.Metadata, st eval.State) {
+	s.mu.Lock()
+	if s.terminating {
+		s.mu.Unlock()
+		panic(terminated{})
+	}
+
+	depth := len(st.CallStack())
+	reason := ""
+	switch {
+	case s.launch.NoDebug:
+	case s.breakpoints[md.FileName()][md.LineNumber()]:
+		reason = breakpointReason
+	case s.stepComplete(depth):
+		reason = s.reason
+	}
+
+	if reason == "" {
+		s.mu.Unlock()
+		return
+	}
+
+	s.state, s.pos, s.depth = st, md, depth
+	s.mu.Unlock()
+
+	s.event("stopped", StoppedEventBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+
+	select {
+	case <-s.resume:
+	case <-s.quit:
+		panic(terminated{})
+	}
+}
+
+func (s *server) stepComplete(depth int) bool {
+	switch s.mode {
+	case stepInto:
+		return true
+	case stepOver:
+		return depth <= s.depth
+	case stepOut:
+		return depth < s.depth
+	default:
+		return false
+	}
+}
+
+func (s *server) step(mode stepMode) requestMethod {
+	return func(args json.RawMessage) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.state == nil {
+			return nil, errors.New(internal.ErrNotPaused)
+		}
+
+		s.mode, s.reason = mode, stepReason
+		s.state, s.references = nil, nil
+		s.pending = append(s.pending, func() {
+			s.resume <- struct{}{}
+		})
+
+		if mode == resume {
+			return ContinueResponseBody{AllThreadsContinued: true}, nil
+		}
+		return nil, nil
+	}
+}
+
+func (s *server) pause(args json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		s.mode, s.reason = stepInto, pauseReason
+	}
+	return nil, nil
+}
+
+// breakpoints
+
+func (s *server) setBreakpoints(args json.RawMessage) (interface{}, error) {
+	var a SetBreakpointsArguments
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Clean(a.Source.Path)
+	source := Source{Name: filepath.Base(path), Path: path}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// lines can only be checked against the launched program
+	var lines map[int]bool
+	if s.prog != nil && s.prog.FileName() == path {
+		lines = statementLines(s.prog)
+	}
+
+	s.breakpoints[path] = make(map[int]bool)
+	bs := make([]Breakpoint, len(a.Breakpoints))
+	for i, b := range a.Breakpoints {
+		bs[i] = Breakpoint{Verified: true, Line: b.Line, Source: source}
+		if lines != nil && !lines[b.Line] {
+			bs[i].Verified, bs[i].Message = false, fmt.Sprintf(internal.ErrNoStatement, b.Line)
+			continue
+		}
+		s.breakpoints[path][b.Line] = true
+	}
+	return SetBreakpointsResponseBody{Breakpoints: bs}, nil
+}
+
+// statementLines returns the lines the program may stop at
+func statementLines(prog *ast.Program) map[int]bool {
+	lines := make(map[int]bool)
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if _, ok := n.(ast.Statement); ok && n.Type() != ast.FunctionDeclarationStatementNode &&
+			n.Type() != ast.StructDeclarationStatementNode {
+			lines[n.LineNumber()] = true
+		}
+		return true
+	})
+	return lines
+}
+
+// inspection
+
+func (s *server) threads(args json.RawMessage) (interface{}, error) {
+	return ThreadsResponseBody{Threads: []Thread{{ID: threadID, Name: threadName}}}, nil
+}
+
+// frameID numbers frames from 1, the program's frame, so that 0 can mean the innermost frame
+func frameID(frame int) int {
+	return frame + 1
+}
+
+func (s *server) stackTrace(args json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil, errors.New(internal.ErrNotPaused)
+	}
+
+	calls := s.state.CallStack()
+	frames := make([]StackFrame, 0, len(calls)+1)
+	pos := s.pos
+	for i := len(calls); i >= 0; i-- {
+		name := "<main>"
+		if i > 0 {
+			name = calls[i-1].String()
+		}
+
+		frames = append(frames, StackFrame{
+			ID:     frameID(i),
+			Name:   name,
+			Source: Source{Name: filepath.Base(pos.FileName()), Path: pos.FileName()},
+			Line:   pos.LineNumber(),
+			Column: 1,
+		})
+
+		// a caller is paused at its call
+		if i > 0 {
+			pos = calls[i-1]
+		}
+	}
+	return StackTraceResponseBody{StackFrames: frames, TotalFrames: len(frames)}, nil
+}
+
+func (s *server) scopes(args json.RawMessage) (interface{}, error) {
+	var a ScopesArguments
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil, errors.New(internal.ErrNotPaused)
+	}
+
+	frames := s.state.Frames()
+	frame := a.FrameID - 1
+	if frame < 0 || frame >= len(frames) {
+		return nil, fmt.Errorf(internal.ErrInvalidFrame, a.FrameID)
+	}
+
+	// innermost scope first, as clients expand the first scope
+	scopes := make([]Scope, 0, len(frames[frame]))
+	for i := len(frames[frame]) - 1; i >= 0; i-- {
+		name := fmt.Sprintf("Block %v", i)
+		switch {
+		case i == 0 && frame == 0:
+			name = "Globals"
+		case i == 0:
+			name = "Locals"
+		}
+		scopes = append(scopes, Scope{Name: name, VariablesReference: s.reference(scopeReference{frame, i})})
+	}
+	return ScopesResponseBody{Scopes: scopes}, nil
+}
+
+// reference returns a handle to a scope or array, for the client to expand
+func (s *server) reference(r interface{}) int {
+	s.references = append(s.references, r)
+	return len(s.references)
+}
+
+func (s *server) variable(name string, o object.Object) Variable {
+	v := Variable{Name: name, Value: o.Literal(), Type: string(o.Type())}
+	if arr, ok := o.(*object.ArrayNode); ok && arr.Length > 0 {
+		v.VariablesReference = s.reference(arr)
+	}
+	return v
+}
+
+func (s *server) variables(args json.RawMessage) (interface{}, error) {
+	var a VariablesArguments
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil, errors.New(internal.ErrNotPaused)
+	}
+	if a.VariablesReference < 1 || a.VariablesReference > len(s.references) {
+		return nil, fmt.Errorf(internal.ErrInvalidReference, a.VariablesReference)
+	}
+
+	vars := make([]Variable, 0)
+	switch r := s.references[a.VariablesReference-1].(type) {
+	case scopeReference:
+		scope := s.state.Frames()[r.frame][r.scope]
+		names := make([]string, 0, len(scope))
+		for name := range scope {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			vars = append(vars, s.variable(name, scope[name]))
+		}
+	case *object.ArrayNode:
+		for i, o := range r.Data {
+			vars = append(vars, s.variable(fmt.Sprintf("[%v]", i), o))
+		}
+	}
+	return VariablesResponseBody{Variables: vars}, nil
+}
+
+func (s *server) evaluate(args json.RawMessage) (interface{}, error) {
+	var a EvaluateArguments
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, err
+	}
+
+	expr, err := parseExpression(a.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil, errors.New(internal.ErrNotPaused)
+	}
+
+	frame := a.FrameID - 1
+	if a.FrameID == 0 {
+		frame = len(s.state.Frames()) - 1
+	}
+
+	o, err := s.state.EvaluateInFrame(expr, frame)
+	if err != nil {
+		if e, ok := err.(*internal.Error); ok {
+			return nil, errors.New(e.Message())
+		}
+		return nil, err
+	}
+
+	v := s.variable("", o)
+	return EvaluateResponseBody{Result: v.Value, Type: v.Type, VariablesReference: v.VariablesReference}, nil
+}
@@ -0,0 +1,359 @@
+package dap
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bufio"
+	"encoding/json"
+	"github.com/spf13/afero"
+	"io"
+	"reflect"
+	"testing"
+)
+
+const testProgram = `func add(a, b) {
+    var s = a + b;
+    return s;
+};
+var x = 1;
+var xs = [x, 2];
+if (x == 1) {
+    var y = add(x, 2);
+    x = y;
+};
+`
+
+// testClient drives a server running in the same process
+type testClient struct {
+	t      *testing.T
+	in     *bufio.Reader
+	out    *io.PipeWriter
+	seq    int
+	events []message // read while waiting for a response
+	done   chan error
+}
+
+func newTestClient(t *testing.T, files map[string]string) *testClient {
+	fs := afero.NewMemMapFs()
+	for fp, src := range files {
+		if err := afero.WriteFile(fs, fp, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{
+		t:    t,
+		in:   bufio.NewReader(clientIn),
+		out:  clientOut,
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		err := NewServer(fs, serverIn, serverOut).Serve()
+		serverOut.Close()
+		c.done <- err
+	}()
+
+	c.request("initialize", map[string]interface{}{"adapterID": "yum"}, nil)
+	c.event("initialized", nil)
+	return c
+}
+
+func (c *testClient) read() message {
+	body, err := internal.ReadMessage(c.in)
+	if err != nil {
+		c.t.Fatalf("failed to read message: %v", err)
+	}
+
+	var msg message
+	if err = json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("failed to decode message: %v", err)
+	}
+	return msg
+}
+
+// request sends a request and decodes the body of its response into body, returning the error message if it failed
+func (c *testClient) request(command string, args interface{}, body interface{}) string {
+	c.seq++
+	err := internal.WriteMessage(c.out, map[string]interface{}{
+		"seq":       c.seq,
+		"type":      requestType,
+		"command":   command,
+		"arguments": args,
+	})
+	if err != nil {
+		c.t.Fatalf("failed to write request: %v", err)
+	}
+
+	for {
+		msg := c.read()
+		if msg.Type == eventType {
+			c.events = append(c.events, msg)
+			continue
+		}
+
+		if msg.Type != responseType || msg.RequestSeq != c.seq || msg.Command != command {
+			c.t.Fatalf("expected response to %v, got %+v", command, msg)
+		}
+		if !msg.Success {
+			if msg.Message == "" {
+				c.t.Errorf("%v failed without a message", command)
+			}
+			return msg.Message
+		}
+		if body != nil {
+			if err = json.Unmarshal(msg.Body, body); err != nil {
+				c.t.Fatalf("failed to decode body %s: %v", msg.Body, err)
+			}
+		}
+		return ""
+	}
+}
+
+// event waits for the next event, which must be name, decoding its body into body
+func (c *testClient) event(name string, body interface{}) {
+	var msg message
+	if len(c.events) > 0 {
+		msg, c.events = c.events[0], c.events[1:]
+	} else {
+		msg = c.read()
+	}
+
+	if msg.Type != eventType || msg.Event != name {
+		c.t.Fatalf("expected %v event, got %+v", name, msg)
+	}
+	if body != nil {
+		if err := json.Unmarshal(msg.Body, body); err != nil {
+			c.t.Fatalf("failed to decode body %s: %v", msg.Body, err)
+		}
+	}
+}
+
+// stopped waits for the program to stop, returning the reason and the line it stopped at
+func (c *testClient) stopped() (string, int) {
+	var stop StoppedEventBody
+	c.event("stopped", &stop)
+
+	var trace StackTraceResponseBody
+	if err := c.request("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace); err != "" {
+		c.t.Fatalf("unexpected error %v", err)
+	}
+	return stop.Reason, trace.StackFrames[0].Line
+}
+
+func (c *testClient) exited() int {
+	var exit ExitedEventBody
+	c.event("exited", &exit)
+	c.event("terminated", nil)
+	return exit.ExitCode
+}
+
+func (c *testClient) close() {
+	c.request("disconnect", map[string]interface{}{}, nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server failed: %v", err)
+	}
+}
+
+func (c *testClient) variables(ref int) map[string]Variable {
+	var body VariablesResponseBody
+	if err := c.request("variables", VariablesArguments{VariablesReference: ref}, &body); err != "" {
+		c.t.Fatalf("unexpected error %v", err)
+	}
+
+	vars := make(map[string]Variable)
+	for _, v := range body.Variables {
+		vars[v.Name] = v
+	}
+	return vars
+}
+
+func TestSession(t *testing.T) {
+	c := newTestClient(t, map[string]string{"/test.yum": testProgram})
+	defer c.close()
+
+	if err := c.request("launch", LaunchArguments{Program: "/test.yum"}, nil); err != "" {
+		t.Fatalf("failed to launch: %v", err)
+	}
+
+	var bps SetBreakpointsResponseBody
+	c.request("setBreakpoints", SetBreakpointsArguments{
+		Source:      Source{Path: "/test.yum"},
+		Breakpoints: []SourceBreakpoint{{Line: 2}, {Line: 4}},
+	}, &bps)
+
+	if len(bps.Breakpoints) != 2 || !bps.Breakpoints[0].Verified || bps.Breakpoints[1].Verified {
+		t.Errorf("expected only the breakpoint on line 2 to be verified, got %+v", bps.Breakpoints)
+	}
+
+	c.request("configurationDone", nil, nil)
+	if reason, line := c.stopped(); reason != breakpointReason || line != 2 {
+		t.Fatalf("expected to stop at the breakpoint on line 2, stopped at %v for %v", line, reason)
+	}
+
+	var threads ThreadsResponseBody
+	c.request("threads", nil, &threads)
+	if !reflect.DeepEqual(threads.Threads, []Thread{{ID: threadID, Name: threadName}}) {
+		t.Errorf("unexpected threads %+v", threads.Threads)
+	}
+
+	var trace StackTraceResponseBody
+	c.request("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace)
+	source := Source{Name: "test.yum", Path: "/test.yum"}
+	expectedFrames := []StackFrame{
+		{ID: 2, Name: "add(x, 2)", Source: source, Line: 2, Column: 1},
+		{ID: 1, Name: "<main>", Source: source, Line: 8, Column: 1},
+	}
+	if !reflect.DeepEqual(trace.StackFrames, expectedFrames) {
+		t.Errorf("expected frames %+v, got %+v", expectedFrames, trace.StackFrames)
+	}
+
+	// the function's frame
+	var scopes ScopesResponseBody
+	c.request("scopes", ScopesArguments{FrameID: 2}, &scopes)
+	if len(scopes.Scopes) != 1 || scopes.Scopes[0].Name != "Locals" {
+		t.Fatalf("expected the function's locals, got %+v", scopes.Scopes)
+	}
+	locals := c.variables(scopes.Scopes[0].VariablesReference)
+	if len(locals) != 2 || locals["a"].Value != "1" || locals["b"].Value != "2" {
+		t.Errorf("unexpected locals %+v", locals)
+	}
+
+	// the program's frame, paused in the if statement's block
+	c.request("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "Block 1" || scopes.Scopes[1].Name != "Globals" {
+		t.Fatalf("expected a block and globals, got %+v", scopes.Scopes)
+	}
+	globals := c.variables(scopes.Scopes[1].VariablesReference)
+	if len(globals) != 2 || globals["x"].Value != "1" || globals["xs"].VariablesReference == 0 {
+		t.Fatalf("unexpected globals %+v", globals)
+	}
+	elements := c.variables(globals["xs"].VariablesReference)
+	if len(elements) != 2 || elements["[0]"].Value != "1" || elements["[1]"].Value != "2" {
+		t.Errorf("unexpected elements %+v", elements)
+	}
+
+	type evaluateTestCase struct {
+		expression string
+		frame      int
+		expected   string // empty if evaluation should fail
+	}
+
+	for i, tC := range []evaluateTestCase{
+		{"a + b * 10", 0, "21"},
+		{"a + b * 10", 2, "21"},
+		{"xs[1] + x", 1, "3"},
+		{"add(x, x)", 1, "2"},
+		{"xs", 1, "[1,2]"},
+		{"x", 2, ""},          // not in the function's frame
+		{"missing(1)", 0, ""}, // undeclared function
+		{"1 +", 0, ""},
+		{"1; print(1)", 0, ""},
+		{"a / 0", 0, ""},
+		{"a", 3, ""},
+	} {
+		var result EvaluateResponseBody
+		err := c.request("evaluate", EvaluateArguments{Expression: tC.expression, FrameID: tC.frame}, &result)
+		switch {
+		case tC.expected == "" && err == "":
+			t.Errorf("test case %v: expected %v to fail, got %v", i, tC.expression, result.Result)
+		case tC.expected != "" && result.Result != tC.expected:
+			t.Errorf("test case %v: expected %v, got %v %v", i, tC.expected, result.Result, err)
+		}
+	}
+
+	// evaluation does not disturb the program
+	c.request("next", StepArguments{ThreadID: threadID}, nil)
+	if reason, line := c.stopped(); reason != stepReason || line != 3 {
+		t.Errorf("expected to step to line 3, stopped at %v for %v", line, reason)
+	}
+
+	c.request("stepOut", StepArguments{ThreadID: threadID}, nil)
+	if reason, line := c.stopped(); reason != stepReason || line != 9 {
+		t.Errorf("expected to step out to line 9, stopped at %v for %v", line, reason)
+	}
+
+	// references are invalidated when the program resumes
+	c.request("continue", StepArguments{ThreadID: threadID}, nil)
+	if code := c.exited(); code != 0 {
+		t.Errorf("expected exit code 0, got %v", code)
+	}
+	if err := c.request("variables", VariablesArguments{VariablesReference: 1}, nil); err == "" {
+		t.Errorf("expected variables to fail once the program has exited")
+	}
+}
+
+func TestStepping(t *testing.T) {
+	c := newTestClient(t, map[string]string{"/test.yum": testProgram})
+	defer c.close()
+
+	c.request("configurationDone", nil, nil)
+	c.request("launch", LaunchArguments{Program: "/test.yum", StopOnEntry: true}, nil)
+
+	type step struct {
+		command string
+		reason  string
+		line    int
+	}
+
+	for i, s := range []step{
+		{"", entryReason, 5},
+		{"next", stepReason, 6},
+		{"next", stepReason, 7},
+		{"stepIn", stepReason, 8},
+		{"stepIn", stepReason, 2},
+		{"next", stepReason, 3},
+		{"next", stepReason, 9},
+	} {
+		if s.command != "" {
+			if err := c.request(s.command, StepArguments{ThreadID: threadID}, nil); err != "" {
+				t.Fatalf("step %v: unexpected error %v", i, err)
+			}
+		}
+
+		if reason, line := c.stopped(); reason != s.reason || line != s.line {
+			t.Errorf("step %v: expected to stop at %v for %v, stopped at %v for %v", i, s.line, s.reason, line, reason)
+		}
+	}
+
+	c.request("terminate", nil, nil)
+	c.exited()
+}
+
+func TestErrors(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/fail.yum":    "var z = 0;\nvar x = 1 / z;",
+		"/invalid.yum": "var x = ;",
+	})
+	defer c.close()
+
+	for i, command := range []string{"stackTrace", "scopes", "variables", "evaluate", "next", "unknown"} {
+		if err := c.request(command, map[string]interface{}{}, nil); err == "" {
+			t.Errorf("test case %v: expected %v to fail before the program is paused", i, command)
+		}
+	}
+
+	if err := c.request("launch", LaunchArguments{Program: "/missing.yum"}, nil); err == "" {
+		t.Errorf("expected launching a missing file to fail")
+	}
+	if err := c.request("launch", LaunchArguments{Program: "/invalid.yum"}, nil); err == "" {
+		t.Errorf("expected launching an invalid program to fail")
+	}
+
+	c.request("launch", LaunchArguments{Program: "/fail.yum"}, nil)
+	if err := c.request("launch", LaunchArguments{Program: "/fail.yum"}, nil); err == "" {
+		t.Errorf("expected a second launch to fail")
+	}
+	c.request("configurationDone", nil, nil)
+
+	var output OutputEventBody
+	c.event("output", &output)
+	if output.Category != "stderr" || output.Output == "" {
+		t.Errorf("expected the runtime error on stderr, got %+v", output)
+	}
+	if code := c.exited(); code != 1 {
+		t.Errorf("expected exit code 1, got %v", code)
+	}
+}
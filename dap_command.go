@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/dap"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/object"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// dapCommand implements yum dap, running a debug adapter over stdio, or over TCP on localhost if a port is given
+func dapCommand(args []string) int {
+	flags := flag.NewFlagSet("dap", flag.ExitOnError)
+	allow := flags.String("allow", object.AllCapabilities.String(),
+		"comma separated capabilities granted to the script's native functions")
+	port := flags.Int("port", 0, "serve a single client on this port of localhost, rather than over stdio")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yum dap [-allow capabilities] [-port port]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	caps, unknown, ok := object.ParseCapabilitySet(*allow)
+	if !ok {
+		fmt.Printf(internal.ErrUnknownCapability+"\n", unknown)
+		return 2
+	}
+
+	var (
+		in  io.Reader = os.Stdin
+		out io.Writer = os.Stdout
+	)
+
+	if *port != 0 {
+		ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%v", *port))
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		log.Printf("listening on %v", ln.Addr())
+
+		conn, err := ln.Accept()
+		ln.Close()
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		defer conn.Close()
+		in, out = conn, conn
+	}
+
+	// the program's output is sent to the client as events, rather than written between messages
+	r, w := io.Pipe()
+
+	server := dap.NewServer(afero.NewOsFs(), in, out,
+		// the client may be talking over stdin, so the program has no input
+		dap.WithEvaluatorOptions(
+			eval.WithCapabilities(caps),
+			eval.WithProcess(nil, strings.NewReader("")),
+			eval.WithOutput(w),
+		),
+		dap.WithProgramOutput(r),
+	)
+	if err := server.Serve(); err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
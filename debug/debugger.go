@@ -0,0 +1,420 @@
+package debug
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/token"
+	"bufio"
+	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	prompt      = "(yum) "
+	listContext = 5 // lines listed either side of the current line
+)
+
+// Debugger runs a program, pausing at breakpoints and steps to read commands
+type Debugger interface {
+	Run(prog *ast.Program)
+}
+
+type Option func(*debugger)
+
+// WithEvaluatorOptions configures the evaluator running the program
+func WithEvaluatorOptions(opts ...eval.Option) Option {
+	return func(d *debugger) {
+		d.evalOpts = append(d.evalOpts, opts...)
+	}
+}
+
+// WithBreakpoints sets breakpoints on lines of the program's file before it starts
+func WithBreakpoints(lines ...int) Option {
+	return func(d *debugger) {
+		d.initialBreakpoints = append(d.initialBreakpoints, lines...)
+	}
+}
+
+type stepMode int
+
+const (
+	stepInto stepMode = iota // stop at the next statement
+	stepOver                 // stop at the next statement that is not in a deeper call
+	stepOut                  // stop at the next statement of a caller
+	resume                   // stop only at breakpoints
+)
+
+type breakpoint struct {
+	file string
+	line int
+}
+
+func (b breakpoint) String() string {
+	return fmt.Sprintf("%v:%v", b.file, b.line)
+}
+
+// command handles a line read at the prompt, returning true if the program should resume
+type command func(args []string) bool
+
+// quit is panicked to abandon the program
+type quit struct{}
+
+type debugger struct {
+	fs                 afero.Fs
+	in                 *bufio.Scanner
+	out                io.Writer
+	evalOpts           []eval.Option
+	initialBreakpoints []int
+	breakpoints        map[breakpoint]bool
+	sources            map[string][]string
+	commandRouter      map[string]command
+	mode               stepMode
+	depth              int // call depth of the last stop
+	file               string
+	pos                token.Metadata // statement the program is paused at
+	state              eval.State
+	lastCommand        string
+}
+
+// NewDebugger reads commands from in and writes to out, source listings are read from fs
+func NewDebugger(fs afero.Fs, in io.Reader, out io.Writer, opts ...Option) Debugger {
+	d := &debugger{
+		fs:          fs,
+		in:          bufio.NewScanner(in),
+		out:         out,
+		breakpoints: make(map[breakpoint]bool),
+		sources:     make(map[string][]string),
+		mode:        stepInto, // stop before the first statement
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.commandRouter = make(map[string]command)
+	for _, c := range []struct {
+		names []string
+		cmd   command
+	}{
+		{[]string{"break", "b"}, d.setBreakpoint},
+		{[]string{"clear"}, d.clearBreakpoint},
+		{[]string{"breakpoints"}, d.listBreakpoints},
+		{[]string{"continue", "c"}, d.step(resume)},
+		{[]string{"next", "n"}, d.step(stepOver)},
+		{[]string{"step", "s"}, d.step(stepInto)},
+		{[]string{"out", "o"}, d.stepOut},
+		{[]string{"stack", "bt"}, d.printStack},
+		{[]string{"vars", "v"}, d.printVars},
+		{[]string{"print", "p"}, d.printVar},
+		{[]string{"list", "l"}, d.list},
+		{[]string{"help", "h"}, d.help},
+		{[]string{"quit", "q"}, d.quit},
+	} {
+		for _, name := range c.names {
+			d.commandRouter[name] = c.cmd
+		}
+	}
+
+	return d
+}
+
+func (d *debugger) Run(prog *ast.Program) {
+	d.file = prog.FileName()
+	for _, line := range d.initialBreakpoints {
+		d.breakpoints[breakpoint{d.file, line}] = true
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(quit); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	// the program's output is interleaved with the debugger's, unless the options direct it elsewhere
+	opts := append([]eval.Option{eval.WithOutput(d.out)}, d.evalOpts...)
+	e := eval.NewEvaluator(append(opts, eval.WithHook(d.hook))...)
+	e.Evaluate(prog)
+	if code := e.ExitCode(); code != 0 {
+		fmt.Fprintf(d.out, "program exited with status %v\n", code)
+		return
+	}
+	fmt.Fprintln(d.out, "program exited")
+	return
+}
+
+// hook decides whether to stop before a statement, and if so reads commands until one resumes the program
+func (d *debugger) hook(md token.Metadata, s eval.State) {
+	depth := len(s.CallStack())
+
+	atBreakpoint := d.breakpoints[breakpoint{md.FileName(), md.LineNumber()}]
+	if !atBreakpoint && !d.stepComplete(depth) {
+		return
+	}
+
+	d.pos, d.state, d.depth = md, s, depth
+	if atBreakpoint {
+		fmt.Fprintf(d.out, "breakpoint at %v:%v\n", md.FileName(), md.LineNumber())
+	} else {
+		fmt.Fprintf(d.out, "stopped at %v:%v\n", md.FileName(), md.LineNumber())
+	}
+	d.printLine(md.FileName(), md.LineNumber(), true)
+
+	for {
+		fmt.Fprint(d.out, prompt)
+		if !d.in.Scan() {
+			// input is exhausted, so the program can no longer be controlled
+			panic(quit{})
+		}
+
+		line := strings.TrimSpace(d.in.Text())
+		if line == "" {
+			line = d.lastCommand // repeat the last command, as gdb does
+		}
+		if line == "" {
+			continue
+		}
+		d.lastCommand = line
+
+		fields := strings.Fields(line)
+		cmd, ok := d.commandRouter[fields[0]]
+		if !ok {
+			fmt.Fprintf(d.out, internal.ErrUnknownCommand+"\n", fields[0])
+			continue
+		}
+
+		if cmd(fields[1:]) {
+			return
+		}
+	}
+}
+
+func (d *debugger) stepComplete(depth int) bool {
+	switch d.mode {
+	case stepInto:
+		return true
+	case stepOver:
+		return depth <= d.depth
+	case stepOut:
+		return depth < d.depth
+	default:
+		return false
+	}
+}
+
+// commands
+
+func (d *debugger) step(mode stepMode) command {
+	return func(args []string) bool {
+		d.mode = mode
+		return true
+	}
+}
+
+func (d *debugger) stepOut(args []string) bool {
+	if d.depth == 0 {
+		fmt.Fprintln(d.out, internal.ErrOutermostFrame)
+		return false
+	}
+	return d.step(stepOut)(args)
+}
+
+func (d *debugger) quit(args []string) bool {
+	panic(quit{})
+}
+
+func (d *debugger) parseBreakpoint(args []string) (b breakpoint, ok bool) {
+	if len(args) != 1 {
+		fmt.Fprintf(d.out, internal.ErrInvalidBreakpoint+"\n", strings.Join(args, " "))
+		return
+	}
+
+	b.file, b.line = d.file, 0
+	lineArg := args[0]
+	if sep := strings.LastIndex(lineArg, ":"); sep >= 0 {
+		b.file, lineArg = lineArg[:sep], lineArg[sep+1:]
+	}
+
+	var err error
+	if b.line, err = strconv.Atoi(lineArg); err != nil || b.line < 1 {
+		fmt.Fprintf(d.out, internal.ErrInvalidBreakpoint+"\n", args[0])
+		return b, false
+	}
+	return b, true
+}
+
+func (d *debugger) setBreakpoint(args []string) bool {
+	if b, ok := d.parseBreakpoint(args); ok {
+		d.breakpoints[b] = true
+		fmt.Fprintf(d.out, "breakpoint set at %v\n", b)
+	}
+	return false
+}
+
+func (d *debugger) clearBreakpoint(args []string) bool {
+	b, ok := d.parseBreakpoint(args)
+	if !ok {
+		return false
+	}
+
+	if !d.breakpoints[b] {
+		fmt.Fprintf(d.out, internal.ErrNoBreakpoint+"\n", b)
+		return false
+	}
+	delete(d.breakpoints, b)
+	fmt.Fprintf(d.out, "breakpoint cleared at %v\n", b)
+	return false
+}
+
+func (d *debugger) listBreakpoints(args []string) bool {
+	bs := make([]breakpoint, 0, len(d.breakpoints))
+	for b := range d.breakpoints {
+		bs = append(bs, b)
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		if bs[i].file != bs[j].file {
+			return bs[i].file < bs[j].file
+		}
+		return bs[i].line < bs[j].line
+	})
+
+	if len(bs) == 0 {
+		fmt.Fprintln(d.out, "no breakpoints")
+	}
+	for _, b := range bs {
+		fmt.Fprintln(d.out, b)
+	}
+	return false
+}
+
+// printStack lists the frames of the call stack, innermost first
+func (d *debugger) printStack(args []string) bool {
+	calls := d.state.CallStack()
+	pos := d.pos
+	for i := len(calls); i >= 0; i-- {
+		name := "<main>"
+		if i > 0 {
+			name = calls[i-1].String()
+		}
+		fmt.Fprintf(d.out, "#%v %v at %v:%v\n", len(calls)-i, name, pos.FileName(), pos.LineNumber())
+		if i > 0 {
+			pos = calls[i-1]
+		}
+	}
+	return false
+}
+
+// visibleVars returns the variables visible in a frame, numbered as by printStack
+func (d *debugger) visibleVars(frame int) (map[string]object.Object, bool) {
+	frames := d.state.Frames()
+	if frame < 0 || frame >= len(frames) {
+		fmt.Fprintf(d.out, internal.ErrInvalidFrame+"\n", frame)
+		return nil, false
+	}
+
+	vars := make(map[string]object.Object)
+	for _, scope := range frames[len(frames)-1-frame] {
+		// inner scopes shadow outer ones
+		for name, o := range scope {
+			vars[name] = o
+		}
+	}
+	return vars, true
+}
+
+func (d *debugger) printVars(args []string) bool {
+	frame := 0
+	if len(args) == 1 {
+		var err error
+		if frame, err = strconv.Atoi(args[0]); err != nil {
+			fmt.Fprintf(d.out, internal.ErrInvalidFrame+"\n", args[0])
+			return false
+		}
+	}
+
+	vars, ok := d.visibleVars(frame)
+	if !ok {
+		return false
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		fmt.Fprintln(d.out, "no variables")
+	}
+	for _, name := range names {
+		fmt.Fprintf(d.out, "%v = %v\n", name, vars[name].Literal())
+	}
+	return false
+}
+
+func (d *debugger) printVar(args []string) bool {
+	if len(args) != 1 {
+		fmt.Fprintln(d.out, "usage: print <variable>")
+		return false
+	}
+
+	vars, _ := d.visibleVars(0)
+	if o, ok := vars[args[0]]; ok {
+		fmt.Fprintf(d.out, "%v = %v\n", args[0], o.Literal())
+	} else {
+		fmt.Fprintf(d.out, internal.ErrUndeclaredIdentifierNode+"\n", args[0])
+	}
+	return false
+}
+
+func (d *debugger) list(args []string) bool {
+	line := d.pos.LineNumber()
+	for l := line - listContext; l <= line+listContext; l++ {
+		d.printLine(d.pos.FileName(), l, l == line)
+	}
+	return false
+}
+
+func (d *debugger) printLine(file string, line int, current bool) {
+	src, ok := d.sources[file]
+	if !ok {
+		if b, err := afero.ReadFile(d.fs, file); err == nil {
+			src = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		}
+		d.sources[file] = src
+	}
+
+	if line < 1 || line > len(src) {
+		return
+	}
+
+	marker := " "
+	if current {
+		marker = ">"
+	}
+	fmt.Fprintf(d.out, "%v %4d | %v\n", marker, line, src[line-1])
+}
+
+func (d *debugger) help(args []string) bool {
+	fmt.Fprint(d.out, `break, b [file:]line    set a breakpoint
+clear [file:]line       remove a breakpoint
+breakpoints             list breakpoints
+continue, c             run until the next breakpoint
+next, n                 run to the next statement, stepping over function calls
+step, s                 run to the next statement, stepping into function calls
+out, o                  run until the current function returns
+stack, bt               print the call stack
+vars, v [frame]         print the variables of a frame, the current frame by default
+print, p name           print a variable of the current frame
+list, l                 list the source around the current line
+quit, q                 abandon the program
+`)
+	return false
+}
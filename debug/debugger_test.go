@@ -0,0 +1,98 @@
+package debug
+
+import (
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"bytes"
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+const testProgram = `func add(a, b) {
+    var s = a + b;
+    return s;
+};
+var x = 1;
+var y = add(x, 2);
+if (y > 2) {
+    var z = add(y, y);
+    x = z;
+};
+`
+
+type debuggerTestCase struct {
+	commands    string
+	breakpoints []int
+	expected    string
+}
+
+func TestDebugger(t *testing.T) {
+	tCs := []debuggerTestCase{
+		{
+			// stepping over and into calls
+			"n\nn\ns\ns\n\nbt\nv\np a\nv 1\no\nq\n",
+			nil,
+			"stopped at test.yum:5\n>    5 | var x = 1;\n" +
+				"(yum) stopped at test.yum:6\n>    6 | var y = add(x, 2);\n" +
+				"(yum) stopped at test.yum:7\n>    7 | if (y > 2) {\n" +
+				"(yum) stopped at test.yum:8\n>    8 |     var z = add(y, y);\n" +
+				"(yum) stopped at test.yum:2\n>    2 |     var s = a + b;\n" +
+				"(yum) stopped at test.yum:3\n>    3 |     return s;\n" +
+				"(yum) #0 add(y, y) at test.yum:3\n#1 <main> at test.yum:8\n" +
+				"(yum) a = 3\nb = 3\ns = 6\n" +
+				"(yum) a = 3\n" +
+				"(yum) x = 1\ny = 3\n" +
+				"(yum) stopped at test.yum:9\n>    9 |     x = z;\n" +
+				"(yum) ",
+		},
+		{
+			// breakpoints
+			"c\nc\nbreakpoints\nclear 2\nbreakpoints\nb test.yum:9\nc\np x\np z\nc\n",
+			[]int{2},
+			"stopped at test.yum:5\n>    5 | var x = 1;\n" +
+				"(yum) breakpoint at test.yum:2\n>    2 |     var s = a + b;\n" +
+				"(yum) breakpoint at test.yum:2\n>    2 |     var s = a + b;\n" +
+				"(yum) test.yum:2\n" +
+				"(yum) breakpoint cleared at test.yum:2\n" +
+				"(yum) no breakpoints\n" +
+				"(yum) breakpoint set at test.yum:9\n" +
+				"(yum) breakpoint at test.yum:9\n>    9 |     x = z;\n" +
+				"(yum) x = 1\n" +
+				"(yum) z = 6\n" +
+				"(yum) program exited\n",
+		},
+		{
+			// invalid commands
+			"o\nfoo\nb\nb x\nclear 3\nv 4\np\nl\n",
+			nil,
+			"stopped at test.yum:5\n>    5 | var x = 1;\n" +
+				"(yum) not in a function call\n" +
+				"(yum) unknown command foo, type help for a list of commands\n" +
+				"(yum) invalid breakpoint \"\", expected [file:]line\n" +
+				"(yum) invalid breakpoint \"x\", expected [file:]line\n" +
+				"(yum) no breakpoint at test.yum:3\n" +
+				"(yum) no frame 4\n" +
+				"(yum) usage: print <variable>\n" +
+				"(yum)      1 | func add(a, b) {\n     2 |     var s = a + b;\n     3 |     return s;\n" +
+				"     4 | };\n>    5 | var x = 1;\n     6 | var y = add(x, 2);\n     7 | if (y > 2) {\n" +
+				"     8 |     var z = add(y, y);\n     9 |     x = z;\n    10 | };\n" +
+				"(yum) ",
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "test.yum", []byte(testProgram), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tC := range tCs {
+		prog := testutil.ParseFile(t, fs, "test.yum")
+
+		out := &bytes.Buffer{}
+		NewDebugger(fs, strings.NewReader(tC.commands), out, WithBreakpoints(tC.breakpoints...)).Run(prog)
+
+		if out.String() != tC.expected {
+			t.Errorf("test case %v: expected output\n%v\ngot\n%v", i, tC.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/debug"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/semantic"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// debugCommand implements yum debug, running a script under the terminal debugger
+func debugCommand(args []string) int {
+	flags := flag.NewFlagSet("debug", flag.ExitOnError)
+	allow := flags.String("allow", object.AllCapabilities.String(),
+		"comma separated capabilities granted to the script's native functions")
+	breaks := flags.String("break", "", "comma separated lines to set breakpoints on")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yum debug [-allow capabilities] [-break lines] file [arg...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Println(internal.ErrFileNotProvided)
+		return 2
+	}
+
+	caps, unknown, ok := object.ParseCapabilitySet(*allow)
+	if !ok {
+		fmt.Printf(internal.ErrUnknownCapability+"\n", unknown)
+		return 2
+	}
+
+	lines := make([]int, 0)
+	for _, b := range strings.Split(*breaks, ",") {
+		if b = strings.TrimSpace(b); b == "" {
+			continue
+		}
+		line, err := strconv.Atoi(b)
+		if err != nil || line < 1 {
+			fmt.Printf(internal.ErrInvalidBreakpoint+"\n", b)
+			return 2
+		}
+		lines = append(lines, line)
+	}
+
+	appFs := afero.NewOsFs()
+	prog, ok := loadProgram(appFs, flags.Arg(0), caps)
+	if !ok {
+		return 1
+	}
+
+	debug.NewDebugger(appFs, os.Stdin, os.Stdout,
+		debug.WithBreakpoints(lines...),
+		debug.WithEvaluatorOptions(eval.WithCapabilities(caps), eval.WithProcess(flags.Args()[1:], os.Stdin)),
+	).Run(prog)
+	return 0
+}
+
+// loadProgram parses and analyses the script at fp and its imports, logging any errors
+func loadProgram(fs afero.Fs, fp string, caps object.CapabilitySet) (*ast.Program, bool) {
+	prog, errs := module.Load(fs, fp)
+	if len(errs) == 0 {
+		sA := semantic.NewSemanticAnalyser(semantic.WithCapabilities(caps))
+		errs = sA.Analyse(prog)
+		for _, w := range sA.Warnings() {
+			log.Println(w)
+		}
+	}
+
+	for _, e := range errs {
+		log.Println(e)
+	}
+	return prog, len(errs) == 0
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/EricNRodriguez/yum/token"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"log"
+	"os"
+)
+
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+)
+
+type dumpToken struct {
+	Type    token.TokenType `json:"type"`
+	Literal string          `json:"literal"`
+	File    string          `json:"file"`
+	Line    int             `json:"line"`
+}
+
+// parseDumpFlags parses the arguments shared by the dump commands, returning the source file and output format
+func parseDumpFlags(name string, args []string) (fp string, format string, ok bool) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	f := flags.String("format", textFormat, "output format, text or json")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: yum %v [-format text|json] file\n", name)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println(internal.ErrFileNotProvided)
+		return "", "", false
+	}
+
+	if *f != textFormat && *f != jsonFormat {
+		fmt.Printf(internal.ErrUnknownFormat+"\n", *f)
+		return "", "", false
+	}
+
+	return flags.Arg(0), *f, true
+}
+
+// tokensCommand implements yum tokens, printing the lexer's token stream
+func tokensCommand(args []string) int {
+	var (
+		f   afero.File
+		l   lexer.Lexer
+		t   token.Token
+		err error
+	)
+
+	fp, format, ok := parseDumpFlags("tokens", args)
+	if !ok {
+		return 2
+	}
+
+	if f, err = afero.NewOsFs().Open(fp); err != nil {
+		log.Println(fmt.Sprintf(internal.ErrFailedToReadFile, fp, err))
+		return 1
+	}
+
+	if l, err = lexer.NewLexer(f); err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer l.Close()
+
+	toks := make([]dumpToken, 0)
+	for t, _ = l.NextToken(); ; t, _ = l.NextToken() {
+		toks = append(toks, dumpToken{
+			Type:    t.Type(),
+			Literal: t.Literal(),
+			File:    t.FileName(),
+			Line:    t.LineNumber(),
+		})
+		if t.Type() == token.EOFToken {
+			break
+		}
+	}
+
+	if format == jsonFormat {
+		return writeJSON(toks)
+	}
+
+	for _, t := range toks {
+		fmt.Printf("%v:%v\t%v\t%q\n", t.File, t.Line, t.Type, t.Literal)
+	}
+	return 0
+}
+
+// astCommand implements yum ast, printing the parsed program
+func astCommand(args []string) int {
+	var (
+		f    afero.File
+		l    lexer.Lexer
+		p    parser.Parser
+		prog *ast.Program
+		err  error
+		errs []error
+	)
+
+	fp, format, ok := parseDumpFlags("ast", args)
+	if !ok {
+		return 2
+	}
+
+	if f, err = afero.NewOsFs().Open(fp); err != nil {
+		log.Println(fmt.Sprintf(internal.ErrFailedToReadFile, fp, err))
+		return 1
+	}
+
+	if l, err = lexer.NewLexer(f); err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer l.Close()
+
+	// the tree is dumped in source order
+	if p, err = parser.NewRecursiveDescentParser(l, parser.WithoutHoisting()); err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	if prog, errs = p.Parse(); len(errs) != 0 {
+		for _, e := range errs {
+			log.Println(e)
+		}
+		return 1
+	}
+
+	if format == jsonFormat {
+		return writeJSON(ast.Dump(prog))
+	}
+
+	if err = ast.Dump(prog).Fprint(os.Stdout); err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(v interface{}) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -6,59 +6,309 @@ import (
 	"github.com/EricNRodriguez/yum/object"
 	"github.com/EricNRodriguez/yum/symbol_table"
 	"github.com/EricNRodriguez/yum/token"
+	"bytes"
 	"flag"
-	"log"
 	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"log"
 	"os"
 )
 
 type Evaluator interface {
 	Evaluate(ast.Node)
+	// ExitCode returns the status the program passed to exit, 0 if it has not called exit
+	ExitCode() int
+	// StackTrace returns the calls being evaluated when the program was ended by an uncaught error, outermost first
+	StackTrace() []*ast.FunctionCallExpression
 }
 
 type evalMethod func(node ast.Node) object.Object
 
+// Hook is called before each statement is evaluated, with the statement's position and the evaluator's state. The
+// program is paused until the hook returns
+type Hook func(md token.Metadata, s State)
+
+// BranchHook is called when an if statement's condition has been evaluated, taken is false if the else block, which
+// may be empty, is to be evaluated
+type BranchHook func(stmt *ast.IfStatement, taken bool)
+
+// Tracer is notified as functions are called and return. Calls are reported once their arguments have been evaluated,
+// and the evaluation of an imported module's top level is reported as a call to its init function
+type Tracer interface {
+	Call(fCall *ast.FunctionCallExpression, native bool)
+	Return(fCall *ast.FunctionCallExpression)
+}
+
+// State is a read only view of a paused evaluator
+type State interface {
+	// CallStack returns the user function calls being evaluated, outermost first
+	CallStack() []*ast.FunctionCallExpression
+	// Frames returns the scopes of the program and of each call on the call stack, outermost first. The scopes of a
+	// frame are ordered from the outermost scope to the innermost
+	Frames() [][]map[string]object.Object
+	// EvaluateInFrame evaluates expr in the innermost scope of a frame, numbered as by Frames. Errors are returned
+	// rather than ending the program
+	EvaluateInFrame(expr ast.Expression, frame int) (object.Object, error)
+}
+
+type Option func(*evaluator)
+
+// the call recorded in the stack trace while a module's top level is evaluated
+const initFunction = "init"
+
+// WithCapabilities restricts the native functions a program may call to those whose
+// capabilities are contained in cs. All capabilities are granted by default
+func WithCapabilities(cs object.CapabilitySet) Option {
+	return func(e *evaluator) {
+		e.capabilities = cs
+	}
+}
+
+// WithNativeFunctions makes fs available to the program, in addition to the standard native functions
+func WithNativeFunctions(fs map[string]*object.NativeFunction) Option {
+	return func(e *evaluator) {
+		for _, f := range fs {
+			e.symbolTable.SetNativeFunc(f)
+		}
+	}
+}
+
+// WithFileSystem makes the program's file functions, such as readFile, act on fs rather than the host's file system
+func WithFileSystem(fs afero.Fs) Option {
+	return WithNativeFunctions(object.NewFileFunctions(fs))
+}
+
+// WithProcess gives the program the command line arguments args, and makes its standard input functions, such as
+// readLine, read from in
+func WithProcess(args []string, in io.Reader) Option {
+	return func(e *evaluator) {
+		e.args, e.in = args, in
+	}
+}
+
+// WithOutput makes the program's output functions, such as print, and the prompts of input write to w rather than
+// the host's standard output
+func WithOutput(w io.Writer) Option {
+	return func(e *evaluator) {
+		e.out = w
+	}
+}
+
+// WithHook calls h before each statement is evaluated, function and struct declarations excluded. Hooks are called
+// in the order they are added
+func WithHook(h Hook) Option {
+	return func(e *evaluator) {
+		e.hooks = append(e.hooks, h)
+	}
+}
+
+// WithBranchHook calls h as each if statement chooses a block
+func WithBranchHook(h BranchHook) Option {
+	return func(e *evaluator) {
+		e.branchHooks = append(e.branchHooks, h)
+	}
+}
+
+// WithTracer reports function calls and returns to t
+func WithTracer(t Tracer) Option {
+	return func(e *evaluator) {
+		e.tracers = append(e.tracers, t)
+	}
+}
+
+// WithRecoverableErrors panics with runtime errors, rather than logging them and exiting, so that a program embedding
+// the evaluator may recover them
+func WithRecoverableErrors() Option {
+	return func(e *evaluator) {
+		e.recoverable = true
+	}
+}
+
 type evaluator struct {
-	stackTrace   internal.StackTrace
+	stackTrace   internal.StackTrace // the calls being evaluated, including those whose arguments are being evaluated
+	callStack    internal.StackTrace // the calls whose arguments have been evaluated, as reported to tracers
 	symbolTable  symbol_table.SymbolTable
 	methodRouter map[ast.NodeType]evalMethod
+	capabilities object.CapabilitySet
+	hooks        []Hook
+	branchHooks  []BranchHook
+	tracers      []Tracer
+	recoverable  bool
+	uncaught     []*ast.FunctionCallExpression // the call stack when an uncaught error ended the program
+	exitCode     int
+	tries        int                                   // the number of try statements being evaluated, whose blocks recover errors
+	modules      map[*ast.Program]*symbol_table.Module // imported modules, each evaluated once
+
+	// the program's arguments, standard input and output, nil unless set by an option
+	args []string
+	in   io.Reader
+	out  io.Writer
 }
 
-func NewEvaluator() (e *evaluator) {
+func NewEvaluator(opts ...Option) (e *evaluator) {
 	e = &evaluator{
-		symbolTable: symbol_table.NewSymbolTable(),
-		stackTrace:  internal.NewStackTrace(),
+		symbolTable:  symbol_table.NewSymbolTable(),
+		stackTrace:   internal.NewStackTrace(),
+		callStack:    internal.NewStackTrace(),
+		capabilities: object.AllCapabilities,
+		modules:      make(map[*ast.Program]*symbol_table.Module),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	// input writes its prompts to the output, so both are created once all options have been applied
+	if e.in != nil || e.out != nil {
+		in, out := e.in, e.out
+		if in == nil {
+			in = os.Stdin
+		}
+		if out == nil {
+			out = os.Stdout
+		}
+		WithNativeFunctions(object.NewOutputFunctions(out))(e)
+		WithNativeFunctions(object.NewProcessFunctions(e.args, in, out))(e)
 	}
 
 	e.methodRouter = map[ast.NodeType]evalMethod{
 		ast.ProgramNode:                      e.evaluateProgramNode,
 		ast.ArrayExpressionNode:              e.evaluateArrayExpression,
 		ast.ArrayIndexExpressionNode:         e.evaluateArrayIndexExpression,
+		ast.StructExpressionNode:             e.evaluateStructExpression,
 		ast.PrefixExpressionNode:             e.evaluatePrefixExpression,
 		ast.InfixExpressionNode:              e.evaluateInfixExpression,
 		ast.IntegerExpressionNode:            e.evaluateIntegerExpression,
 		ast.FloatingPointExpressionNode:      e.evaluateFloatingPointExpression,
 		ast.StringExpressionNode:             e.evaluateStringExpression,
+		ast.InterpolatedStringExpressionNode: e.evaluateInterpolatedStringExpression,
 		ast.BooleanExpressionNode:            e.evaluateBooleanExpression,
 		ast.FunctionCallExpressionNode:       e.evaluateFunctionCallExpression,
+		ast.SafeCallExpressionNode:           e.evaluateSafeCallExpression,
 		ast.IdentifierExpressionNode:         e.evaluateIdentifierExpression,
+		ast.FieldExpressionNode:              e.evaluateFieldExpression,
 		ast.VarStatementNode:                 e.evaluateVarStatement,
 		ast.ReturnStatementNode:              e.evaluateReturnStatement,
 		ast.IfStatementNode:                  e.evaluateIfStatement,
 		ast.WhileStatementNode:               e.evaluateWhileStatement,
+		ast.ThrowStatementNode:               e.evaluateThrowStatement,
+		ast.TryStatementNode:                 e.evaluateTryStatement,
 		ast.FunctionDeclarationStatementNode: e.evaluateFunctionDeclarationStatement,
 		ast.FunctionCallStatementNode:        e.evaluateFunctionCallStatement,
 		ast.AssignmentStatementNode:          e.evaluateAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     e.evaluateFieldAssignmentStatement,
+		ast.StructDeclarationStatementNode:   e.evaluateStructDeclarationStatement,
+		ast.ImportStatementNode:              e.evaluateImportStatement,
 	}
 
 	return
 }
 
+// Evaluate evaluates node, stopping early if the program calls exit
 func (e *evaluator) Evaluate(node ast.Node) {
+	defer func() {
+		if r := recover(); r != nil {
+			exit, ok := r.(*object.ExitError)
+			if !ok {
+				panic(r)
+			}
+			e.exitCode = exit.Code
+		}
+	}()
+
 	e.evaluate(node)
 	return
 }
 
+func (e *evaluator) ExitCode() int {
+	return e.exitCode
+}
+
+func (e *evaluator) StackTrace() []*ast.FunctionCallExpression {
+	return e.uncaught
+}
+
+func (e *evaluator) CallStack() []*ast.FunctionCallExpression {
+	return e.callStack.Calls()
+}
+
+func (e *evaluator) Frames() [][]map[string]object.Object {
+	return e.symbolTable.Frames()
+}
+
+func (e *evaluator) EvaluateInFrame(expr ast.Expression, frame int) (o object.Object, err error) {
+	if frame < 0 || frame >= len(e.symbolTable.Frames()) {
+		return nil, fmt.Errorf(internal.ErrInvalidFrame, frame)
+	}
+
+	// a separate evaluator keeps the program's call stack intact
+	fE := NewEvaluator(WithCapabilities(e.capabilities), WithRecoverableErrors())
+	fE.symbolTable = e.symbolTable.Frame(frame)
+
+	// the expression has not been semantically analysed
+	ast.Inspect(expr, func(n ast.Node) bool {
+		var (
+			name     string
+			declared bool
+		)
+
+		switch n := n.(type) {
+		case *ast.IdentifierExpression:
+			name = n.Name
+			_, declared = fE.symbolTable.GetVar(name)
+		case *ast.FieldExpression:
+			q, ok := n.Qualifier()
+			m, imported := fE.symbolTable.GetImport(q)
+			if !ok || !imported {
+				return true
+			}
+
+			// the qualifier names the module, not a variable
+			name = n.String()
+			if _, declared = m.Globals[n.Field]; !declared && err == nil {
+				err = internal.NewError(n, fmt.Sprintf(internal.ErrUndeclaredIdentifierNode, name), internal.RuntimeErr)
+			}
+			return false
+		case *ast.StructExpression:
+			name = n.Name
+			_, declared = fE.symbolTable.GetStruct(name)
+		case *ast.ArrayIndexExpression:
+			name = n.ArrayName
+			_, declared = fE.symbolTable.GetVar(name)
+		case *ast.FunctionCallExpression:
+			name = n.FunctionName
+			if m, ok := fE.symbolTable.GetImport(n.Module); ok {
+				_, declared = m.Functions[name]
+			} else if n.Module == "" {
+				declared = !fE.symbolTable.AvailableFunc(name)
+			}
+		default:
+			return true
+		}
+
+		if !declared && err == nil {
+			err = internal.NewError(n, fmt.Sprintf(internal.ErrUndeclaredIdentifierNode, name), internal.RuntimeErr)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			rErr, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			o, err = nil, rErr
+		}
+	}()
+
+	return fE.unpack(fE.evaluate(expr)), nil
+}
+
 func (e *evaluator) evaluate(node ast.Node) (o object.Object) {
 	if method, ok := e.methodRouter[node.Type()]; ok {
 		return method(node)
@@ -80,6 +330,34 @@ func (e *evaluator) evaluateIdentifierExpression(node ast.Node) (o object.Object
 	return
 }
 
+func (e *evaluator) evaluateFieldExpression(node ast.Node) (o object.Object) {
+	f := node.(*ast.FieldExpression)
+	if q, ok := f.Qualifier(); ok {
+		if m, ok := e.symbolTable.GetImport(q); ok {
+			return m.Globals[f.Field]
+		}
+	}
+
+	o, _ = e.fieldTarget(f).Get(f.Field)
+	return
+}
+
+// fieldTarget returns the struct that the target of f evaluates to, which must declare the field f reads
+func (e *evaluator) fieldTarget(f *ast.FieldExpression) *object.Struct {
+	v := e.unpack(e.evaluate(f.Target))
+	s, ok := v.(*object.Struct)
+	if !ok {
+		e.quit(internal.NewError(f.Metadata, fmt.Sprintf(internal.ErrType, v.Literal(), object.StructObject),
+			internal.RuntimeErr))
+	}
+
+	if !s.HasField(f.Field) {
+		e.quit(internal.NewError(f.Metadata, fmt.Sprintf(internal.ErrUndeclaredField, s.Name, f.Field),
+			internal.RuntimeErr))
+	}
+	return s
+}
+
 func (e *evaluator) evaluatePrefixExpression(node ast.Node) (o object.Object) {
 	pExpr := node.(*ast.PrefixExpression)
 	rObj := e.evaluate(pExpr.Expression)
@@ -221,6 +499,19 @@ func (e *evaluator) evaluateInfixExpression(node ast.Node) (o object.Object) {
 			e.quit(internal.NewError(iExpr.Data(), fmt.Sprintf(internal.ErrTypeOperation, iExpr.Token.Type(),
 				lObj.Type()), internal.RuntimeErr))
 		}
+	} else if lObj.Type() == object.StructObject && rObj.Type() == object.StructObject {
+		lObj := lObj.(*object.Struct)
+		rObj := rObj.(*object.Struct)
+		switch iExpr.Token.Type() {
+		case token.EqualToken:
+			o = object.NewBoolean(lObj.Equal(rObj))
+		case token.NotEqualToken:
+			o = object.NewBoolean(!lObj.Equal(rObj))
+		default:
+			e.quit(internal.NewError(iExpr.Data(), fmt.Sprintf(internal.ErrTypeOperation, iExpr.Token.Type(),
+				lObj.Type()), internal.RuntimeErr))
+		}
+
 	} else if lObj.Type() == object.StringObject && rObj.Type() == object.StringObject {
 		lObj := lObj.(*object.String)
 		rObj := rObj.(*object.String)
@@ -272,6 +563,19 @@ func (e *evaluator) evaluateStringExpression(node ast.Node) object.Object {
 	return o
 }
 
+// evaluateInterpolatedStringExpression evaluates the embedded expressions in order, writing each in its display form
+func (e *evaluator) evaluateInterpolatedStringExpression(node ast.Node) object.Object {
+	s := node.(*ast.InterpolatedStringExpression)
+	sBuff := bytes.Buffer{}
+	for i, seg := range s.Segments {
+		sBuff.WriteString(seg)
+		if i < len(s.Expressions) {
+			sBuff.WriteString(object.Display(e.evaluate(s.Expressions[i])))
+		}
+	}
+	return object.NewString(sBuff.String())
+}
+
 func (e *evaluator) evaluateArrayExpression(node ast.Node) object.Object {
 	a := node.(*ast.ArrayExpression)
 	oData := make([]object.Object, a.Length)
@@ -286,6 +590,9 @@ func (e *evaluator) evaluateArrayIndexExpression(node ast.Node) (o object.Object
 	iden := node.(*ast.ArrayIndexExpression)
 
 	arrE, _ := e.symbolTable.GetVar(iden.ArrayName)
+	if m, ok := arrE.(object.Keyed); ok {
+		return e.evaluateMapIndexExpression(iden, m)
+	}
 	if arrE.Type() != object.ArrayObject {
 		errMsg := fmt.Sprintf(internal.ErrType, arrE.Literal(), object.ArrayObject)
 		e.quit(internal.NewError(iden.Metadata, errMsg, internal.RuntimeErr))
@@ -308,6 +615,33 @@ func (e *evaluator) evaluateArrayIndexExpression(node ast.Node) (o object.Object
 	return
 }
 
+// evaluateMapIndexExpression evaluates an index into a map or error, which is null if the key is not present
+func (e *evaluator) evaluateMapIndexExpression(iden *ast.ArrayIndexExpression, m object.Keyed) object.Object {
+	keyE := e.evaluate(iden.IndexExpr)
+	key, ok := keyE.(*object.String)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrType, keyE.Literal(), object.StringObject)
+		e.quit(internal.NewError(iden.Metadata, errMsg, internal.RuntimeErr))
+	}
+
+	if o, ok := m.Get(key.Lit); ok {
+		return o
+	}
+	return object.NewNull()
+}
+
+// evaluateStructExpression constructs a struct from the listed fields, the others are null
+func (e *evaluator) evaluateStructExpression(node ast.Node) object.Object {
+	sExpr := node.(*ast.StructExpression)
+	d, _ := e.symbolTable.GetStruct(sExpr.Name)
+
+	s := d.New()
+	for i, f := range sExpr.Fields {
+		s.Set(f.Name, e.unpack(e.evaluate(sExpr.Values[i])))
+	}
+	return s
+}
+
 func (e *evaluator) evaluateFloatingPointExpression(node ast.Node) object.Object {
 	i := node.(*ast.FloatingPointExpression)
 	o := object.NewFloat(i.Value)
@@ -322,22 +656,50 @@ func (e *evaluator) evaluateBooleanExpression(node ast.Node) object.Object {
 
 func (e *evaluator) evaluateFunctionCallExpression(node ast.Node) (o object.Object) {
 	var (
-		fCall = node.(*ast.FunctionCallExpression)
-		err   error
+		fCall  = node.(*ast.FunctionCallExpression)
+		module = e.symbolTable.Module()
+		err    error
 	)
 
+	if fCall.Module != "" {
+		module, _ = e.symbolTable.GetImport(fCall.Module)
+	}
+
+	// struct construction, the arguments are the values of its fields in declaration order
+	if d, ok := module.Structs[fCall.FunctionName]; ok {
+		s := d.New()
+		for i, v := range fCall.Parameters {
+			s.Set(d.Fields[i], e.unpack(e.evaluate(v)))
+		}
+		return s
+	}
+
 	e.stackTrace.Push(fCall) // record function call
 
 	// native function call
-	if f, ok := e.symbolTable.GetUserFunc(fCall.FunctionName); !ok {
+	if f, ok := module.Functions[fCall.FunctionName]; !ok {
 		// evaluate parameters
 		evalParams := make([]object.Object, len(fCall.Parameters))
 		for i, expr := range fCall.Parameters {
 			evalParams[i] = e.evaluate(expr)
 		}
 
+		e.enterCall(fCall, true)
+
 		f, _ := e.symbolTable.GetNativeFunc(fCall.FunctionName)
+		if c, missing := e.capabilities.Missing(f); missing {
+			errMsg := fmt.Sprintf(internal.ErrMissingCapability, fCall.FunctionName, c)
+			e.quit(internal.NewError(fCall.Metadata, errMsg, internal.RuntimeErr))
+		}
+
 		if o, err = f.Function(evalParams...); err != nil {
+			// exit unwinds the program to Evaluate, rather than being reported as an error
+			if exit, ok := err.(*object.ExitError); ok {
+				panic(exit)
+			}
+			if _, ok := err.(*object.TestFailure); ok {
+				e.quit(internal.NewError(fCall.Metadata, err.Error(), internal.TestErr))
+			}
 			e.quit(internal.NewError(fCall.Metadata, err.Error(), internal.RuntimeErr))
 		}
 
@@ -347,11 +709,17 @@ func (e *evaluator) evaluateFunctionCallExpression(node ast.Node) (o object.Obje
 		// evaluate parameters
 		paramValues := map[string]object.Object{}
 		for i, v := range fCall.Parameters {
-			paramValues[f.Parameters[i]] = e.evaluate(v)
+			paramValues[f.Parameters[i]] = e.unpack(e.evaluate(v))
+			if !object.MatchesAnnotation(paramValues[f.Parameters[i]], f.ParameterTypes[i]) {
+				errMsg := fmt.Sprintf(internal.ErrType, paramValues[f.Parameters[i]].Literal(), f.ParameterTypes[i])
+				e.quit(internal.NewError(v, errMsg, internal.RuntimeErr))
+			}
 		}
 
-		// new symbol table
-		e.symbolTable.EnterFunction()
+		e.enterCall(fCall, false)
+
+		// new symbol table, in which the function's module is visible
+		e.symbolTable.EnterModuleFunction(module)
 
 		for k, v := range paramValues {
 			e.symbolTable.SetVar(k, v)
@@ -363,8 +731,14 @@ func (e *evaluator) evaluateFunctionCallExpression(node ast.Node) (o object.Obje
 		}
 		e.symbolTable.ExitFunction()
 
+		if rO := e.unpack(o); !object.MatchesAnnotation(rO, f.ReturnType) {
+			errMsg := fmt.Sprintf(internal.ErrType, rO.Literal(), f.ReturnType)
+			e.quit(internal.NewError(fCall.Metadata, errMsg, internal.RuntimeErr))
+		}
+
 	}
 
+	e.exitCall()
 	e.stackTrace.Pop()
 	if o.Type() == object.ReturnObject {
 		o = o.(*object.ReturnValue).Value
@@ -372,6 +746,24 @@ func (e *evaluator) evaluateFunctionCallExpression(node ast.Node) (o object.Obje
 	return o
 }
 
+// enterCall records that fCall's arguments have been evaluated by the caller, and that it is being called
+func (e *evaluator) enterCall(fCall *ast.FunctionCallExpression, native bool) {
+	e.callStack.Push(fCall)
+	for _, t := range e.tracers {
+		t.Call(fCall, native)
+	}
+	return
+}
+
+// exitCall records that the innermost call entered has returned, or has been unwound by an error
+func (e *evaluator) exitCall() {
+	fCall, _ := e.callStack.Pop()
+	for _, t := range e.tracers {
+		t.Return(fCall)
+	}
+	return
+}
+
 func (e *evaluator) evaluateFunctionCallStatement(node ast.Node) (o object.Object) {
 	stmt := node.(*ast.FunctionCallStatement)
 	e.evaluateFunctionCallExpression(stmt.FunctionCallExpression)
@@ -395,6 +787,13 @@ func (e *evaluator) evaluateAssignmentStatement(node ast.Node) object.Object {
 	return object.NewNull()
 }
 
+func (e *evaluator) evaluateFieldAssignmentStatement(node ast.Node) object.Object {
+	fStmt := node.(*ast.FieldAssignmentStatement)
+	s := e.fieldTarget(fStmt.Field)
+	s.Set(fStmt.Field.Field, e.unpack(e.evaluate(fStmt.Expression)))
+	return object.NewNull()
+}
+
 func (e *evaluator) evaluateReturnStatement(node ast.Node) object.Object {
 	var (
 		o object.Object
@@ -410,6 +809,13 @@ func (e *evaluator) evaluateReturnStatement(node ast.Node) object.Object {
 
 func (e *evaluator) evaluateBlockStatement(stmt ...ast.Statement) (o object.Object) {
 	for _, s := range stmt {
+		// declarations are hoisted, so are not steps of the program's execution
+		if s.Type() != ast.FunctionDeclarationStatementNode && s.Type() != ast.StructDeclarationStatementNode {
+			for _, h := range e.hooks {
+				h(s, e)
+			}
+		}
+
 		if o = e.evaluate(s); o != nil && o.Type() == object.ReturnObject {
 			return
 		}
@@ -424,6 +830,10 @@ func (e *evaluator) evaluateIfStatement(node ast.Node) (o object.Object) {
 	if cond.Type() == object.BooleanObject {
 
 		cond := cond.(*object.Boolean)
+		for _, h := range e.branchHooks {
+			h(ifStmt, cond.Value)
+		}
+
 		e.symbolTable.EnterScope() // enter nested scope
 
 		if cond.Value {
@@ -449,9 +859,14 @@ func (e *evaluator) evaluateWhileStatement(node ast.Node) (o object.Object) {
 		for state {
 
 			e.symbolTable.EnterScope() // enter nested scope
-			e.evaluateBlockStatement(wStmt.Block...)
+			o = e.evaluateBlockStatement(wStmt.Block...)
 			e.symbolTable.ExitScope() // exit nested scope
 
+			// returning from within the loop
+			if o != nil && o.Type() == object.ReturnObject {
+				return
+			}
+
 			state = e.evaluate(wStmt.Condition).(*object.Boolean).Value
 		}
 
@@ -461,6 +876,136 @@ func (e *evaluator) evaluateWhileStatement(node ast.Node) (o object.Object) {
 	return
 }
 
+// evaluateThrowStatement raises the value of the expression as an error. Errors are rethrown unchanged, other values
+// are wrapped in an error whose message is their display form
+func (e *evaluator) evaluateThrowStatement(node ast.Node) object.Object {
+	stmt := node.(*ast.ThrowStatement)
+	v := e.unpack(e.evaluate(stmt.Expression))
+
+	errO, ok := v.(*object.Error)
+	if !ok {
+		errO = object.NewError(object.Display(v), internal.ThrownErr, stmt.LineNumber())
+		errO.Value = v
+	} else if errO.Line == 0 {
+		// created by the error function, which does not know its line
+		errO.Line = stmt.LineNumber()
+	}
+	e.quit(&thrownError{
+		err:   internal.NewError(stmt.Data(), errO.Message, errO.ErrType),
+		value: errO,
+	})
+	return nil
+}
+
+// evaluateTryStatement evaluates the try block, then the catch block if the try block raised an error, then the
+// finally block. An error that is not caught is raised again once the finally block has been evaluated, unless the
+// finally block returns
+func (e *evaluator) evaluateTryStatement(node ast.Node) (o object.Object) {
+	tStmt := node.(*ast.TryStatement)
+
+	o, caught, exit := e.guard(func() object.Object {
+		return e.evaluateNestedBlock(tStmt.TryBlock, nil, nil)
+	})
+	if caught != nil && tStmt.CatchName != nil {
+		// the catch block is also guarded, so that the finally block is evaluated if it raises an error
+		value := caught.value
+		o, caught, exit = e.guard(func() object.Object {
+			return e.evaluateNestedBlock(tStmt.CatchBlock, tStmt.CatchName, value)
+		})
+	}
+
+	if tStmt.FinallyBlock != nil {
+		e.symbolTable.EnterScope() // enter nested scope
+		f := e.evaluateBlockStatement(tStmt.FinallyBlock...)
+		e.symbolTable.ExitScope() // exit nested scope
+
+		// returning from the finally block does not stop the program from exiting
+		if f != nil && f.Type() == object.ReturnObject && exit == nil {
+			return f
+		}
+	}
+
+	if exit != nil {
+		panic(exit)
+	}
+	if caught != nil {
+		e.quit(caught)
+	}
+	return
+}
+
+// evaluateNestedBlock evaluates a block in a nested scope, in which iden, if not nil, is declared with value v
+func (e *evaluator) evaluateNestedBlock(block []ast.Statement, iden *ast.IdentifierExpression, v object.Object) (
+	o object.Object) {
+	e.symbolTable.EnterScope() // enter nested scope
+	if iden != nil {
+		e.symbolTable.SetVar(iden.Name, v)
+	}
+	o = e.evaluateBlockStatement(block...)
+	e.symbolTable.ExitScope() // exit nested scope
+	return
+}
+
+// guard calls f, recovering a runtime or thrown error that it raises, or the exit of the program. Either is returned
+// once the function calls and scopes entered since f was called have been exited, and exits must be passed on by the
+// caller
+func (e *evaluator) guard(f func() object.Object) (o object.Object, caught *thrownError, exit *object.ExitError) {
+	var (
+		calls   = len(e.stackTrace.Calls())
+		entered = len(e.callStack.Calls())
+		frames  = len(e.symbolTable.Frames())
+		scope   = e.symbolTable.GetScope()
+	)
+
+	e.tries++
+	defer func() {
+		e.tries--
+		r := recover()
+		if r == nil {
+			return
+		}
+		exit, _ = r.(*object.ExitError)
+		if caught = catchable(r); caught == nil && exit == nil {
+			panic(r)
+		}
+
+		// the trace is reported as it was when the error was raised, should the error not be caught
+		if caught != nil && caught.calls == nil {
+			caught.calls = e.stackTrace.Calls()
+		}
+		for len(e.stackTrace.Calls()) > calls {
+			e.stackTrace.Pop()
+		}
+		for len(e.callStack.Calls()) > entered {
+			e.exitCall()
+		}
+		for len(e.symbolTable.Frames()) > frames {
+			e.symbolTable.ExitFunction()
+		}
+		for e.symbolTable.GetScope() > scope {
+			e.symbolTable.ExitScope()
+		}
+		o = nil
+	}()
+
+	return f(), nil, nil
+}
+
+// evaluateSafeCallExpression evaluates the call, giving the error it raises, if any, as its value
+func (e *evaluator) evaluateSafeCallExpression(node ast.Node) object.Object {
+	sc := node.(*ast.SafeCallExpression)
+	o, caught, exit := e.guard(func() object.Object {
+		return e.evaluate(sc.Call)
+	})
+	if exit != nil {
+		panic(exit)
+	}
+	if caught != nil {
+		return caught.value
+	}
+	return o
+}
+
 func (e *evaluator) evaluateFunctionDeclarationStatement(node ast.Node) object.Object {
 	fDec := node.(*ast.FunctionDeclarationStatement)
 	paramNames := make([]string, len(fDec.Parameters))
@@ -468,25 +1013,104 @@ func (e *evaluator) evaluateFunctionDeclarationStatement(node ast.Node) object.O
 		paramNames[i] = n.Name
 	}
 	o := object.NewUserFunction(fDec.Name, paramNames, fDec.Body)
+	o.ParameterTypes = fDec.ParameterTypes
+	o.ReturnType = fDec.ReturnType
 	e.symbolTable.SetUserFunc(o)
 	return object.NewNull()
 }
 
+func (e *evaluator) evaluateStructDeclarationStatement(node ast.Node) object.Object {
+	sDec := node.(*ast.StructDeclarationStatement)
+	e.symbolTable.SetStruct(object.NewStructDefinition(sDec.Name, sDec.FieldNames()))
+	return object.NewNull()
+}
+
+// evaluates the imported module once, in its own namespace, as if its top level were a function of the module named
+// init. Later imports of the module share its functions and variables
+func (e *evaluator) evaluateImportStatement(node ast.Node) object.Object {
+	stmt := node.(*ast.ImportStatement)
+
+	m, ok := e.modules[stmt.Program]
+	if !ok {
+		m = symbol_table.NewModule()
+		e.modules[stmt.Program] = m
+
+		initCall := ast.NewFunctionCallExpression(stmt.Metadata, initFunction).(*ast.FunctionCallExpression)
+		initCall.Module = stmt.Name()
+
+		e.stackTrace.Push(initCall)
+		e.enterCall(initCall, false)
+		e.symbolTable.EnterModule(m)
+		e.evaluateBlockStatement(stmt.Program.Statements...)
+		e.symbolTable.ExitFunction()
+		e.exitCall()
+		e.stackTrace.Pop()
+	}
+
+	e.symbolTable.SetImport(stmt.Name(), m)
+	return object.NewNull()
+}
+
 func (e *evaluator) quit(err error) {
+	// recovers in guard
+	if e.tries > 0 {
+		panic(err)
+	}
+
+	calls := e.stackTrace.Calls()
+	if tErr, ok := err.(*thrownError); ok {
+		err = tErr.err
+		if tErr.calls != nil {
+			calls = tErr.calls
+		}
+	}
+
 	// Recovers in TestEvaluator
-	if v := flag.Lookup("test.v"); v != nil {
+	if v := flag.Lookup("test.v"); v != nil || e.recoverable {
+		e.uncaught = calls
 		panic(err)
 	}
 
+	LogError(err, calls)
+	os.Exit(0)
+}
+
+// LogError logs err, an error that ended a program, followed by the calls being evaluated when it was raised
+func LogError(err error, calls []*ast.FunctionCallExpression) {
 	log.Println(err)
 
 	log.Println("stack trace ---------- ")
-	fCall, ok := e.stackTrace.Pop()
+	for i := len(calls) - 1; i >= 0; i-- {
+		log.Println(fmt.Sprintf("FUNCTION CALL %v %v - %v", calls[i].FileName(), calls[i].LineNumber(),
+			calls[i].String()))
+	}
+}
+
+// thrownError is an error raised by a throw statement, or a runtime error recovered by a try statement. err is
+// reported if it is not caught, value is bound to the variable of a catch block
+type thrownError struct {
+	err   *internal.Error
+	value *object.Error
+	calls []*ast.FunctionCallExpression // the calls being evaluated when the error was raised, nil until it unwinds
+}
 
-	for ok == true {
-		log.Println(fmt.Sprintf("FUNCTION CALL %v %v - %v", fCall.FileName(), fCall.LineNumber(),
-			fCall.String()))
-		fCall, ok = e.stackTrace.Pop()
+func (t *thrownError) Error() string {
+	return t.err.Error()
+}
+
+// catchable returns the error a try statement recovers from the panic r, or nil if r is not a runtime or thrown
+// error, such as an exit or a failed test
+func catchable(r interface{}) *thrownError {
+	switch err := r.(type) {
+	case *thrownError:
+		return err
+	case *internal.Error:
+		if err.Type() == internal.RuntimeErr {
+			return &thrownError{
+				err:   err,
+				value: object.NewError(err.Message(), err.Type(), err.LineNumber()),
+			}
+		}
 	}
-	os.Exit(0)
+	return nil
 }
@@ -3,13 +3,17 @@ package eval
 import (
 	"github.com/EricNRodriguez/yum/ast"
 	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"github.com/EricNRodriguez/yum/lexer"
 	"github.com/EricNRodriguez/yum/object"
-	"github.com/EricNRodriguez/yum/symbol_table"
-	"github.com/EricNRodriguez/yum/token"
+	"github.com/EricNRodriguez/yum/parser"
 	"github.com/EricNRodriguez/yum/semantic"
-
+	"github.com/EricNRodriguez/yum/token"
+	"bytes"
 	"fmt"
 	"github.com/spf13/afero"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -99,6 +103,27 @@ func TestEvaluator(t *testing.T) {
 				},
 			},
 		},
+		{
+			[]byte("func f(x) { return x * 2; }; var a = 1; var b = [\"c\", 2.5]; " +
+				"var s = \"${a} + ${f(a)} = ${a + f(a)}, ${b} ${\"${true}\"} ${isNull(a)}\";"),
+			false,
+			[]symbol{
+				{
+					"s",
+					"\"1 + 2 = 3, [\"c\",2.5] true false\"",
+				},
+			},
+		},
+		{
+			[]byte("var a = 1; var s = \"\\\"${a}\\\"\\t\\\\\";"),
+			false,
+			[]symbol{
+				{
+					"s",
+					"\"\"1\"\t\\\"",
+				},
+			},
+		},
 		{
 			[]byte("var a = +2; var b = +-2; var c = -+2; var d = --+--2; var e = -02; var f = -020;"),
 			false,
@@ -208,15 +233,15 @@ func TestEvaluator(t *testing.T) {
 			[]symbol{
 				{
 					"a",
-					"-0.041667",
+					"-0.041666666666666664",
 				},
 				{
 					"b",
-					"-0.375000",
+					"-0.375",
 				},
 				{
 					"c",
-					"-0.666667",
+					"-0.6666666666666666",
 				},
 			},
 		},
@@ -226,15 +251,15 @@ func TestEvaluator(t *testing.T) {
 			[]symbol{
 				{
 					"a",
-					"3.333333",
+					"3.333333333333333",
 				},
 				{
 					"b",
-					"1.625000",
+					"1.625",
 				},
 				{
 					"c",
-					"4.000000",
+					"4.0",
 				},
 			},
 		},
@@ -689,6 +714,16 @@ func TestEvaluator(t *testing.T) {
 			true, // index type
 			[]symbol{},
 		},
+		{
+			[]byte("var s = \"${1 / 0}\";"),
+			true, // errors in embedded expressions
+			[]symbol{},
+		},
+		{
+			[]byte("func getInt() {return 1;}; var m = jsonParse(\"{}\"); var x = m[getInt()];"),
+			true, // maps are indexed by strings
+			[]symbol{},
+		},
 		{
 			[]byte("var x = -\"hello\";"),
 			true, // invalid prefix op
@@ -719,6 +754,336 @@ func TestEvaluator(t *testing.T) {
 			true, // invalid prefix op
 			[]symbol{},
 		},
+		{
+			[]byte("func add(a: int, b: int): int { return a + b; }; var x: int = add(1, 2);"),
+			false,
+			[]symbol{
+				{
+					"x",
+					"3",
+				},
+			},
+		},
+		{
+			[]byte("func first(a: [string]): string { return a[0]; }; var x = first([\"a\", \"b\"]);"),
+			false,
+			[]symbol{
+				{
+					"x",
+					"\"a\"",
+				},
+			},
+		},
+		{
+			[]byte("func double(a: int): int { return a * 2; }; var xs = [1, \"a\"]; var x = double(xs[1]);"),
+			true, // argument does not match parameter annotation
+			[]symbol{},
+		},
+		{
+			[]byte("func get(a, i): int { return a[i]; }; var x = get([1, \"a\"], 1);"),
+			true, // return value does not match return annotation
+			[]symbol{},
+		},
+		{
+			[]byte("func sum(a: [int]): int { return a[0] + a[1]; }; var xs = [1, \"a\"]; var x = sum(xs);"),
+			true, // array element does not match annotation
+			[]symbol{},
+		},
+		{
+			[]byte("func find(xs, v) { var i = 0; while (i < length(xs)) { if (xs[i] == v) { return i; }; i = i + 1; }; " +
+				"return -1; }; var x = find([4, 5, 6], 5); var y = find([4], 7);"),
+			false,
+			[]symbol{
+				{
+					"x",
+					"1",
+				},
+				{
+					"y",
+					"-1",
+				},
+			},
+		},
+		{
+			[]byte("var m = \"\"; var t = \"\"; var l = 0; try { var x = 1 / 0; } catch (e) { m = e[\"message\"]; " +
+				"t = e[\"type\"]; l = e[\"line\"]; };"),
+			false,
+			[]symbol{
+				{
+					"m",
+					"\"division by zero\"",
+				},
+				{
+					"t",
+					"\"runtime error\"",
+				},
+				{
+					"l",
+					"1",
+				},
+			},
+		},
+		{
+			[]byte("func at(a, i) { var x = 1; return a[i]; }; var x = 0; try { x = at([1], 3); } catch (e) { x = -1; }; " +
+				"var y = at([5], 0);"),
+			false, // the call's scope is exited when the error is caught
+			[]symbol{
+				{
+					"x",
+					"-1",
+				},
+				{
+					"y",
+					"5",
+				},
+			},
+		},
+		{
+			[]byte("var v = 0; var m = \"\"; try { throw [1, \"a\"]; } catch (e) { v = e[\"value\"]; m = e[\"message\"]; };"),
+			false,
+			[]symbol{
+				{
+					"v",
+					"[1,\"a\"]",
+				},
+				{
+					"m",
+					"\"[1,\"a\"]\"",
+				},
+			},
+		},
+		{
+			[]byte("var log = \"\"; try { log = log + \"t\"; throw \"x\"; log = log + \"u\"; } catch (e) { log = log + \"c\"; } " +
+				"finally { log = log + \"f\"; }; try { log = log + \"t\"; } finally { log = log + \"f\"; };"),
+			false,
+			[]symbol{
+				{
+					"log",
+					"\"tcftf\"",
+				},
+			},
+		},
+		{
+			[]byte("func f() { try { throw \"x\"; } finally { return 2; }; }; func g() { try { return 1; } finally { var y = 0; }; }; " +
+				"var x = f(); var y = g();"),
+			false, // returning from the finally block discards the error
+			[]symbol{
+				{
+					"x",
+					"2",
+				},
+				{
+					"y",
+					"1",
+				},
+			},
+		},
+		{
+			[]byte("var m = \"\"; try { try { throw \"inner\"; } catch (e) { throw e[\"message\"] + \" again\"; }; } " +
+				"catch (e) { m = e[\"message\"]; };"),
+			false,
+			[]symbol{
+				{
+					"m",
+					"\"inner again\"",
+				},
+			},
+		},
+		{
+			[]byte("var e = 0; try { throw \"x\"; } catch (e) { e = 1; }; var a = [1]; try { var z = a[2]; } catch (err) { throw err; };"),
+			true, // rethrown errors are not caught
+			[]symbol{},
+		},
+		{
+			[]byte("try { throw 1; } finally { var x = 0; };"),
+			true, // finally does not catch the error
+			[]symbol{},
+		},
+		{
+			[]byte("func f(a) { if (a < 0) { throw error(\"negative\"); }; return a * 2; }; var x = try f(2); var y = try f(-1); " +
+				"var m = errorMessage(y); var l = y[\"line\"]; var e = isError(y); var n = isError(x);"),
+			false,
+			[]symbol{
+				{
+					"x",
+					"4",
+				},
+				{
+					"y",
+					"error(\"negative\")",
+				},
+				{
+					"m",
+					"\"negative\"",
+				},
+				{
+					"l",
+					"1",
+				},
+				{
+					"e",
+					"true",
+				},
+				{
+					"n",
+					"false",
+				},
+			},
+		},
+		{
+			[]byte("var x = try length(1); var m = errorMessage(x); var t = x[\"type\"]; var y = try isNull(1 / 0);"),
+			false, // errors of native functions and of the arguments are recovered
+			[]symbol{
+				{
+					"m",
+					"\"integer not of type ArrayNode\"",
+				},
+				{
+					"t",
+					"\"runtime error\"",
+				},
+				{
+					"y",
+					"error(\"division by zero\")",
+				},
+			},
+		},
+		{
+			[]byte("var e = error(\"a\"); var l = e[\"line\"]; var m = errorMessage(error(\"b\"));"),
+			false, // the error function does not raise the error
+			[]symbol{
+				{
+					"l",
+					"0",
+				},
+				{
+					"m",
+					"\"b\"",
+				},
+			},
+		},
+		{
+			[]byte("var m = errorMessage(\"a\");"),
+			true, // not an error
+			[]symbol{},
+		},
+		{
+			[]byte("var p = Point{y: 2, x: 1}; var q = Point(1, 2); var e = p == q; var n = p != q; var o = Point{x: \"a\"}; " +
+				"var f = o == Point{x: \"a\"}; struct Point { x, y };"),
+			false,
+			[]symbol{
+				{
+					"p",
+					"Point{x: 1, y: 2}",
+				},
+				{
+					"e",
+					"true",
+				},
+				{
+					"n",
+					"false",
+				},
+				{
+					"o",
+					"Point{x: \"a\", y: null}",
+				},
+				{
+					"f",
+					"true",
+				},
+			},
+		},
+		{
+			[]byte("struct Line { a, b }; struct Point { x, y }; var p = Point(1, 2); var l = Line(p, Point(3, 4)); var q = p; " +
+				"q.x = 5; var x = p.x; var a = l.a; var y = a.x; var e = Line(Point(5, 2), Point(3, 4)) == l; " +
+				"var f = Point(1, 2.0) == Point(1, 2);"),
+			false, // structs are shared by the variables holding them, and compared by value
+			[]symbol{
+				{
+					"x",
+					"5",
+				},
+				{
+					"y",
+					"5",
+				},
+				{
+					"e",
+					"true",
+				},
+				{
+					"f",
+					"false",
+				},
+			},
+		},
+		{
+			[]byte("struct L { a }; struct P { x }; func mk(x) { return P(x); }; var l = L(P(1)); var ps = [P(2), l.a]; " +
+				"l.a.x = 3; ps[0].x = l.a.x + 1; var x = ps[1].x; var y = ps[0].x; var z = mk(5).x;"),
+			false, // fields are read from and assigned to any expression holding a struct
+			[]symbol{
+				{
+					"x",
+					"3",
+				},
+				{
+					"y",
+					"4",
+				},
+				{
+					"z",
+					"5",
+				},
+			},
+		},
+		{
+			[]byte("struct L { a }; struct P { x }; var l = L(P(1)); l.a.y = 2;"),
+			true, // P has no field y
+			[]symbol{},
+		},
+		{
+			[]byte("struct N { next }; var n = N(0); n.next = n; var m = N(0); m.next = [m]; var a = [n, m]; " +
+				"var e = n == n; var f = n == N(n); var g = m == n; var o = N(1); o.next = o; var h = n == o;"),
+			false, // structs that contain themselves are printed and compared without recursing forever
+			[]symbol{
+				{
+					"a",
+					"[N{next: <cycle>},N{next: [<cycle>]}]",
+				},
+				{
+					"e",
+					"true",
+				},
+				{
+					"f",
+					"true",
+				},
+				{
+					"g",
+					"false",
+				},
+				{
+					"h",
+					"true",
+				},
+			},
+		},
+		{
+			[]byte("struct P { x }; func f(a) { return a.y; }; var y = f(P(1));"),
+			true, // P has no field y
+			[]symbol{},
+		},
+		{
+			[]byte("var a = 1; var x = a.x;"),
+			true, // a does not hold a struct
+			[]symbol{},
+		},
+		{
+			[]byte("struct P { x }; var e = P(1) < P(2);"),
+			true, // structs are not ordered
+			[]symbol{},
+		},
 	}
 
 	var (
@@ -811,3 +1176,334 @@ func TestEvaluator(t *testing.T) {
 	}
 	return
 }
+
+func TestHook(t *testing.T) {
+	type step struct {
+		line   int
+		calls  string
+		frames int
+	}
+
+	input := "func f(a) {\n    var b = a;\n    return b;\n};\nvar x = f(f(1));\nif (x == 1) {\n    x = 2;\n};"
+	expected := []step{
+		{5, "", 1},
+		{2, "f(1)", 2},
+		{3, "f(1)", 2},
+		{2, "f(f(1))", 2}, // arguments are evaluated before the call is recorded
+		{3, "f(f(1))", 2},
+		{6, "", 1},
+		{7, "", 1},
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "hook.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("hook.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	steps := make([]step, 0)
+	NewEvaluator(WithHook(func(md token.Metadata, s State) {
+		calls := make([]string, 0)
+		for _, c := range s.CallStack() {
+			calls = append(calls, c.String())
+		}
+		steps = append(steps, step{md.LineNumber(), strings.Join(calls, " "), len(s.Frames())})
+	})).Evaluate(prog)
+
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected steps %v, got %v", expected, steps)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	input := "func f(x) {\n    return x / 0;\n};\nvar y = try f(2);\nprint(f(1));"
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "trace.yum", []byte(input))
+
+	e := NewEvaluator(WithRecoverableErrors())
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected the division by zero to end the program")
+			}
+		}()
+		e.Evaluate(prog)
+	}()
+
+	// a call is on the trace while its arguments are evaluated, and calls unwound by a caught error are not
+	calls := make([]string, 0)
+	for _, c := range e.StackTrace() {
+		calls = append(calls, c.String())
+	}
+	if expected := []string{"print(f(1))", "f(1)"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected stack trace %v, got %v", expected, calls)
+	}
+}
+
+func TestExit(t *testing.T) {
+	input := "func f(x) {\n    if (x > 1) {\n        exit(x);\n    };\n    return x;\n};\nvar a = f(1);\nvar b = f(3);\nvar c = 5;"
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "exit.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("exit.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	e := NewEvaluator()
+	e.Evaluate(prog)
+
+	if e.ExitCode() != 3 {
+		t.Errorf("expected exit status 3, got %v", e.ExitCode())
+	}
+
+	// the program ends at the call to exit, within f
+	globals := e.Frames()[0][0]
+	if a, ok := globals["a"]; !ok || a.Literal() != "1" {
+		t.Errorf("expected a to be 1, got %v", a)
+	}
+	if _, ok := globals["c"]; ok {
+		t.Error("expected c not to be declared")
+	}
+}
+
+func TestExitFinally(t *testing.T) {
+	input := "func f() {\n    try {\n        exit(3);\n    } finally {\n        print(\"f\");\n        return 1;\n    };\n};\n" +
+		"try {\n    try {\n        f();\n    } catch (e) {\n        print(\"c\");\n    } finally {\n        print(\"g\");\n" +
+		"    };\n} finally {\n    print(\"h\");\n};\nprint(\"end\");"
+	prog := testutil.Parse(t, afero.NewMemMapFs(), "exit.yum", []byte(input))
+
+	out := &bytes.Buffer{}
+	e := NewEvaluator(WithOutput(out))
+	e.Evaluate(prog)
+
+	if e.ExitCode() != 3 {
+		t.Errorf("expected exit status 3, got %v", e.ExitCode())
+	}
+
+	// every enclosing finally block runs before the program ends, exit is not caught and a return does not stop it
+	if out.String() != "f\ng\nh\n" {
+		t.Errorf("expected output %q, got %q", "f\ng\nh\n", out.String())
+	}
+}
+
+type callCounter struct {
+	calls, returns int
+}
+
+func (c *callCounter) Call(*ast.FunctionCallExpression, bool) { c.calls++ }
+
+func (c *callCounter) Return(*ast.FunctionCallExpression) { c.returns++ }
+
+func TestUncaughtError(t *testing.T) {
+	input := "func f(x) {\n    throw x;\n};\ntry {\n    f(1);\n} catch (e) {\n    var y = 1;\n};\ntry {\n    exit(2);\n} " +
+		"catch (e) {\n    var y = 1;\n};\nf(\"x\");"
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "uncaught.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("uncaught.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	// exit is not caught, so the error raised after it is never reached
+	counter := &callCounter{}
+	e := NewEvaluator(WithTracer(counter), WithRecoverableErrors())
+	e.Evaluate(prog)
+	if e.ExitCode() != 2 {
+		t.Errorf("expected exit status 2, got %v", e.ExitCode())
+	}
+
+	// the calls unwound by the caught error and by the exit are reported as returning
+	if counter.calls != 2 || counter.returns != 2 {
+		t.Errorf("expected 2 calls and 2 returns, got %v and %v", counter.calls, counter.returns)
+	}
+
+	// without the exit, the final call's error is not caught
+	prog.Statements = append(prog.Statements[:2], prog.Statements[3:]...)
+	defer func() {
+		err, ok := recover().(*internal.Error)
+		if !ok || err.Type() != internal.ThrownErr || err.Message() != "x" || err.LineNumber() != 2 {
+			t.Errorf("expected the thrown error x at line 2, got %v", err)
+		}
+	}()
+	NewEvaluator(WithRecoverableErrors()).Evaluate(prog)
+	t.Error("expected the uncaught error to end the program")
+}
+
+func TestMapIndex(t *testing.T) {
+	input := "var m = jsonParse(readFile(\"data.json\"));\nvar x = m[\"a\"];\nvar y = m[\"b\"];\nvar z = length(m);"
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "map.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "data.json", []byte(`{"a": [1, 2.5, "c"], "d": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("map.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	e := NewEvaluator(WithFileSystem(fs))
+	e.Evaluate(prog)
+
+	globals := e.Frames()[0][0]
+	for iden, expected := range map[string]string{"x": "[1,2.5,\"c\"]", "y": "null", "z": "2"} {
+		if v, ok := globals[iden]; !ok || v.Literal() != expected {
+			t.Errorf("expected %v to be %v, got %v", iden, expected, v)
+		}
+	}
+}
+
+func TestOutput(t *testing.T) {
+	input := "var name = input(\"name? \");\nprint(\"hello\", name, 1.5);\nwrite(sprintf(\"%03d\", 7));"
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "output.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Open("output.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("failed to parse test program: %v", errs)
+	}
+
+	out := strings.Builder{}
+	NewEvaluator(WithOutput(&out), WithProcess(nil, strings.NewReader("yum\n"))).Evaluate(prog)
+
+	if expected := "name? hello yum 1.5\n007"; out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	// probe requires the env capability, and is only available to programs given it as a native function
+	probe := object.NewNativeFunction("probe", 0, func(args ...object.Object) (object.Object, error) {
+		return object.NewInteger(1), nil
+	}, object.EnvCapability)
+
+	tCs := []struct {
+		input        string
+		capabilities object.CapabilitySet
+		err          bool
+	}{
+		{"var x = probe();", object.AllCapabilities, false},
+		{"var x = probe();", object.NewCapabilitySet(object.EnvCapability), false},
+		{"var x = probe();", object.NewCapabilitySet(object.IOCapability, object.FSCapability), true},
+		{"var x = probe();", object.NewCapabilitySet(), true},
+		{"var x = length([1, 2]);", object.NewCapabilitySet(), false}, // pure functions require no capability
+		{"var x = readFile(\"a.txt\");", object.NewCapabilitySet(object.IOCapability), true},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("capabilities_%v.yum", i), []byte(tC.input))
+
+		func() {
+			defer func() {
+				r := recover()
+				err, ok := r.(*internal.Error)
+				if !tC.err && r != nil {
+					t.Errorf("test case %v: unexpected runtime error %v", i+1, r)
+				} else if tC.err && (!ok || !strings.Contains(err.Message(), "capability")) {
+					t.Errorf("test case %v: expected a missing capability error, got %v", i+1, r)
+				}
+			}()
+
+			e := NewEvaluator(WithNativeFunctions(map[string]*object.NativeFunction{"probe": probe}),
+				WithCapabilities(tC.capabilities), WithRecoverableErrors())
+			e.Evaluate(prog)
+
+			if v, ok := e.Frames()[0][0]["x"]; !tC.err && (!ok || v.Type() != object.IntegerObject) {
+				t.Errorf("test case %v: expected x to be an integer, got %v", i+1, v)
+			}
+		}()
+	}
+}
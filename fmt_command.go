@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/format"
+	"github.com/EricNRodriguez/yum/internal"
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"log"
+	"os"
+)
+
+// formatCommand implements yum fmt, returning the exit status
+func formatCommand(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write the result to the source file instead of stdout")
+	diff := flags.Bool("d", false, "print diffs instead of the formatted source")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yum fmt [-w] [-d] file...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Println(internal.ErrFileNotProvided)
+		return 2
+	}
+
+	appFs := afero.NewOsFs()
+	status := 0
+
+	for _, fp := range flags.Args() {
+		var (
+			src       []byte
+			formatted []byte
+			errs      []error
+			err       error
+		)
+
+		if src, err = afero.ReadFile(appFs, fp); err != nil {
+			log.Println(fmt.Sprintf(internal.ErrFailedToReadFile, fp, err))
+			status = 1
+			continue
+		}
+
+		if formatted, errs = format.Source(appFs, fp); len(errs) != 0 {
+			for _, e := range errs {
+				log.Println(e)
+			}
+			status = 1
+			continue
+		}
+
+		if *diff {
+			os.Stdout.Write(format.Diff(fp, src, formatted))
+		}
+
+		if *write && !bytes.Equal(src, formatted) {
+			if err = afero.WriteFile(appFs, fp, formatted, 0644); err != nil {
+				log.Println(err)
+				status = 1
+			}
+		}
+
+		if !*diff && !*write {
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	return status
+}
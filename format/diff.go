@@ -0,0 +1,144 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type editKind int
+
+const (
+	keep editKind = iota
+	remove
+	insert
+)
+
+type edit struct {
+	kind editKind
+	line string
+	a, b int // line indexes in a and b before the edit is applied
+}
+
+// Diff returns a unified diff from a to b, labelled with name, or nil if they are equal
+func Diff(name string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+
+	edits := lineEdits(splitLines(a), splitLines(b))
+
+	buff := bytes.Buffer{}
+	buff.WriteString(fmt.Sprintf("--- %v.orig\n+++ %v\n", name, name))
+
+	for start := 0; start < len(edits); {
+		// find the next change
+		for start < len(edits) && edits[start].kind == keep {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// extend the hunk while changes are separated by less than two contexts
+		end, kept := start, 0
+		for i := start; i < len(edits) && kept <= 2*diffContext; i++ {
+			if edits[i].kind == keep {
+				kept++
+			} else {
+				end, kept = i+1, 0
+			}
+		}
+
+		from, to := max(start-diffContext, 0), min(end+diffContext, len(edits))
+		writeHunk(&buff, edits[from:to])
+		start = to
+	}
+
+	return buff.Bytes()
+}
+
+func writeHunk(buff *bytes.Buffer, edits []edit) {
+	aLines, bLines := 0, 0
+	for _, e := range edits {
+		if e.kind != insert {
+			aLines++
+		}
+		if e.kind != remove {
+			bLines++
+		}
+	}
+
+	buff.WriteString(fmt.Sprintf("@@ -%v,%v +%v,%v @@\n", edits[0].a+1, aLines, edits[0].b+1, bLines))
+	for _, e := range edits {
+		switch e.kind {
+		case keep:
+			buff.WriteString(" ")
+		case remove:
+			buff.WriteString("-")
+		case insert:
+			buff.WriteString("+")
+		}
+		buff.WriteString(e.line + "\n")
+	}
+}
+
+// lineEdits returns the shortest edit script from a to b, found from their longest common subsequence
+func lineEdits(a, b []string) []edit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{kind: keep, line: a[i], a: i, b: j})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{kind: remove, line: a[i], a: i, b: j})
+			i++
+		default:
+			edits = append(edits, edit{kind: insert, line: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return edits
+}
+
+func splitLines(src []byte) []string {
+	s := strings.TrimSuffix(string(src), "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,527 @@
+package format
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/EricNRodriguez/yum/token"
+	"bytes"
+	"fmt"
+	"github.com/spf13/afero"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const indentation = "    "
+
+type statementMethod func(stmt ast.Statement)
+type expressionMethod func(expr ast.Expression) string
+
+// printer writes the canonical source of a program: one statement per line, blocks indented by four spaces, single
+// spaces around binary operators and after commas, and only the parentheses required by operator precedence. Comments
+// are kept on the line they annotate, and at most one blank line is kept between statements. A comment within a
+// statement is kept after the node it follows, and the statement continues on the next line
+type printer struct {
+	buf              bytes.Buffer
+	depth            int
+	comments         []*ast.Comment
+	lastLine         int  // source line of the last statement or comment printed
+	line             int  // source line of the last node formatted within the current statement
+	blockStart       bool // nothing has been printed in the current block yet
+	statementRouter  map[ast.NodeType]statementMethod
+	expressionRouter map[ast.NodeType]expressionMethod
+}
+
+func newPrinter(comments []*ast.Comment) (p *printer) {
+	p = &printer{
+		comments: comments,
+	}
+
+	p.statementRouter = map[ast.NodeType]statementMethod{
+		ast.VarStatementNode:                 p.printVarStatement,
+		ast.AssignmentStatementNode:          p.printAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     p.printFieldAssignmentStatement,
+		ast.ReturnStatementNode:              p.printReturnStatement,
+		ast.IfStatementNode:                  p.printIfStatement,
+		ast.WhileStatementNode:               p.printWhileStatement,
+		ast.ThrowStatementNode:               p.printThrowStatement,
+		ast.TryStatementNode:                 p.printTryStatement,
+		ast.FunctionDeclarationStatementNode: p.printFunctionDeclarationStatement,
+		ast.StructDeclarationStatementNode:   p.printStructDeclarationStatement,
+		ast.FunctionCallStatementNode:        p.printFunctionCallStatement,
+		ast.ImportStatementNode:              p.printImportStatement,
+	}
+
+	p.expressionRouter = map[ast.NodeType]expressionMethod{
+		ast.IdentifierExpressionNode:         p.formatIdentifierExpression,
+		ast.IntegerExpressionNode:            p.formatIntegerExpression,
+		ast.FloatingPointExpressionNode:      p.formatFloatingPointExpression,
+		ast.StringExpressionNode:             p.formatStringExpression,
+		ast.InterpolatedStringExpressionNode: p.formatInterpolatedStringExpression,
+		ast.BooleanExpressionNode:            p.formatBooleanExpression,
+		ast.ArrayExpressionNode:              p.formatArrayExpression,
+		ast.ArrayIndexExpressionNode:         p.formatArrayIndexExpression,
+		ast.StructExpressionNode:             p.formatStructExpression,
+		ast.FieldExpressionNode:              p.formatFieldExpression,
+		ast.PrefixExpressionNode:             p.formatPrefixExpression,
+		ast.SafeCallExpressionNode:           p.formatSafeCallExpression,
+		ast.InfixExpressionNode:              p.formatInfixExpression,
+		ast.FunctionCallExpressionNode:       p.formatFunctionCallExpression,
+	}
+
+	return
+}
+
+// Program returns the canonical source of prog. Statements are printed in the order they appear in prog, so programs
+// should be parsed with parser.WithoutHoisting
+func Program(prog *ast.Program) []byte {
+	p := newPrinter(prog.Comments)
+	p.printBlock(prog.Statements, math.MaxInt32)
+	return p.buf.Bytes()
+}
+
+// Source formats the yum source file at fp
+func Source(fs afero.Fs, fp string) (formatted []byte, errs []error) {
+	var (
+		f    afero.File
+		l    lexer.Lexer
+		p    parser.Parser
+		prog *ast.Program
+		err  error
+	)
+
+	if f, err = fs.Open(fp); err != nil {
+		return nil, []error{err}
+	}
+
+	if l, err = lexer.NewLexer(f); err != nil {
+		return nil, []error{err}
+	}
+	defer l.Close()
+
+	if p, err = parser.NewRecursiveDescentParser(l, parser.WithoutHoisting()); err != nil {
+		return nil, []error{err}
+	}
+
+	if prog, errs = p.Parse(); len(errs) != 0 {
+		return nil, errs
+	}
+
+	return Program(prog), nil
+}
+
+// printBlock prints stmts at the current depth, followed by any comments before end, the line of the block's closing
+// brace
+func (p *printer) printBlock(stmts []ast.Statement, end int) {
+	p.blockStart = true
+
+	for i, s := range stmts {
+		p.printCommentsBefore(s.LineNumber())
+		p.separate(s.LineNumber())
+		p.buf.WriteString(strings.Repeat(indentation, p.depth))
+		p.line = s.LineNumber()
+		p.statementRouter[s.Type()](s)
+
+		last := lastLine(s)
+		// a comment following several statements on one line annotates the last of them, or the closing brace
+		if (i == len(stmts)-1 || stmts[i+1].LineNumber() != last) && last != end {
+			p.printTrailingComment(last)
+		}
+		p.buf.WriteString("\n")
+		p.lastLine = last
+	}
+
+	p.printCommentsBefore(end)
+}
+
+// printNestedBlock prints the body of a compound statement, from its opening brace on line open up to and including
+// its closing brace
+func (p *printer) printNestedBlock(stmts []ast.Statement, open int, end token.Metadata) {
+	// comments within blocks of unknown extent are printed after the enclosing statement
+	endLine := 0
+	if end != nil {
+		endLine = end.LineNumber()
+	}
+
+	p.buf.WriteString("{")
+	// a comment after the opening brace stays there, unless the block closes on the same line
+	if len(stmts) > 0 && stmts[0].LineNumber() > open || len(stmts) == 0 && endLine > open {
+		p.printTrailingComment(open)
+	}
+	p.buf.WriteString("\n")
+	p.lastLine = open
+	p.depth++
+	p.printBlock(stmts, endLine)
+	p.depth--
+	p.buf.WriteString(strings.Repeat(indentation, p.depth) + "}")
+	p.blockStart = false
+}
+
+// separate keeps a single blank line wherever the source had one or more
+func (p *printer) separate(line int) {
+	if !p.blockStart && line > p.lastLine+1 {
+		p.buf.WriteString("\n")
+	}
+	p.blockStart = false
+}
+
+func (p *printer) printCommentsBefore(line int) {
+	for len(p.comments) > 0 && p.comments[0].LineNumber() < line {
+		c := p.comments[0]
+		p.separate(c.LineNumber())
+		p.buf.WriteString(strings.Repeat(indentation, p.depth) + c.Text + "\n")
+		p.lastLine = c.LineNumber()
+		p.comments = p.comments[1:]
+	}
+}
+
+func (p *printer) printTrailingComment(line int) {
+	if len(p.comments) > 0 && p.comments[0].LineNumber() == line {
+		p.buf.WriteString(" " + p.comments[0].Text)
+		p.comments = p.comments[1:]
+	}
+}
+
+// interject returns the comments within the current statement before line, each followed by a line break. A comment
+// on the line of the last node formatted follows it, others are given their own line
+func (p *printer) interject(line int) string {
+	sBuff := strings.Builder{}
+	for len(p.comments) > 0 && p.comments[0].LineNumber() < line {
+		c := p.comments[0]
+		switch {
+		case c.LineNumber() == p.line:
+			sBuff.WriteString(" ")
+		case sBuff.Len() == 0:
+			sBuff.WriteString("\n" + strings.Repeat(indentation, p.depth+1))
+		}
+		sBuff.WriteString(c.Text + "\n" + strings.Repeat(indentation, p.depth+1))
+		p.line = c.LineNumber()
+		p.comments = p.comments[1:]
+	}
+	return sBuff.String()
+}
+
+// spaced prefixes s with a space, unless s starts with a comment
+func spaced(s string) string {
+	if strings.HasPrefix(s, " ") || strings.HasPrefix(s, "\n") {
+		return s
+	}
+	return " " + s
+}
+
+// lastLine is the source line a statement ends on
+func lastLine(stmt ast.Statement) (line int) {
+	var end token.Metadata
+	switch s := stmt.(type) {
+	case *ast.IfStatement:
+		end = s.ElseBlockEnd
+		if s.ElseBlock == nil {
+			end = s.IfBlockEnd
+		}
+	case *ast.WhileStatement:
+		end = s.BlockEnd
+	case *ast.TryStatement:
+		end = s.TryBlockEnd
+		if s.FinallyBlock != nil {
+			end = s.FinallyBlockEnd
+		} else if s.CatchName != nil {
+			end = s.CatchBlockEnd
+		}
+	case *ast.FunctionDeclarationStatement:
+		end = s.BodyEnd
+	}
+
+	if end != nil {
+		return end.LineNumber()
+	}
+	_, line = lines(stmt)
+	return
+}
+
+// lines returns the first and last source lines of the nodes in the tree rooted at node
+func lines(node ast.Node) (first, last int) {
+	first = math.MaxInt32
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n != nil && n.LineNumber() < first {
+			first = n.LineNumber()
+		}
+		if n != nil && n.LineNumber() > last {
+			last = n.LineNumber()
+		}
+		return true
+	})
+	return
+}
+
+func (p *printer) printVarStatement(stmt ast.Statement) {
+	vStmt := stmt.(*ast.VarStatement)
+	p.printExport(vStmt.Exported)
+	p.buf.WriteString(fmt.Sprintf("var %v =%v;", annotate(vStmt.IdentifierNode.Name, vStmt.TypeAnnotation),
+		spaced(p.format(vStmt.Expression))))
+}
+
+func (p *printer) printAssignmentStatement(stmt ast.Statement) {
+	aStmt := stmt.(*ast.AssignmentStatement)
+	p.buf.WriteString(fmt.Sprintf("%v =%v;", aStmt.IdentifierNode.Name, spaced(p.format(aStmt.Expression))))
+}
+
+func (p *printer) printFieldAssignmentStatement(stmt ast.Statement) {
+	fStmt := stmt.(*ast.FieldAssignmentStatement)
+	p.buf.WriteString(fmt.Sprintf("%v =%v;", p.format(fStmt.Field), spaced(p.format(fStmt.Expression))))
+}
+
+func (p *printer) printReturnStatement(stmt ast.Statement) {
+	rS := stmt.(*ast.ReturnStatement)
+	if rS.Expression == nil {
+		p.buf.WriteString("return;")
+		return
+	}
+	p.buf.WriteString(fmt.Sprintf("return%v;", spaced(p.format(rS.Expression))))
+}
+
+func (p *printer) printFunctionCallStatement(stmt ast.Statement) {
+	p.buf.WriteString(p.format(stmt.(*ast.FunctionCallStatement).FunctionCallExpression) + ";")
+}
+
+func (p *printer) printImportStatement(stmt ast.Statement) {
+	p.buf.WriteString(stmt.String())
+}
+
+func (p *printer) printIfStatement(stmt ast.Statement) {
+	ifStmt := stmt.(*ast.IfStatement)
+	p.buf.WriteString(fmt.Sprintf("if (%v) ", p.format(ifStmt.Condition)))
+	_, open := lines(ifStmt.Condition)
+	p.printNestedBlock(ifStmt.IfBlock, open, ifStmt.IfBlockEnd)
+
+	if ifStmt.ElseBlock != nil {
+		// else follows the if block's closing brace
+		if ifStmt.IfBlockEnd != nil {
+			open = ifStmt.IfBlockEnd.LineNumber()
+		}
+		p.buf.WriteString(" else ")
+		p.printNestedBlock(ifStmt.ElseBlock, open, ifStmt.ElseBlockEnd)
+	}
+	p.buf.WriteString(";")
+}
+
+func (p *printer) printWhileStatement(stmt ast.Statement) {
+	wStmt := stmt.(*ast.WhileStatement)
+	p.buf.WriteString(fmt.Sprintf("while (%v) ", p.format(wStmt.Condition)))
+	_, open := lines(wStmt.Condition)
+	p.printNestedBlock(wStmt.Block, open, wStmt.BlockEnd)
+	p.buf.WriteString(";")
+}
+
+func (p *printer) printThrowStatement(stmt ast.Statement) {
+	p.buf.WriteString(fmt.Sprintf("throw%v;", spaced(p.format(stmt.(*ast.ThrowStatement).Expression))))
+}
+
+func (p *printer) printTryStatement(stmt ast.Statement) {
+	tStmt := stmt.(*ast.TryStatement)
+	p.buf.WriteString("try ")
+	p.printNestedBlock(tStmt.TryBlock, tStmt.LineNumber(), tStmt.TryBlockEnd)
+
+	// catch and finally follow the closing brace of the previous block
+	open, end := tStmt.LineNumber(), tStmt.TryBlockEnd
+	if tStmt.CatchName != nil {
+		if end != nil {
+			open = end.LineNumber()
+		}
+		p.buf.WriteString(fmt.Sprintf(" catch (%v) ", tStmt.CatchName.Name))
+		p.printNestedBlock(tStmt.CatchBlock, open, tStmt.CatchBlockEnd)
+		end = tStmt.CatchBlockEnd
+	}
+
+	if tStmt.FinallyBlock != nil {
+		if end != nil {
+			open = end.LineNumber()
+		}
+		p.buf.WriteString(" finally ")
+		p.printNestedBlock(tStmt.FinallyBlock, open, tStmt.FinallyBlockEnd)
+	}
+	p.buf.WriteString(";")
+}
+
+func (p *printer) printFunctionDeclarationStatement(stmt ast.Statement) {
+	fDec := stmt.(*ast.FunctionDeclarationStatement)
+
+	// the body opens on the line of the last parameter
+	open := fDec.LineNumber()
+	params := make([]string, len(fDec.Parameters))
+	for i, param := range fDec.Parameters {
+		params[i] = p.interject(param.LineNumber()) + annotate(param.Name, fDec.ParameterTypes[i])
+		p.line, open = param.LineNumber(), param.LineNumber()
+	}
+
+	p.printExport(fDec.Exported)
+	p.buf.WriteString(annotate(fmt.Sprintf("func %v(%v)", fDec.Name, join(params)), fDec.ReturnType))
+	p.buf.WriteString(" ")
+	p.printNestedBlock(fDec.Body, open, fDec.BodyEnd)
+	p.buf.WriteString(";")
+}
+
+// the fields of a struct are printed on one line
+func (p *printer) printStructDeclarationStatement(stmt ast.Statement) {
+	sDec := stmt.(*ast.StructDeclarationStatement)
+	if len(sDec.Fields) == 0 {
+		p.buf.WriteString(fmt.Sprintf("struct %v {};", sDec.Name))
+		return
+	}
+	p.buf.WriteString(fmt.Sprintf("struct %v { %v };", sDec.Name, strings.Join(sDec.FieldNames(), ", ")))
+}
+
+func (p *printer) printExport(exported bool) {
+	if exported {
+		p.buf.WriteString("export ")
+	}
+}
+
+func annotate(s string, ta *ast.TypeAnnotation) string {
+	if ta == nil {
+		return s
+	}
+	return fmt.Sprintf("%v: %v", s, ta.String())
+}
+
+func (p *printer) format(expr ast.Expression) string {
+	first, _ := lines(expr)
+	comments := p.interject(first)
+	p.line = first
+	return comments + p.expressionRouter[expr.Type()](expr)
+}
+
+func (p *printer) formatIdentifierExpression(expr ast.Expression) string {
+	return expr.String()
+}
+
+func (p *printer) formatIntegerExpression(expr ast.Expression) string {
+	return strconv.FormatInt(expr.(*ast.IntegerExpression).Value, 10)
+}
+
+// floats are printed in their shortest form, with at least one digit after the decimal point
+func (p *printer) formatFloatingPointExpression(expr ast.Expression) string {
+	f := strconv.FormatFloat(expr.(*ast.FloatingPointExpression).Value, 'f', -1, 64)
+	if !strings.Contains(f, ".") {
+		f += ".0"
+	}
+	return f
+}
+
+func (p *printer) formatStringExpression(expr ast.Expression) string {
+	return fmt.Sprintf("\"%v\"", ast.Escape(expr.(*ast.StringExpression).Literal))
+}
+
+func (p *printer) formatInterpolatedStringExpression(expr ast.Expression) string {
+	s := expr.(*ast.InterpolatedStringExpression)
+	sBuff := strings.Builder{}
+	sBuff.WriteString("\"")
+	for i, seg := range s.Segments {
+		sBuff.WriteString(ast.Escape(seg))
+		if i < len(s.Expressions) {
+			sBuff.WriteString(fmt.Sprintf("${%v}", p.format(s.Expressions[i])))
+		}
+	}
+	sBuff.WriteString("\"")
+	return sBuff.String()
+}
+
+func (p *printer) formatBooleanExpression(expr ast.Expression) string {
+	return expr.String()
+}
+
+func (p *printer) formatArrayExpression(expr ast.Expression) string {
+	return fmt.Sprintf("[%v]", p.formatList(expr.(*ast.ArrayExpression).Data))
+}
+
+func (p *printer) formatArrayIndexExpression(expr ast.Expression) string {
+	aIExpr := expr.(*ast.ArrayIndexExpression)
+	return fmt.Sprintf("%v[%v]", aIExpr.ArrayName, p.format(aIExpr.IndexExpr))
+}
+
+func (p *printer) formatStructExpression(expr ast.Expression) string {
+	sExpr := expr.(*ast.StructExpression)
+	fields := make([]string, len(sExpr.Fields))
+	for i, f := range sExpr.Fields {
+		fields[i] = fmt.Sprintf("%v:%v", f.Name, spaced(p.format(sExpr.Values[i])))
+	}
+	return fmt.Sprintf("%v{%v}", sExpr.Name, strings.Join(fields, ", "))
+}
+
+func (p *printer) formatFunctionCallExpression(expr ast.Expression) string {
+	fCall := expr.(*ast.FunctionCallExpression)
+	name := fCall.FunctionName
+	if fCall.Module != "" {
+		name = fCall.Module + "." + name
+	}
+	return fmt.Sprintf("%v(%v)", name, p.formatList(fCall.Parameters))
+}
+
+// the field binds tighter than any operator, so an operator's target must be parenthesised
+func (p *printer) formatFieldExpression(expr ast.Expression) string {
+	fExpr := expr.(*ast.FieldExpression)
+	target := p.format(fExpr.Target)
+	switch fExpr.Target.Type() {
+	case ast.InfixExpressionNode, ast.PrefixExpressionNode, ast.SafeCallExpressionNode:
+		target = fmt.Sprintf("(%v)", target)
+	}
+	return target + "." + fExpr.Field
+}
+
+func (p *printer) formatList(exprs []ast.Expression) string {
+	formatted := make([]string, len(exprs))
+	for i, e := range exprs {
+		formatted[i] = p.format(e)
+	}
+	return join(formatted)
+}
+
+// join separates elements with commas, keeping any comments that start them
+func join(elems []string) string {
+	sBuff := strings.Builder{}
+	for i, e := range elems {
+		if i > 0 {
+			e = "," + spaced(e)
+		}
+		sBuff.WriteString(e)
+	}
+	return sBuff.String()
+}
+
+func (p *printer) formatPrefixExpression(expr ast.Expression) string {
+	pExpr := expr.(*ast.PrefixExpression)
+	operand := p.format(pExpr.Expression)
+	if pExpr.Expression.Type() == ast.InfixExpressionNode {
+		operand = fmt.Sprintf("(%v)", operand)
+	}
+	return pExpr.Literal() + operand
+}
+
+func (p *printer) formatSafeCallExpression(expr ast.Expression) string {
+	return "try" + spaced(p.format(expr.(*ast.SafeCallExpression).Call))
+}
+
+// operators are left associative, so a right operand of equal precedence must be parenthesised
+func (p *printer) formatInfixExpression(expr ast.Expression) string {
+	iExpr := expr.(*ast.InfixExpression)
+	prec, _ := parser.Precedence(iExpr.Token.Type())
+
+	left := p.format(iExpr.LeftExpression)
+	if l, ok := infixPrecedence(iExpr.LeftExpression); ok && l < prec {
+		left = fmt.Sprintf("(%v)", left)
+	}
+
+	right := p.format(iExpr.RightExpression)
+	if r, ok := infixPrecedence(iExpr.RightExpression); ok && r <= prec {
+		right = fmt.Sprintf("(%v)", right)
+	}
+
+	return fmt.Sprintf("%v %v%v", left, iExpr.Literal(), spaced(right))
+}
+
+func infixPrecedence(expr ast.Expression) (int, bool) {
+	if iExpr, ok := expr.(*ast.InfixExpression); ok {
+		return parser.Precedence(iExpr.Token.Type())
+	}
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package format
+
+import (
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"fmt"
+	"github.com/spf13/afero"
+	"path/filepath"
+	"testing"
+)
+
+type formatTestCase struct {
+	input    string
+	expected string
+}
+
+func TestFormat(t *testing.T) {
+	tCs := []formatTestCase{
+		{
+			"var x=[1,2,  3];",
+			"var x = [1, 2, 3];\n",
+		},
+		{
+			"var y = (1 + 2) * 3 - (4 - 5) + (6 * 7); var z = -(y + 1) + !(true | false) + --y;",
+			"var y = (1 + 2) * 3 - (4 - 5) + 6 * 7;\nvar z = -(y + 1) + !(true | false) + --y;\n",
+		},
+		{
+			"var a = 1 < 2 == (3 < 4); var b = 1 - (2 + 3); var c = (1 - 2) + 3; var d = 2.50 + 3.0 + 1.25;",
+			"var a = 1 < 2 == 3 < 4;\nvar b = 1 - (2 + 3);\nvar c = 1 - 2 + 3;\nvar d = 2.5 + 3.0 + 1.25;\n",
+		},
+		{
+			// declaration order is kept
+			"var x = 1;\nfunc f(a, b: int): [int] { return [a,b]; };\nprint(f(x, 2));",
+			"var x = 1;\nfunc f(a, b: int): [int] {\n    return [a, b];\n};\nprint(f(x, 2));\n",
+		},
+		{
+			"var x = 0; if (x == 0) { x = 1; } else { if (true) { x = 2; }; }; while (x < 3) { x = x + 1; };",
+			"var x = 0;\nif (x == 0) {\n    x = 1;\n} else {\n    if (true) {\n        x = 2;\n    };\n};\n" +
+				"while (x < 3) {\n    x = x + 1;\n};\n",
+		},
+		{
+			"func f() { return; };\nf();\n\n\n\nvar s = \"a  // b\";",
+			"func f() {\n    return;\n};\nf();\n\nvar s = \"a  // b\";\n",
+		},
+		{
+			"// leading\n\n// another\nvar x = 1; // trailing\nvar y = 2; var z = 3; // after z\n// final\n",
+			"// leading\n\n// another\nvar x = 1; // trailing\nvar y = 2;\nvar z = 3; // after z\n// final\n",
+		},
+		{
+			"func f(a) { // header\n  // body\n  return a;\n  // end\n}; // after\nif (true) { print(1); }; // closing\n" +
+				"if (true) {\n} else { // else\n};",
+			"func f(a) { // header\n    // body\n    return a;\n    // end\n}; // after\nif (true) {\n    print(1);\n}; " +
+				"// closing\nif (true) {\n} else { // else\n};\n",
+		},
+		{
+			"import \"lib/math\" as m;\nexport   var x=m.pi; export func f(a) {return m.square(a);};",
+			"import \"lib/math\" as m;\nexport var x = m.pi;\nexport func f(a) {\n    return m.square(a);\n};\n",
+		},
+		{
+			"var s = \"tab\\t\\\"q\\\" ${ 1 } \\\\\";",
+			"var s = \"tab\\t\\\"q\\\" ${1} \\\\\";\n",
+		},
+		{
+			"var s = \"total:  ${ (1+2)*3 } and ${ \"${[1,2]}\" }\";",
+			"var s = \"total:  ${(1 + 2) * 3} and ${\"${[1, 2]}\"}\";\n",
+		},
+		{
+			"try { f(); } catch (e) { throw   e; } finally {\n// done\n};\ntry {} finally { print(1); };",
+			"try {\n    f();\n} catch (e) {\n    throw e;\n} finally {\n    // done\n};\ntry {\n} finally {\n    print(1);\n};\n",
+		},
+		{
+			"var r=try  f( 1 )+1;",
+			"var r = try f(1) + 1;\n",
+		},
+		{
+			"struct Point{x,\ny};struct Empty{ };\nvar p=Point{ x:1+2,y : 3 };p.x=p.y*2;",
+			"struct Point { x, y };\nstruct Empty {};\nvar p = Point{x: 1 + 2, y: 3};\np.x = p.y * 2;\n",
+		},
+		{
+			"ps[ 0 ].x=( try f() ).a.x+m.pi;",
+			"ps[0].x = (try f()).a.x + m.pi;\n",
+		},
+		{
+			// comments within a statement stay after the node they follow
+			"var x = 1 + // base\n  2 * 3;\nprint(f(1,\n// second\n2));",
+			"var x = 1 + // base\n    2 * 3;\nprint(f(1,\n    // second\n    2));\n",
+		},
+		{
+			"func f(a, // first\n  b) {\n  return a;\n};\nfunc g(\n  // only\n  a) {\n  return a;\n};",
+			"func f(a, // first\n    b) {\n    return a;\n};\nfunc g(\n    // only\n    a) {\n    return a;\n};\n",
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+
+	for i, tC := range tCs {
+		fp := fmt.Sprintf("test_files/format/test_%v.txt", i)
+		if err := afero.WriteFile(fs, fp, []byte(tC.input), 0644); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		formatted, errs := Source(fs, fp)
+		if len(errs) != 0 {
+			t.Fatalf("test case %v: %v", i+1, errs)
+		}
+
+		if string(formatted) != tC.expected {
+			t.Errorf("test case %v: expected\n%v\ngot\n%v", i+1, tC.expected, string(formatted))
+		}
+	}
+}
+
+// formatting the examples must be idempotent, and must not change the parsed program
+func TestFormatExamples(t *testing.T) {
+	var (
+		osFs  = afero.NewOsFs()
+		memFs = afero.NewMemMapFs()
+	)
+
+	examples, err := afero.Glob(osFs, filepath.Join("..", "examples", "*"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(examples) == 0 {
+		t.Fatalf("no examples found")
+	}
+
+	for _, fp := range examples {
+		formatted, errs := Source(osFs, fp)
+		if len(errs) != 0 {
+			t.Fatalf("%v: %v", fp, errs)
+		}
+
+		formattedFp := filepath.Join("test_files", "format", filepath.Base(fp))
+		if err = afero.WriteFile(memFs, formattedFp, formatted, 0644); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		reformatted, errs := Source(memFs, formattedFp)
+		if len(errs) != 0 {
+			t.Fatalf("%v: formatted source does not parse: %v", fp, errs)
+		}
+
+		if string(reformatted) != string(formatted) {
+			t.Errorf("%v: formatting is not idempotent, got\n%v\nthen\n%v", fp, string(formatted), string(reformatted))
+		}
+
+		if testutil.ParseFile(t, osFs, fp).String() != testutil.ParseFile(t, memFs, formattedFp).String() {
+			t.Errorf("%v: formatting changed the program", fp)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if d := Diff("same", []byte("a\nb\n"), []byte("a\nb\n")); d != nil {
+		t.Errorf("expected no diff for equal sources, got %q", d)
+	}
+
+	a := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	b := []byte("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n")
+	expected := "--- f.orig\n+++ f\n" +
+		"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"
+
+	if d := string(Diff("f", a, b)); d != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, d)
+	}
+}
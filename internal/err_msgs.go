@@ -9,6 +9,12 @@ const (
 	ErrInitParser            = "unable to initialise parser"
 	ErrInvalidStatement      = "invalid statement beginning with %v"
 	ErrEndOfFile             = "unexpected EOF at line %v"
+	ErrInvalidImportPath     = "import path must be a string, received %v"
+	ErrInvalidQualified      = "can not call %v.%v, only the name of an imported module qualifies a function call"
+	ErrInvalidExport         = "only function and variable declarations can be exported, received %v"
+	ErrInvalidTry            = "try requires a catch or finally block"
+	ErrInvalidSafeCall       = "try must be followed by a function call, received %v"
+	ErrInvalidEscape         = "%v is not a valid escape sequence"
 
 	// semantic errors
 	ErrDeclaredVariable              = "%v already declared in current scope"
@@ -18,6 +24,35 @@ const (
 	ErrInvalidFunctionCallParameters = "%v requires %v parameters, %v given"
 	ErrUndeclaredIdentifierNode      = "%v not declared"
 	ErrInvalidIndexType              = "%v is not a valid index"
+	ErrMissingCapability             = "%v requires the %v capability, which has not been granted"
+	ErrUnknownType                   = "%v is not a type"
+	ErrAnnotatedType                 = "cannot use %v as %v of type %v"
+	ErrMissingReturn                 = "%v does not return a value on every path, declared to return %v"
+	ErrImportCycle                   = "import cycle %v"
+	ErrImportNotFound                = "unable to import %v | %v"
+	ErrImportLocation                = "imports must be at the top level of a file"
+	ErrDeclaredImport                = "%v already imported"
+	ErrInvalidImportName             = "%v is not a valid module name, import it as another name"
+	ErrUndeclaredModule              = "%v is not an imported module"
+	ErrUnresolvedImport              = "import of %v has not been resolved"
+	ErrExportLocation                = "exports must be at the top level of a file"
+	ErrUnexported                    = "%v is not exported by %v"
+	ErrDeclaredField                 = "field %v of %v already declared"
+	ErrRepeatedField                 = "field %v of %v set more than once"
+	ErrStructLocation                = "structs must be declared at the top level of a file"
+	ErrUndeclaredField               = "%v has no field %v"
+	ErrAssignImported                = "%v is declared in module %v and can not be assigned"
+	ErrImportedName                  = "%v is the name of an imported module and can not be declared as a variable"
+
+	// semantic warnings
+	ErrUnusedVariable     = "%v declared but not used"
+	ErrUnusedParameter    = "parameter %v of %v is not used"
+	ErrUnusedFunction     = "%v declared but never called"
+	ErrShadowedVariable   = "%v shadows the variable declared on line %v"
+	ErrUnreadAssignment   = "value assigned to %v is never read"
+	ErrSelfComparison     = "%v compares a value with itself"
+	ErrUnreachableCode    = "unreachable code"
+	ErrInconsistentReturn = "%v returns a value on some paths but not on others"
 
 	// runtime errors
 	ErrDivisionByZero   = "division by zero"
@@ -25,6 +60,15 @@ const (
 	ErrTypeOperation    = "operation %v not available for type %v"
 	ErrIndexOutOfBounds = "index out of bounds"
 	ErrConditionType    = "condition does not evaluate to a boolean"
+	ErrFileOperation    = "%v failed | %v"
+	ErrExit             = "exit status %v"
+	ErrInvalidJSON      = "invalid json | %v"
+	ErrJSONType         = "%v can not be represented as json"
+	ErrJSONCycle        = "%v can not be represented as json, as it contains itself"
+	ErrFormatMissing    = "a format string is required"
+	ErrFormatArgs       = "format requires %v arguments, %v given"
+	ErrFormatVerb       = "%v is not a valid format verb"
+	ErrFormatType       = "%v can not format %v, which is not of type %v"
 
 	// internal error
 	ErrUnimplementedType = "unable to evaluate type %v"
@@ -45,7 +89,42 @@ const (
 	ErrUnexpectedRuntimeError                 = "test case %v | unexpected %v"
 
 	// executing errors
-	ErrFileNotProvided = "txt file required as argument"
-	ErrFileNotFound    = "%v not found"
-	ErrLoadFile        = "unable to load %v | %v"
+	ErrFileNotProvided   = "txt file required as argument"
+	ErrFileNotFound      = "%v not found"
+	ErrLoadFile          = "unable to load %v | %v"
+	ErrUnknownCapability = "unknown capability %v"
+	ErrUnknownFormat     = "unknown output format %v, expected text or json"
+
+	// language server errors
+	ErrInvalidHeader        = "invalid header %q"
+	ErrMissingContentLength = "message has no Content-Length header"
+	ErrUnknownMethod        = "unknown method %v"
+	ErrUnknownDocument      = "%v is not open"
+	ErrNoSymbol             = "no symbol at %v:%v"
+	ErrInvalidRename        = "%v is not a valid identifier"
+	ErrRenameNative         = "native function %v can not be renamed"
+	ErrRenameConflict       = "%v is already declared"
+
+	// debugger errors
+	ErrUnknownCommand    = "unknown command %v, type help for a list of commands"
+	ErrInvalidBreakpoint = "invalid breakpoint %q, expected [file:]line"
+	ErrNoBreakpoint      = "no breakpoint at %v"
+	ErrInvalidFrame      = "no frame %v"
+	ErrOutermostFrame    = "not in a function call"
+	ErrLaunched          = "a program has already been launched"
+	ErrNotPaused         = "the program is not paused"
+	ErrNoStatement       = "no statement on line %v"
+	ErrInvalidReference  = "unknown variables reference %v"
+	ErrInvalidExpression = "%v is not an expression"
+
+	// coverage errors
+	ErrInvalidCoverage      = "invalid coverage profile on line %v"
+	ErrInconsistentCoverage = "coverage profiles disagree on %v, were they recorded from the same source?"
+	ErrNoCoverageProfiles   = "at least one coverage profile is required"
+
+	// test errors
+	ErrAssertionFailed   = "assertion failed"
+	ErrAssertEqualFailed = "expected %v : %v, got %v : %v"
+	ErrInvalidTestFilter = "invalid -run pattern | %v"
+	ErrNoTestFiles       = "no test files"
 )
@@ -5,29 +5,61 @@ import (
 	"fmt"
 )
 
+// ErrorType is the kind of an error, naming the stage of the program that raised it
 type ErrorType string
 
 const (
-	SyntaxErr   ErrorType = "syntax error"
-	RuntimeErr  ErrorType = "runtime error"
-	SemanticErr ErrorType = "semantic error"
-	InternalErr ErrorType = "internal error"
+	SyntaxErr   ErrorType = "syntax"
+	RuntimeErr  ErrorType = "runtime"
+	SemanticErr ErrorType = "semantic"
+	InternalErr ErrorType = "internal"
+	ThrownErr   ErrorType = "thrown" // raised by a program's throw statement
+	TestErr     ErrorType = "test"   // raised by a failed test assertion, which is not caught by a try statement
+)
+
+// reads as "syntax error", "runtime error" and so on
+func (t ErrorType) String() string {
+	return string(t) + " " + string(ErrorSeverity)
+}
+
+// Severity distinguishes fatal errors from diagnostics that do not prevent a program from running
+type Severity string
+
+const (
+	ErrorSeverity   Severity = "error"
+	WarningSeverity Severity = "warning"
+	InfoSeverity    Severity = "info"
 )
 
 type Error struct {
 	token.Metadata
-	msg  string
-	code ErrorType
+	msg      string
+	code     ErrorType
+	severity Severity
 }
 
 func NewError(md token.Metadata, msg string, code ErrorType) *Error {
-	return &Error{md, msg, code}
+	return &Error{md, msg, code, ErrorSeverity}
 }
 
+func NewDiagnostic(md token.Metadata, msg string, code ErrorType, severity Severity) *Error {
+	return &Error{md, msg, code, severity}
+}
+
+// reads as "semantic error", "semantic warning" or "semantic info"
 func (e *Error) Error() string {
-	return fmt.Sprintf("%v %v %v | %v", e.Type(), e.Metadata.FileName(), e.Metadata.LineNumber(), e.msg)
+	label := string(e.Type()) + " " + string(e.Severity())
+	return fmt.Sprintf("%v %v %v | %v", label, e.Metadata.FileName(), e.Metadata.LineNumber(), e.msg)
 }
 
 func (e *Error) Type() ErrorType {
 	return e.code
 }
+
+func (e *Error) Severity() Severity {
+	return e.severity
+}
+
+func (e *Error) Message() string {
+	return e.msg
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const contentLengthHeader = "Content-Length"
+
+// ReadMessage reads a single message, framed by a header block containing its Content-Length, as used by the
+// language server and debug adapter protocols
+func ReadMessage(r *bufio.Reader) (msg []byte, err error) {
+	length := -1
+
+	for {
+		var line string
+		if line, err = r.ReadString('\n'); err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break // end of headers
+		}
+
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf(ErrInvalidHeader, line)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(line[:sep]), contentLengthHeader) {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[sep+1:])); err != nil {
+				return nil, fmt.Errorf(ErrInvalidHeader, line)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf(ErrMissingContentLength)
+	}
+
+	msg = make([]byte, length)
+	_, err = io.ReadFull(r, msg)
+	return
+}
+
+// WriteMessage encodes v as JSON and writes it with a Content-Length header
+func WriteMessage(w io.Writer, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(w, "%v: %v\r\n\r\n", contentLengthHeader, len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 type StackTrace interface {
 	Push(*ast.FunctionCallExpression)
 	Pop() (*ast.FunctionCallExpression, bool)
+	Calls() []*ast.FunctionCallExpression
 }
 
 type stackTrace []*ast.FunctionCallExpression
@@ -29,3 +30,8 @@ func (st *stackTrace) Pop() (fc *ast.FunctionCallExpression, ok bool) {
 	}
 	return
 }
+
+// Calls returns a copy of the trace, outermost call first
+func (st *stackTrace) Calls() []*ast.FunctionCallExpression {
+	return append([]*ast.FunctionCallExpression{}, *st...)
+}
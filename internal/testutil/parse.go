@@ -0,0 +1,47 @@
+// Package testutil holds helpers shared by the tests of the interpreter's packages
+package testutil
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+// ParseFile parses the program in the file fp of fs, failing the test if it can not be parsed
+func ParseFile(t testing.TB, fs afero.Fs, fp string) *ast.Program {
+	t.Helper()
+
+	f, err := fs.Open(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		t.Fatalf("%v | invalid test case, syntax errors occurred: %v", fp, errs)
+	}
+	return prog
+}
+
+// Parse writes input to the file fp of fs, and parses it as by ParseFile
+func Parse(t testing.TB, fs afero.Fs, fp string, input []byte) *ast.Program {
+	t.Helper()
+
+	if err := afero.WriteFile(fs, fp, input, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ParseFile(t, fs, fp)
+}
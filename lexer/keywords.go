@@ -3,14 +3,21 @@ package lexer
 import "github.com/EricNRodriguez/yum/token"
 
 var keywords = map[string]token.TokenType{
-	"func":   token.FuncToken,
-	"var":    token.VarToken,
-	"if":     token.IfToken,
-	"else":   token.ElseToken,
-	"return": token.ReturnToken,
-	"true":   token.BooleanToken,
-	"false":  token.BooleanToken,
-	"while":  token.WhileToken,
+	"func":    token.FuncToken,
+	"var":     token.VarToken,
+	"if":      token.IfToken,
+	"else":    token.ElseToken,
+	"return":  token.ReturnToken,
+	"true":    token.BooleanToken,
+	"false":   token.BooleanToken,
+	"while":   token.WhileToken,
+	"import":  token.ImportToken,
+	"export":  token.ExportToken,
+	"throw":   token.ThrowToken,
+	"try":     token.TryToken,
+	"catch":   token.CatchToken,
+	"finally": token.FinallyToken,
+	"struct":  token.StructToken,
 }
 
 func classifyTokenLiteral(s string) (t token.TokenType) {
@@ -20,3 +27,21 @@ func classifyTokenLiteral(s string) (t token.TokenType) {
 	}
 	return
 }
+
+// IsIdentifier reports whether s would be lexed as a single identifier, rather than a keyword or literal
+func IsIdentifier(s string) bool {
+	if s == "" || classifyTokenLiteral(s) != token.IdentifierToken {
+		return false
+	}
+
+	l := &lexer{}
+	if !l.validVariableNameStartCharacter(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !l.validVariableNameCharacter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
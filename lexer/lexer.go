@@ -23,6 +23,7 @@ type lexer struct {
 	currentLineIndex  int
 	fileName          string
 	ignoreSpace       bool
+	interpolations    []int // braces opened within each interpolation being lexed, innermost last
 }
 
 func NewLexer(f afero.File) (l Lexer, err error) {
@@ -139,20 +140,47 @@ func (l *lexer) NextToken() (t token.Token, err error) {
 		l.currentLineIndex++
 
 		if s == " " && l.currentLineIndex == len(l.currentLine) {
-			// begin parsing new line
-			l.currentLineIndex = 0
+			// the rest of the line is white space, begin parsing the next line
 			return l.NextToken()
 		}
 
 	}
 
+	// within a string, ${ begins an embedded expression, which is lexed as code until its closing brace
+	if !l.ignoreSpace && s == "$" && l.currentLineIndex < len(l.currentLine) &&
+		l.currentLine[l.currentLineIndex] == '{' {
+		l.currentLineIndex++
+		l.interpolations = append(l.interpolations, 0)
+		l.ignoreSpace = true
+		t = token.NewToken(token.InterpolationStartToken, "${", l.currentLineNumber, l.fileName)
+		return
+	}
+
+	// within a string, \ escapes the character that follows it, which is kept in the token's literal
+	if !l.ignoreSpace && s == "\\" && l.currentLineIndex < len(l.currentLine) {
+		s += string(l.currentLine[l.currentLineIndex])
+		l.currentLineIndex++
+		t = token.NewToken(token.EscapeToken, s, l.currentLineNumber, l.fileName)
+		return
+	}
+
 	switch token.TokenType(s) {
 	case token.AddToken:
 		t = token.NewToken(token.AddToken, s, l.currentLineNumber, l.fileName)
 	case token.SubToken:
 		t = token.NewToken(token.SubToken, s, l.currentLineNumber, l.fileName)
 	case token.DivToken:
-		t = token.NewToken(token.DivToken, s, l.currentLineNumber, l.fileName)
+		tt, _ := l.trailingTerminal()
+		switch {
+		case tt == token.DivToken && l.ignoreSpace:
+			t = token.NewToken(token.CommentToken, s+string(l.currentLine[l.currentLineIndex-1:]),
+				l.currentLineNumber, l.fileName)
+			l.currentLineIndex = len(l.currentLine)
+		default:
+			// shift back, unread trailing terminal
+			l.currentLineIndex--
+			t = token.NewToken(token.DivToken, s, l.currentLineNumber, l.fileName)
+		}
 	case token.MultToken:
 		t = token.NewToken(token.MultToken, s, l.currentLineNumber, l.fileName)
 	case token.AssignToken:
@@ -200,6 +228,10 @@ func (l *lexer) NextToken() (t token.Token, err error) {
 		t = token.NewToken(token.SemicolonToken, s, l.currentLineNumber, l.fileName)
 	case token.CommaToken:
 		t = token.NewToken(token.CommaToken, s, l.currentLineNumber, l.fileName)
+	case token.ColonToken:
+		t = token.NewToken(token.ColonToken, s, l.currentLineNumber, l.fileName)
+	case token.DotToken:
+		t = token.NewToken(token.DotToken, s, l.currentLineNumber, l.fileName)
 	case token.QuotationMarkToken:
 		t = token.NewToken(token.QuotationMarkToken, s, l.currentLineNumber, l.fileName)
 		l.ignoreSpace = !l.ignoreSpace // allow strings to have white spaces
@@ -209,8 +241,21 @@ func (l *lexer) NextToken() (t token.Token, err error) {
 		t = token.NewToken(token.RightParenToken, s, l.currentLineNumber, l.fileName)
 	case token.LeftBraceToken:
 		t = token.NewToken(token.LeftBraceToken, s, l.currentLineNumber, l.fileName)
+		if n := len(l.interpolations); n > 0 && l.ignoreSpace {
+			l.interpolations[n-1]++
+		}
 	case token.RightBraceToken:
 		t = token.NewToken(token.RightBraceToken, s, l.currentLineNumber, l.fileName)
+		if n := len(l.interpolations); n > 0 && l.ignoreSpace {
+			if l.interpolations[n-1] == 0 {
+				// the end of an embedded expression, the string continues
+				l.interpolations = l.interpolations[:n-1]
+				l.ignoreSpace = false
+				t = token.NewToken(token.InterpolationEndToken, s, l.currentLineNumber, l.fileName)
+			} else {
+				l.interpolations[n-1]--
+			}
+		}
 	case token.LeftBracketToken:
 		t = token.NewToken(token.LeftBracketToken, s, l.currentLineNumber, l.fileName)
 	case token.RightBracketToken:
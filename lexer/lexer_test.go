@@ -21,6 +21,13 @@ func TestLexer(t *testing.T) {
 				token.IntegerToken, token.SemicolonToken},
 			[]string{"var", "x", "=", "22", ";"},
 		},
+		{
+			[]byte("var x = 22;  \nvar y = 3;"), // trailing white space
+			[]token.TokenType{token.VarToken, token.IdentifierToken, token.AssignToken,
+				token.IntegerToken, token.SemicolonToken, token.VarToken, token.IdentifierToken, token.AssignToken,
+				token.IntegerToken, token.SemicolonToken},
+			[]string{"var", "x", "=", "22", ";", "var", "y", "=", "3", ";"},
+		},
 		{
 			[]byte("var u = (a + b) / 22;"),
 			[]token.TokenType{token.VarToken, token.IdentifierToken, token.AssignToken,
@@ -112,6 +119,62 @@ x = x+1;
 			[]string{"while", "(", "a", "<", "b", "&", "a", "<=", "b", "&", "a", ">", "b", "&", "a",
 				">=", "b", ")", "{", "x", "=", "x", "+", "1", ";", "}", ";"},
 		},
+		{
+			[]byte(`// comment / with * symbols
+x = a / b; // trailing
+print("//");`),
+			[]token.TokenType{token.CommentToken, token.IdentifierToken, token.AssignToken, token.IdentifierToken,
+				token.DivToken, token.IdentifierToken, token.SemicolonToken, token.CommentToken, token.IdentifierToken,
+				token.LeftParenToken, token.QuotationMarkToken, token.DivToken, token.DivToken, token.QuotationMarkToken,
+				token.RightParenToken, token.SemicolonToken},
+			[]string{"// comment / with * symbols", "x", "=", "a", "/", "b", ";", "// trailing", "print", "(", "\"",
+				"/", "/", "\"", ")", ";"},
+		},
+		{
+			[]byte(`import "lib/math.yum" as m;
+print(m.pi);`),
+			[]token.TokenType{token.ImportToken, token.QuotationMarkToken, token.IdentifierToken, token.DivToken,
+				token.IdentifierToken, token.DotToken, token.IdentifierToken, token.QuotationMarkToken,
+				token.IdentifierToken, token.IdentifierToken, token.SemicolonToken, token.IdentifierToken,
+				token.LeftParenToken, token.IdentifierToken, token.DotToken, token.IdentifierToken,
+				token.RightParenToken, token.SemicolonToken},
+			[]string{"import", "\"", "lib", "/", "math", ".", "yum", "\"", "as", "m", ";", "print", "(", "m", ".", "pi",
+				")", ";"},
+		},
+		{
+			[]byte(`"a ${f({1}) + "${b}"} $c{}"`),
+			[]token.TokenType{token.QuotationMarkToken, token.IdentifierToken, token.IllegalToken,
+				token.InterpolationStartToken, token.IdentifierToken, token.LeftParenToken, token.LeftBraceToken,
+				token.IntegerToken, token.RightBraceToken, token.RightParenToken, token.AddToken,
+				token.QuotationMarkToken, token.InterpolationStartToken, token.IdentifierToken,
+				token.InterpolationEndToken, token.QuotationMarkToken, token.InterpolationEndToken, token.IllegalToken,
+				token.IllegalToken, token.IdentifierToken, token.LeftBraceToken, token.RightBraceToken,
+				token.QuotationMarkToken},
+			[]string{"\"", "a", " ", "${", "f", "(", "{", "1", "}", ")", "+", "\"", "${", "b", "}", "\"", "}", " ", "$", "c",
+				"{", "}", "\""},
+		},
+		{
+			[]byte(`"a\"b\n" \`),
+			[]token.TokenType{token.QuotationMarkToken, token.IdentifierToken, token.EscapeToken,
+				token.IdentifierToken, token.EscapeToken, token.QuotationMarkToken, token.IllegalToken},
+			[]string{"\"", "a", "\\\"", "b", "\\n", "\"", "\\"},
+		},
+		{
+			[]byte("try {} catch (e) { throw e; } finally {};"),
+			[]token.TokenType{token.TryToken, token.LeftBraceToken, token.RightBraceToken, token.CatchToken,
+				token.LeftParenToken, token.IdentifierToken, token.RightParenToken, token.LeftBraceToken, token.ThrowToken,
+				token.IdentifierToken, token.SemicolonToken, token.RightBraceToken, token.FinallyToken, token.LeftBraceToken,
+				token.RightBraceToken, token.SemicolonToken},
+			[]string{"try", "{", "}", "catch", "(", "e", ")", "{", "throw", "e", ";", "}", "finally", "{", "}", ";"},
+		},
+		{
+			[]byte("struct P { x }; p.x = P{x: 1};"),
+			[]token.TokenType{token.StructToken, token.IdentifierToken, token.LeftBraceToken, token.IdentifierToken,
+				token.RightBraceToken, token.SemicolonToken, token.IdentifierToken, token.DotToken, token.IdentifierToken,
+				token.AssignToken, token.IdentifierToken, token.LeftBraceToken, token.IdentifierToken, token.ColonToken,
+				token.IntegerToken, token.RightBraceToken, token.SemicolonToken},
+			[]string{"struct", "P", "{", "x", "}", ";", "p", ".", "x", "=", "P", "{", "x", ":", "1", "}", ";"},
+		},
 	}
 
 	var (
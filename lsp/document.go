@@ -0,0 +1,315 @@
+package lsp
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/parser"
+	"github.com/EricNRodriguez/yum/semantic"
+	"github.com/EricNRodriguez/yum/test"
+	"fmt"
+	"github.com/spf13/afero"
+	"math"
+	"strings"
+)
+
+const diagnosticSource = "yum"
+
+type symbolKind int
+
+const (
+	variableSymbol symbolKind = iota
+	parameterSymbol
+	functionSymbol
+	nativeSymbol
+)
+
+// symbol is a declared variable, parameter or function, along with every reference to it
+type symbol struct {
+	name       string
+	kind       symbolKind
+	decl       *occurrence // nil for native functions
+	annotation *ast.TypeAnnotation
+	fDec       *ast.FunctionDeclarationStatement // functions only
+	native     *object.NativeFunction            // native functions only
+	end        int                               // last line of a function's body
+	refs       []*occurrence                     // including the declaration
+	locals     []*symbol                         // parameters and variables of a function
+}
+
+func (s *symbol) Type() object.ObjectType {
+	return symbolObject
+}
+
+func (s *symbol) Literal() string {
+	return s.name
+}
+
+// occurrence is the position of an identifier in the source
+type occurrence struct {
+	rng Range
+	sym *symbol // nil for unresolved identifiers
+}
+
+// scope is a block of the program, spanning the lines start to end, and the variables declared in it. Names are
+// resolved by the indexer's symbol table, scopes are kept to find the variables visible at a line. Function bodies
+// have their own namespace, so have no parent
+type scope struct {
+	parent     *scope
+	start, end int
+	vars       map[string]*symbol
+	children   []*scope
+}
+
+// document is an open source file, analysed each time it changes
+type document struct {
+	uri         string
+	lines       []string
+	diagnostics []Diagnostic
+	occurrences []*occurrence      // in source order
+	symbols     []*symbol          // top level declarations, in source order
+	functions   map[string]*symbol // user and native functions
+	global      *scope
+}
+
+func newDocument(uri, text string) (d *document) {
+	d = &document{
+		uri:         uri,
+		lines:       strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n"),
+		diagnostics: make([]Diagnostic, 0),
+		occurrences: make([]*occurrence, 0),
+		symbols:     make([]*symbol, 0),
+		functions:   make(map[string]*symbol),
+		global:      newScope(nil, 0, math.MaxInt32),
+	}
+
+	for name, f := range d.nativeFunctions() {
+		d.functions[name] = &symbol{
+			name:   name,
+			kind:   nativeSymbol,
+			native: f,
+		}
+	}
+
+	d.analyse(strings.Join(d.lines, "\n"))
+	return
+}
+
+func (d *document) analyse(text string) {
+	var (
+		// the document may be unsaved, but the modules it imports are read from disk
+		fs   = afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(afero.NewOsFs()), afero.NewMemMapFs())
+		fp   = d.path()
+		f    afero.File
+		l    lexer.Lexer
+		p    parser.Parser
+		prog *ast.Program
+		err  error
+		errs []error
+	)
+
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	if err = afero.WriteFile(fs, fp, []byte(text), 0644); err != nil {
+		d.addDiagnostic(err)
+		return
+	}
+
+	if f, err = fs.Open(fp); err != nil {
+		d.addDiagnostic(err)
+		return
+	}
+
+	if l, err = lexer.NewLexer(f); err != nil {
+		d.addDiagnostic(err)
+		return
+	}
+	defer l.Close()
+
+	// statements are indexed in source order, so that identifiers can be matched to their columns
+	if p, err = parser.NewRecursiveDescentParser(l, parser.WithoutHoisting()); err != nil {
+		d.addDiagnostic(err)
+		return
+	}
+
+	prog, errs = p.Parse()
+	for _, e := range errs {
+		d.addDiagnostic(e)
+	}
+
+	newIndexer(d).index(prog)
+
+	if len(errs) != 0 {
+		return
+	}
+
+	prog.Hoist()
+	if errs = module.Resolve(fs, prog); len(errs) != 0 {
+		for _, e := range errs {
+			d.addDiagnostic(e)
+		}
+		return
+	}
+
+	sA := semantic.NewSemanticAnalyser(semantic.WithNativeFunctions(d.nativeFunctions()))
+	for _, e := range sA.Analyse(prog) {
+		d.addDiagnostic(e)
+	}
+	for _, w := range sA.Warnings() {
+		d.addDiagnostic(w)
+	}
+}
+
+// nativeFunctions returns the native functions available to the document, test files may also use assertions
+func (d *document) nativeFunctions() map[string]*object.NativeFunction {
+	if !strings.HasSuffix(d.uri, test.FileSuffix) {
+		return object.NativeFunctions
+	}
+
+	fs := make(map[string]*object.NativeFunction)
+	for name, f := range object.NativeFunctions {
+		fs[name] = f
+	}
+	for name, f := range object.TestFunctions {
+		fs[name] = f
+	}
+	return fs
+}
+
+// path is the file the document is analysed as
+func (d *document) path() string {
+	return strings.TrimPrefix(d.uri, "file://")
+}
+
+// addDiagnostic reports err against the whole of the line it occurred on
+func (d *document) addDiagnostic(err error) {
+	var (
+		line     = 0
+		severity = errorSeverity
+		msg      = err.Error()
+	)
+
+	if e, ok := err.(*internal.Error); ok {
+		// errors in imported modules are reported at the top of the document, with their own file and line
+		if e.FileName() == d.path() {
+			line = e.LineNumber() - 1
+			msg = e.Message()
+		}
+		switch e.Severity() {
+		case internal.WarningSeverity:
+			severity = warningSeverity
+		case internal.InfoSeverity:
+			severity = informationSeverity
+		}
+	}
+
+	if line >= len(d.lines) {
+		line = len(d.lines) - 1
+	}
+	if line < 0 {
+		line = 0
+	}
+
+	text := d.lines[line]
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range: Range{
+			Start: Position{Line: line, Character: len(text) - len(strings.TrimLeft(text, " \t"))},
+			End:   Position{Line: line, Character: len(text)},
+		},
+		Severity: severity,
+		Source:   diagnosticSource,
+		Message:  msg,
+	})
+}
+
+// occurrenceAt returns the identifier at pos, if any
+func (d *document) occurrenceAt(pos Position) (*occurrence, bool) {
+	for _, o := range d.occurrences {
+		if o.rng.Start.Line == pos.Line && o.rng.Start.Character <= pos.Character &&
+			pos.Character <= o.rng.End.Character {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// scopeAt returns the innermost scope containing line
+func (d *document) scopeAt(line int) *scope {
+	s := d.global
+	for found := true; found; {
+		found = false
+		for _, c := range s.children {
+			if c.start <= line && line <= c.end {
+				s, found = c, true
+				break
+			}
+		}
+	}
+	return s
+}
+
+// visibleVars returns the variables and parameters that may be referenced at line, inner declarations shadowing
+// outer ones
+func (d *document) visibleVars(line int) map[string]*symbol {
+	vars := make(map[string]*symbol)
+	for s := d.scopeAt(line); s != nil; s = s.parent {
+		for name, v := range s.vars {
+			if _, shadowed := vars[name]; !shadowed && v.decl.rng.Start.Line <= line {
+				vars[name] = v
+			}
+		}
+	}
+	return vars
+}
+
+func newScope(parent *scope, start, end int) *scope {
+	return &scope{
+		parent: parent,
+		start:  start,
+		end:    end,
+		vars:   make(map[string]*symbol),
+	}
+}
+
+// signature describes a symbol for hovers and completions
+func (s *symbol) signature() string {
+	switch s.kind {
+	case functionSymbol:
+		params := make([]string, len(s.fDec.Parameters))
+		for i, p := range s.fDec.Parameters {
+			params[i] = annotate(p.Name, s.fDec.ParameterTypes[i])
+		}
+		return annotate(fmt.Sprintf("func %v(%v)", s.name, strings.Join(params, ", ")), s.fDec.ReturnType)
+	case nativeSymbol:
+		params := "..."
+		if s.native.NumParams >= 0 {
+			names := make([]string, s.native.NumParams)
+			for i := range names {
+				names[i] = fmt.Sprintf("arg%v", i+1)
+			}
+			params = strings.Join(names, ", ")
+		}
+		sig := fmt.Sprintf("func %v(%v)", s.name, params)
+		if len(s.native.Capabilities) != 0 {
+			sig += fmt.Sprintf(" // native, requires %v", object.NewCapabilitySet(s.native.Capabilities...))
+		} else {
+			sig += " // native"
+		}
+		return sig
+	case parameterSymbol:
+		return annotate(s.name, s.annotation) + " // parameter"
+	default:
+		return annotate("var "+s.name, s.annotation)
+	}
+}
+
+func annotate(s string, ta *ast.TypeAnnotation) string {
+	if ta == nil {
+		return s
+	}
+	return fmt.Sprintf("%v: %v", s, ta.String())
+}
@@ -0,0 +1,340 @@
+package lsp
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/symbol_table"
+	"github.com/EricNRodriguez/yum/token"
+	"math"
+)
+
+// symbols are held as the values of the variables of the indexer's symbol table
+const symbolObject object.ObjectType = "symbol"
+
+// indexer resolves every identifier of a program to the symbol it refers to, by entering scopes and declaring variables
+// in a symbol table as the semantic analyser does. The AST records only the line of each node, so identifiers are
+// matched to their columns by walking the tree in source order
+type indexer struct {
+	symbol_table.SymbolTable
+	doc      *document
+	scope    *scope                 // the block being indexed, recorded for completions and renames
+	function *symbol                // innermost enclosing function, nil at the top level
+	masked   []string               // source lines with strings and comments blanked out
+	cursors  map[int]map[string]int // the column to search from for the next occurrence of a name on a line
+}
+
+func newIndexer(doc *document) (i *indexer) {
+	i = &indexer{
+		SymbolTable: symbol_table.NewSymbolTable(),
+		doc:         doc,
+		scope:       doc.global,
+		masked:      make([]string, len(doc.lines)),
+		cursors:     make(map[int]map[string]int),
+	}
+
+	for n, line := range doc.lines {
+		i.masked[n] = mask(line)
+	}
+
+	return
+}
+
+// mask blanks out string literals and comments, so that their contents are not mistaken for identifiers. The
+// expressions embedded in strings are left in place
+func mask(line string) string {
+	masked := []byte(line)
+	inString := false
+	interpolations := make([]int, 0) // braces opened within each interpolation, innermost last
+	for c := 0; c < len(masked); c++ {
+		n := len(interpolations)
+		switch {
+		case inString && masked[c] == '\\' && c+1 < len(masked):
+			masked[c], masked[c+1] = ' ', ' '
+			c++
+		case masked[c] == '"':
+			inString = !inString
+		case inString && masked[c] == '$' && c+1 < len(masked) && masked[c+1] == '{':
+			masked[c], masked[c+1] = ' ', ' '
+			c++
+			interpolations = append(interpolations, 0)
+			inString = false
+		case inString:
+			masked[c] = ' '
+		case n > 0 && masked[c] == '{':
+			interpolations[n-1]++
+		case n > 0 && masked[c] == '}':
+			if interpolations[n-1] > 0 {
+				interpolations[n-1]--
+				break
+			}
+			masked[c] = ' '
+			interpolations = interpolations[:n-1]
+			inString = true
+		case masked[c] == '/' && c+1 < len(masked) && masked[c+1] == '/':
+			for ; c < len(masked); c++ {
+				masked[c] = ' '
+			}
+		}
+	}
+	return string(masked)
+}
+
+func (i *indexer) index(node ast.Node) {
+	// the parser leaves out the parts of a program that it could not parse
+	if node != nil {
+		ast.Walk(i, node)
+	}
+	return
+}
+
+// Visit binds the identifiers of node. Declarations and blocks introduce names and scopes, so are indexed here, the
+// children of other nodes are left to ast.Walk
+func (i *indexer) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *ast.Program:
+		i.declareFunctions(n)
+	case *ast.IdentifierExpression:
+		i.bind(i.locate(n, n.Name), i.lookup(n.Name))
+	case *ast.ArrayIndexExpression:
+		i.bind(i.locate(n, n.ArrayName), i.lookup(n.ArrayName))
+	case *ast.FunctionCallExpression:
+		// functions of imported modules are declared in other files
+		if n.Module == "" {
+			i.bind(i.locate(n, n.FunctionName), i.doc.functions[n.FunctionName])
+		}
+	case *ast.StructExpression:
+		// fields are not symbols, so only the values are indexed
+		for _, v := range n.Values {
+			i.index(v)
+		}
+		return nil
+	case *ast.StructDeclarationStatement:
+		return nil // declares only fields
+	case *ast.VarStatement:
+		i.indexVarStatement(n)
+		return nil
+	case *ast.IfStatement:
+		i.indexIfStatement(n)
+		return nil
+	case *ast.WhileStatement:
+		i.indexWhileStatement(n)
+		return nil
+	case *ast.TryStatement:
+		i.indexTryStatement(n)
+		return nil
+	case *ast.FunctionDeclarationStatement:
+		i.indexFunctionDeclarationStatement(n)
+		return nil
+	}
+	return i
+}
+
+// functions are global, and may be called before they are declared
+func (i *indexer) declareFunctions(prog *ast.Program) {
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if fDec, ok := n.(*ast.FunctionDeclarationStatement); ok {
+			if _, declared := i.doc.functions[fDec.Name]; !declared {
+				i.doc.functions[fDec.Name] = &symbol{
+					name: fDec.Name,
+					kind: functionSymbol,
+					fDec: fDec,
+					end:  endLine(fDec.BodyEnd),
+				}
+			}
+		}
+		return true
+	})
+	return
+}
+
+func (i *indexer) indexBlock(stmts []ast.Statement) {
+	for _, s := range stmts {
+		i.index(s)
+	}
+	return
+}
+
+func (i *indexer) indexNestedBlock(stmts []ast.Statement, start token.Metadata, end token.Metadata) {
+	i.enterBlock(start, end)
+	i.indexBlock(stmts)
+	i.exitBlock()
+	return
+}
+
+// enterBlock enters the scope of a block, from the line of start to that of its closing brace end
+func (i *indexer) enterBlock(start token.Metadata, end token.Metadata) {
+	s := newScope(i.scope, start.LineNumber()-1, endLine(end))
+	i.scope.children = append(i.scope.children, s)
+	i.scope = s
+	i.EnterScope()
+	return
+}
+
+func (i *indexer) exitBlock() {
+	i.ExitScope()
+	i.scope = i.scope.parent
+	return
+}
+
+func (i *indexer) indexVarStatement(stmt *ast.VarStatement) {
+	// the declared name precedes the expression in the source, but is not in scope within it
+	occ := i.locate(stmt.IdentifierNode, stmt.IdentifierNode.Name)
+	i.index(stmt.Expression)
+
+	if occ != nil {
+		i.declare(occ, stmt.IdentifierNode.Name, variableSymbol, stmt.TypeAnnotation)
+	}
+	return
+}
+
+func (i *indexer) indexIfStatement(ifStmt *ast.IfStatement) {
+	i.index(ifStmt.Condition)
+	i.indexNestedBlock(ifStmt.IfBlock, ifStmt, ifStmt.IfBlockEnd)
+	if ifStmt.ElseBlock != nil && ifStmt.IfBlockEnd != nil {
+		i.indexNestedBlock(ifStmt.ElseBlock, ifStmt.IfBlockEnd, ifStmt.ElseBlockEnd)
+	}
+	return
+}
+
+func (i *indexer) indexWhileStatement(wStmt *ast.WhileStatement) {
+	i.index(wStmt.Condition)
+	i.indexNestedBlock(wStmt.Block, wStmt, wStmt.BlockEnd)
+	return
+}
+
+func (i *indexer) indexTryStatement(tStmt *ast.TryStatement) {
+	i.indexNestedBlock(tStmt.TryBlock, tStmt, tStmt.TryBlockEnd)
+
+	start := tStmt.TryBlockEnd
+	if tStmt.CatchName != nil && start != nil {
+		// the caught error is declared in the scope of the catch block
+		i.enterBlock(start, tStmt.CatchBlockEnd)
+		if occ := i.locate(tStmt.CatchName, tStmt.CatchName.Name); occ != nil {
+			i.declare(occ, tStmt.CatchName.Name, variableSymbol, nil)
+		}
+		i.indexBlock(tStmt.CatchBlock)
+		i.exitBlock()
+		start = tStmt.CatchBlockEnd
+	}
+
+	if tStmt.FinallyBlock != nil && start != nil {
+		i.indexNestedBlock(tStmt.FinallyBlock, start, tStmt.FinallyBlockEnd)
+	}
+	return
+}
+
+func (i *indexer) indexFunctionDeclarationStatement(fDec *ast.FunctionDeclarationStatement) {
+	f := i.doc.functions[fDec.Name]
+	if occ := i.locate(fDec, fDec.Name); occ != nil && f.fDec == fDec {
+		f.decl = occ
+		i.bind(occ, f)
+		if i.function == nil {
+			i.doc.symbols = append(i.doc.symbols, f)
+		}
+	}
+
+	// functions have their own namespace
+	s := newScope(nil, fDec.LineNumber()-1, endLine(fDec.BodyEnd))
+	i.scope.children = append(i.scope.children, s)
+
+	cachedScope, cachedFunction := i.scope, i.function
+	i.scope, i.function = s, f
+	i.EnterFunction()
+
+	for n := range fDec.Parameters {
+		p := &fDec.Parameters[n]
+		if occ := i.locate(p, p.Name); occ != nil {
+			i.declare(occ, p.Name, parameterSymbol, fDec.ParameterTypes[n])
+		}
+	}
+	i.indexBlock(fDec.Body)
+
+	i.ExitFunction()
+	i.scope, i.function = cachedScope, cachedFunction
+	return
+}
+
+// declare adds a variable or parameter, declared at occ, to the current scope
+func (i *indexer) declare(occ *occurrence, name string, kind symbolKind, ta *ast.TypeAnnotation) {
+	v := &symbol{
+		name:       name,
+		kind:       kind,
+		decl:       occ,
+		annotation: ta,
+	}
+	i.SetVar(name, v)
+	i.scope.vars[name] = v
+	i.bind(occ, v)
+
+	switch {
+	case i.function != nil:
+		i.function.locals = append(i.function.locals, v)
+	case i.GetScope() == 0:
+		i.doc.symbols = append(i.doc.symbols, v)
+	}
+	return
+}
+
+func (i *indexer) lookup(name string) *symbol {
+	if v, ok := i.GetVar(name); ok {
+		return v.(*symbol)
+	}
+	return nil
+}
+
+// bind records occ as a reference to sym
+func (i *indexer) bind(occ *occurrence, sym *symbol) {
+	if occ == nil || sym == nil {
+		return
+	}
+	occ.sym = sym
+	sym.refs = append(sym.refs, occ)
+	return
+}
+
+// locate finds the next occurrence of name on md's line
+func (i *indexer) locate(md token.Metadata, name string) *occurrence {
+	line := md.LineNumber() - 1
+	if line < 0 || line >= len(i.masked) {
+		return nil
+	}
+
+	if _, ok := i.cursors[line]; !ok {
+		i.cursors[line] = make(map[string]int)
+	}
+
+	text := i.masked[line]
+	for c := i.cursors[line][name]; c+len(name) <= len(text); c++ {
+		// names following a dot are qualified by a module, so never refer to this document's symbols
+		if text[c:c+len(name)] != name || (c > 0 && (isIdentifierByte(text[c-1]) || text[c-1] == '.')) ||
+			(c+len(name) < len(text) && isIdentifierByte(text[c+len(name)])) {
+			continue
+		}
+
+		i.cursors[line][name] = c + len(name)
+		occ := &occurrence{
+			rng: Range{
+				Start: Position{Line: line, Character: c},
+				End:   Position{Line: line, Character: c + len(name)},
+			},
+		}
+		i.doc.occurrences = append(i.doc.occurrences, occ)
+		return occ
+	}
+	return nil
+}
+
+func isIdentifierByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// endLine converts the position of a closing brace to a line index, blocks of unknown extent run to the end of the
+// document
+func endLine(md token.Metadata) int {
+	if md == nil {
+		return math.MaxInt32
+	}
+	return md.LineNumber() - 1
+}
@@ -0,0 +1,21 @@
+package lsp
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	tCs := []struct {
+		line     string
+		expected string
+	}{
+		{`print("x", x); // x`, `print(" ", x);     `},
+		{`var s = "a ${x + f("y")} b";`, `var s = "    x + f(" ")   ";`},
+		{`var s = "${ {x} } ${"${y}"} $z";`, `var s = "   {x}     "  y "    ";`},
+		{`var s = "a\"x\\" + y;`, `var s = "      " + y;`},
+	}
+
+	for i, tC := range tCs {
+		if masked := mask(tC.line); masked != tC.expected {
+			t.Errorf("test case %v: expected %q, got %q", i, tC.expected, masked)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package lsp
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const serverName = "yum"
+
+// Server is a language server for yum, communicating over a single stream using JSON-RPC
+type Server interface {
+	// Serve handles messages until the client sends exit or closes the stream
+	Serve() error
+}
+
+type requestMethod func(params json.RawMessage) (interface{}, error)
+
+type notificationMethod func(params json.RawMessage) error
+
+type server struct {
+	in                 *bufio.Reader
+	out                io.Writer
+	documents          map[string]*document
+	requestRouter      map[string]requestMethod
+	notificationRouter map[string]notificationMethod
+	exited             bool
+}
+
+func NewServer(r io.Reader, w io.Writer) Server {
+	s := &server{
+		in:        bufio.NewReader(r),
+		out:       w,
+		documents: make(map[string]*document),
+	}
+
+	s.requestRouter = map[string]requestMethod{
+		"initialize":                  s.initialize,
+		"shutdown":                    s.shutdown,
+		"textDocument/definition":     s.definition,
+		"textDocument/hover":          s.hover,
+		"textDocument/documentSymbol": s.documentSymbol,
+		"textDocument/completion":     s.completion,
+		"textDocument/rename":         s.rename,
+	}
+
+	s.notificationRouter = map[string]notificationMethod{
+		"initialized":            s.ignore,
+		"exit":                   s.exit,
+		"textDocument/didOpen":   s.didOpen,
+		"textDocument/didChange": s.didChange,
+		"textDocument/didClose":  s.didClose,
+	}
+
+	return s
+}
+
+func (s *server) Serve() error {
+	for !s.exited {
+		body, err := internal.ReadMessage(s.in)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var msg message
+		if err = json.Unmarshal(body, &msg); err != nil {
+			err = s.reply(nil, nil, &responseError{Code: parseErrorCode, Message: err.Error()})
+		} else if msg.ID != nil {
+			err = s.handleRequest(msg)
+		} else {
+			err = s.handleNotification(msg)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) handleRequest(msg message) error {
+	method, ok := s.requestRouter[msg.Method]
+	if !ok {
+		return s.reply(msg.ID, nil, &responseError{
+			Code:    methodNotFoundCode,
+			Message: fmt.Sprintf(internal.ErrUnknownMethod, msg.Method),
+		})
+	}
+
+	result, err := method(msg.Params)
+	return s.reply(msg.ID, result, err)
+}
+
+// handleNotification dispatches a notification, unknown notifications are ignored as required by the protocol
+func (s *server) handleNotification(msg message) error {
+	if method, ok := s.notificationRouter[msg.Method]; ok {
+		return method(msg.Params)
+	}
+	return nil
+}
+
+func (s *server) reply(id *json.RawMessage, result interface{}, err error) error {
+	if err == nil {
+		return internal.WriteMessage(s.out, response{JSONRPC: jsonRPCVersion, ID: id, Result: result})
+	}
+
+	rErr, ok := err.(*responseError)
+	if !ok {
+		rErr = &responseError{Code: requestFailedCode, Message: err.Error()}
+	}
+	return internal.WriteMessage(s.out, errorResponse{JSONRPC: jsonRPCVersion, ID: id, Error: rErr})
+}
+
+func (s *server) notify(method string, params interface{}) error {
+	return internal.WriteMessage(s.out, notification{JSONRPC: jsonRPCVersion, Method: method, Params: params})
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: invalidParamsCode, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *server) document(uri string) (*document, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: invalidParamsCode, Message: fmt.Sprintf(internal.ErrUnknownDocument, uri)}
+	}
+	return d, nil
+}
+
+// lifecycle
+
+func (s *server) initialize(params json.RawMessage) (interface{}, error) {
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       fullSync,
+			DefinitionProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+			CompletionProvider:     CompletionOptions{},
+			RenameProvider:         true,
+		},
+		ServerInfo: ServerInfo{Name: serverName},
+	}, nil
+}
+
+func (s *server) shutdown(params json.RawMessage) (interface{}, error) {
+	s.documents = make(map[string]*document)
+	return nil, nil
+}
+
+func (s *server) exit(params json.RawMessage) error {
+	s.exited = true
+	return nil
+}
+
+func (s *server) ignore(params json.RawMessage) error {
+	return nil
+}
+
+// document synchronisation
+
+func (s *server) didOpen(params json.RawMessage) error {
+	var p DidOpenTextDocumentParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil
+	}
+	return s.update(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *server) didChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := decodeParams(params, &p); err != nil || len(p.ContentChanges) == 0 {
+		return nil
+	}
+	// changes hold the full text, so only the last one matters
+	return s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *server) didClose(params json.RawMessage) error {
+	var p DidCloseTextDocumentParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil
+	}
+	delete(s.documents, p.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: make([]Diagnostic, 0),
+	})
+}
+
+// update analyses the new text of a document and publishes its diagnostics
+func (s *server) update(uri, text string) error {
+	d := newDocument(uri, text)
+	s.documents[uri] = d
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: d.diagnostics,
+	})
+}
+
+// language features
+
+func (s *server) definition(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	// native functions have no source to jump to
+	if occ, ok := d.occurrenceAt(p.Position); ok && occ.sym != nil && occ.sym.decl != nil {
+		return Location{URI: d.uri, Range: occ.sym.decl.rng}, nil
+	}
+	return nil, nil
+}
+
+func (s *server) hover(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	if occ, ok := d.occurrenceAt(p.Position); ok && occ.sym != nil {
+		return Hover{
+			Contents: MarkupContent{
+				Kind:  "markdown",
+				Value: fmt.Sprintf("```yum\n%v\n```", occ.sym.signature()),
+			},
+			Range: occ.rng,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (s *server) documentSymbol(params json.RawMessage) (interface{}, error) {
+	var p DocumentSymbolParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]DocumentSymbol, 0, len(d.symbols))
+	for _, sym := range d.symbols {
+		symbols = append(symbols, d.documentSymbol(sym))
+	}
+	return symbols, nil
+}
+
+func (d *document) documentSymbol(sym *symbol) DocumentSymbol {
+	ds := DocumentSymbol{
+		Name:           sym.name,
+		Detail:         sym.signature(),
+		Kind:           variableSymbolKind,
+		Range:          sym.decl.rng,
+		SelectionRange: sym.decl.rng,
+	}
+
+	if sym.kind == functionSymbol {
+		end := sym.end
+		if end >= len(d.lines) {
+			end = len(d.lines) - 1
+		}
+		ds.Kind = functionSymbolKind
+		ds.Range = Range{
+			Start: Position{Line: sym.decl.rng.Start.Line},
+			End:   Position{Line: end, Character: len(d.lines[end])},
+		}
+		for _, l := range sym.locals {
+			ds.Children = append(ds.Children, d.documentSymbol(l))
+		}
+	}
+	return ds
+}
+
+func (s *server) completion(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]CompletionItem, 0)
+	for name, v := range d.visibleVars(p.Position.Line) {
+		items = append(items, CompletionItem{Label: name, Kind: variableCompletionKind, Detail: v.signature()})
+	}
+	for name, f := range d.functions {
+		items = append(items, CompletionItem{Label: name, Kind: functionCompletionKind, Detail: f.signature()})
+	}
+
+	// clients filter by the typed prefix, a stable order keeps the list from jumping around
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Label != items[j].Label {
+			return items[i].Label < items[j].Label
+		}
+		return items[i].Kind < items[j].Kind
+	})
+	return items, nil
+}
+
+func (s *server) rename(params json.RawMessage) (interface{}, error) {
+	var p RenameParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	occ, ok := d.occurrenceAt(p.Position)
+	if !ok || occ.sym == nil {
+		return nil, &responseError{
+			Code:    requestFailedCode,
+			Message: fmt.Sprintf(internal.ErrNoSymbol, p.Position.Line+1, p.Position.Character+1),
+		}
+	}
+
+	if err = d.checkRename(occ.sym, p.NewName); err != nil {
+		return nil, err
+	}
+
+	edits := make([]TextEdit, len(occ.sym.refs))
+	for i, ref := range occ.sym.refs {
+		edits[i] = TextEdit{Range: ref.rng, NewText: p.NewName}
+	}
+	return WorkspaceEdit{Changes: map[string][]TextEdit{d.uri: edits}}, nil
+}
+
+// checkRename ensures renaming sym to name would not change the meaning of the program
+func (d *document) checkRename(sym *symbol, name string) error {
+	fail := func(msg string, arg string) error {
+		return &responseError{Code: requestFailedCode, Message: fmt.Sprintf(msg, arg)}
+	}
+
+	switch {
+	case !lexer.IsIdentifier(name):
+		return fail(internal.ErrInvalidRename, name)
+	case sym.kind == nativeSymbol:
+		return fail(internal.ErrRenameNative, sym.name)
+	case name == sym.name:
+		return nil
+	}
+
+	if sym.kind == functionSymbol {
+		if _, ok := d.functions[name]; ok {
+			return fail(internal.ErrRenameConflict, name)
+		}
+		return nil
+	}
+
+	// the new name must not be declared alongside the variable, nor shadow or be shadowed at any reference
+	for s := d.scopeAt(sym.decl.rng.Start.Line); s != nil; s = s.parent {
+		if s.vars[sym.name] == sym {
+			if _, ok := s.vars[name]; ok {
+				return fail(internal.ErrRenameConflict, name)
+			}
+			break
+		}
+	}
+	for _, ref := range sym.refs {
+		if v, ok := d.visibleVars(ref.rng.Start.Line)[name]; ok && v != sym {
+			return fail(internal.ErrRenameConflict, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,495 @@
+package lsp
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testURI = "file:///test.yum"
+
+// testClient drives a server running in the same process
+type testClient struct {
+	t      *testing.T
+	in     *bufio.Reader
+	out    *io.PipeWriter
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{
+		t:    t,
+		in:   bufio.NewReader(clientIn),
+		out:  clientOut,
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		err := NewServer(serverIn, serverOut).Serve()
+		serverOut.Close()
+		c.done <- err
+	}()
+
+	c.request("initialize", map[string]interface{}{}, nil)
+	c.notify("initialized", map[string]interface{}{})
+	return c
+}
+
+func (c *testClient) read() message {
+	body, err := internal.ReadMessage(c.in)
+	if err != nil {
+		c.t.Fatalf("failed to read message: %v", err)
+	}
+
+	var msg message
+	if err = json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("failed to decode message: %v", err)
+	}
+	return msg
+}
+
+func (c *testClient) send(v interface{}) {
+	if err := internal.WriteMessage(c.out, v); err != nil {
+		c.t.Fatalf("failed to write message: %v", err)
+	}
+}
+
+// request sends a request and decodes the result of its response into result, returning the error if there is one
+func (c *testClient) request(method string, params interface{}, result interface{}) *responseError {
+	c.nextID++
+	c.send(map[string]interface{}{"jsonrpc": jsonRPCVersion, "id": c.nextID, "method": method, "params": params})
+
+	msg := c.read()
+	if msg.ID == nil || string(*msg.ID) != strings.TrimSpace(mustMarshal(c.t, c.nextID)) {
+		c.t.Fatalf("expected response to request %v, got %+v", c.nextID, msg)
+	}
+
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result != nil {
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("failed to decode result %s: %v", msg.Result, err)
+		}
+	}
+	return nil
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.send(notification{JSONRPC: jsonRPCVersion, Method: method, Params: params})
+}
+
+// open sends the document's text to the server, returning the published diagnostics
+func (c *testClient) open(text string) []Diagnostic {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "yum", Version: 1, Text: text},
+	})
+	return c.diagnostics()
+}
+
+func (c *testClient) diagnostics() []Diagnostic {
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("expected diagnostics, got %+v", msg)
+	}
+
+	var p PublishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		c.t.Fatal(err)
+	}
+	return p.Diagnostics
+}
+
+func (c *testClient) close() {
+	c.request("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server failed: %v", err)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func position(uri string, line, char int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: char},
+	}
+}
+
+func span(line, start, end int) Range {
+	return Range{Start: Position{Line: line, Character: start}, End: Position{Line: line, Character: end}}
+}
+
+const testProgram = `var x = 1;
+func add(a, b: int): int {
+    var s = a + b; // a + b
+    return s;
+};
+if (x == 1) {
+    var x = add(x, 2);
+    print(x);
+};
+print(add(x, x), "x");
+`
+
+func TestDiagnostics(t *testing.T) {
+	type diagnosticsTestCase struct {
+		text     string
+		expected []Diagnostic
+	}
+
+	tCs := []diagnosticsTestCase{
+		{
+			testProgram,
+			[]Diagnostic{
+				{Range: span(6, 4, 22), Severity: warningSeverity, Source: diagnosticSource},
+			},
+		},
+		{
+			"var x = 1;\n  print(y);",
+			[]Diagnostic{
+				{Range: span(1, 2, 11), Severity: errorSeverity, Source: diagnosticSource},
+			},
+		},
+		{
+			"var x = 1;\nvar y = ;",
+			[]Diagnostic{
+				{Range: span(1, 0, 9), Severity: errorSeverity, Source: diagnosticSource},
+			},
+		},
+		{
+			"func f(a) {\n    var x = ;\n    return a;\n};",
+			[]Diagnostic{
+				{Range: span(1, 4, 13), Severity: errorSeverity, Source: diagnosticSource},
+			},
+		},
+		{
+			"func f(a) {\n    return a;\n    print(a);\n};\nf(1);",
+			[]Diagnostic{
+				{Range: span(2, 4, 13), Severity: warningSeverity, Source: diagnosticSource},
+			},
+		},
+	}
+
+	c := newTestClient(t)
+	defer c.close()
+
+	for i, tC := range tCs {
+		diagnostics := c.open(tC.text)
+		for j := range diagnostics {
+			if diagnostics[j].Message == "" {
+				t.Errorf("test case %v: diagnostic %v has no message", i, j)
+			}
+			diagnostics[j].Message = ""
+		}
+
+		if !reflect.DeepEqual(diagnostics, tC.expected) {
+			t.Errorf("test case %v: expected diagnostics %+v, got %+v", i, tC.expected, diagnostics)
+		}
+	}
+
+	// diagnostics are cleared on close
+	c.open("print(y);")
+	c.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}})
+	if d := c.diagnostics(); len(d) != 0 {
+		t.Errorf("expected diagnostics to be cleared on close, got %+v", d)
+	}
+}
+
+func TestDidChange(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	c.open("print(y);")
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "var y = 1;\nprint(y);"}},
+	})
+	if d := c.diagnostics(); len(d) != 0 {
+		t.Errorf("expected no diagnostics after change, got %+v", d)
+	}
+
+	var loc *Location
+	c.request("textDocument/definition", position(testURI, 1, 6), &loc)
+	if loc == nil || loc.Range != span(0, 4, 5) {
+		t.Errorf("expected definition of the changed document, got %+v", loc)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	type definitionTestCase struct {
+		line, char int
+		expected   *Range
+	}
+
+	rng := func(line, start, end int) *Range {
+		r := span(line, start, end)
+		return &r
+	}
+
+	tCs := []definitionTestCase{
+		{0, 4, rng(0, 4, 5)},   // declaration of x
+		{5, 4, rng(0, 4, 5)},   // x in the condition
+		{6, 16, rng(0, 4, 5)},  // x in the shadowing declaration's expression
+		{7, 10, rng(6, 8, 9)},  // the shadowing x
+		{9, 7, rng(1, 5, 8)},   // add
+		{9, 13, rng(0, 4, 5)},  // second argument, x
+		{3, 11, rng(2, 8, 9)},  // s
+		{2, 12, rng(1, 9, 10)}, // parameter a
+		{2, 20, nil},           // in a comment
+		{9, 18, nil},           // in a string
+		{9, 1, nil},            // native function
+		{4, 0, nil},            // punctuation
+	}
+
+	c := newTestClient(t)
+	defer c.close()
+	c.open(testProgram)
+
+	for i, tC := range tCs {
+		var loc *Location
+		if err := c.request("textDocument/definition", position(testURI, tC.line, tC.char), &loc); err != nil {
+			t.Fatalf("test case %v: unexpected error %v", i, err)
+		}
+
+		switch {
+		case tC.expected == nil && loc != nil:
+			t.Errorf("test case %v: expected no definition, got %+v", i, loc)
+		case tC.expected != nil && (loc == nil || loc.URI != testURI || loc.Range != *tC.expected):
+			t.Errorf("test case %v: expected definition at %+v, got %+v", i, tC.expected, loc)
+		}
+	}
+}
+
+func TestHover(t *testing.T) {
+	type hoverTestCase struct {
+		line, char int
+		expected   string
+	}
+
+	tCs := []hoverTestCase{
+		{9, 7, "func add(a, b: int): int"},
+		{1, 10, "a // parameter"},
+		{1, 12, "b: int // parameter"},
+		{0, 4, "var x"},
+		{9, 1, "func print(...) // native, requires io"},
+		{4, 0, ""},
+	}
+
+	c := newTestClient(t)
+	defer c.close()
+	c.open(testProgram)
+
+	for i, tC := range tCs {
+		var hover *Hover
+		c.request("textDocument/hover", position(testURI, tC.line, tC.char), &hover)
+
+		if tC.expected == "" {
+			if hover != nil {
+				t.Errorf("test case %v: expected no hover, got %+v", i, hover)
+			}
+			continue
+		}
+
+		if hover == nil || hover.Contents.Value != "```yum\n"+tC.expected+"\n```" {
+			t.Errorf("test case %v: expected hover %q, got %+v", i, tC.expected, hover)
+		}
+	}
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+	c.open(testProgram)
+
+	var symbols []DocumentSymbol
+	c.request("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: testURI}},
+		&symbols)
+
+	expected := []DocumentSymbol{
+		{Name: "x", Detail: "var x", Kind: variableSymbolKind, Range: span(0, 4, 5), SelectionRange: span(0, 4, 5)},
+		{
+			Name:           "add",
+			Detail:         "func add(a, b: int): int",
+			Kind:           functionSymbolKind,
+			Range:          Range{Start: Position{Line: 1}, End: Position{Line: 4, Character: 2}},
+			SelectionRange: span(1, 5, 8),
+			Children: []DocumentSymbol{
+				{Name: "a", Detail: "a // parameter", Kind: variableSymbolKind, Range: span(1, 9, 10),
+					SelectionRange: span(1, 9, 10)},
+				{Name: "b", Detail: "b: int // parameter", Kind: variableSymbolKind, Range: span(1, 12, 13),
+					SelectionRange: span(1, 12, 13)},
+				{Name: "s", Detail: "var s", Kind: variableSymbolKind, Range: span(2, 8, 9),
+					SelectionRange: span(2, 8, 9)},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(symbols, expected) {
+		t.Errorf("expected symbols %+v, got %+v", expected, symbols)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	type completionTestCase struct {
+		line     int
+		expected []string // variables and parameters in scope
+	}
+
+	tCs := []completionTestCase{
+		{0, []string{"x"}},
+		{3, []string{"a", "b", "s"}},
+		{7, []string{"x"}},
+		{9, []string{"x"}},
+	}
+
+	c := newTestClient(t)
+	defer c.close()
+	c.open(testProgram)
+
+	for i, tC := range tCs {
+		var items []CompletionItem
+		c.request("textDocument/completion", position(testURI, tC.line, 0), &items)
+
+		vars := make([]string, 0)
+		functions := make(map[string]bool)
+		for _, item := range items {
+			if item.Kind == variableCompletionKind {
+				vars = append(vars, item.Label)
+			} else {
+				functions[item.Label] = true
+			}
+		}
+		sort.Strings(vars)
+
+		if !reflect.DeepEqual(vars, tC.expected) {
+			t.Errorf("test case %v: expected variables %v, got %v", i, tC.expected, vars)
+		}
+		for _, f := range []string{"add", "print", "length"} {
+			if !functions[f] {
+				t.Errorf("test case %v: expected completion of function %v", i, f)
+			}
+		}
+	}
+}
+
+func TestRename(t *testing.T) {
+	type renameTestCase struct {
+		line, char int
+		newName    string
+		expected   []Range // nil if the rename should fail
+	}
+
+	tCs := []renameTestCase{
+		{0, 4, "y", []Range{span(0, 4, 5), span(5, 4, 5), span(6, 16, 17), span(9, 10, 11), span(9, 13, 14)}},
+		{7, 10, "z", []Range{span(6, 8, 9), span(7, 10, 11)}},
+		{1, 6, "sum", []Range{span(1, 5, 8), span(6, 12, 15), span(9, 6, 9)}},
+		{1, 9, "c", []Range{span(1, 9, 10), span(2, 12, 13)}},
+		{1, 9, "b", nil}, // parameter conflict
+		{0, 4, "add1", []Range{span(0, 4, 5), span(5, 4, 5), span(6, 16, 17), span(9, 10, 11), span(9, 13, 14)}},
+		{1, 6, "print", nil},  // function conflict
+		{9, 1, "output", nil}, // native function
+		{0, 4, "while", nil},  // keyword
+		{0, 4, "1x", nil},     // not an identifier
+		{4, 0, "y", nil},      // no symbol
+	}
+
+	c := newTestClient(t)
+	defer c.close()
+	c.open(testProgram)
+
+	for i, tC := range tCs {
+		var edit WorkspaceEdit
+		err := c.request("textDocument/rename", RenameParams{
+			TextDocument: TextDocumentIdentifier{URI: testURI},
+			Position:     Position{Line: tC.line, Character: tC.char},
+			NewName:      tC.newName,
+		}, &edit)
+
+		if tC.expected == nil {
+			if err == nil {
+				t.Errorf("test case %v: expected rename to %v to fail", i, tC.newName)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("test case %v: unexpected error %v", i, err)
+			continue
+		}
+
+		ranges := make([]Range, 0)
+		for _, e := range edit.Changes[testURI] {
+			if e.NewText != tC.newName {
+				t.Errorf("test case %v: expected new text %v, got %v", i, tC.newName, e.NewText)
+			}
+			ranges = append(ranges, e.Range)
+		}
+
+		if !reflect.DeepEqual(ranges, tC.expected) {
+			t.Errorf("test case %v: expected edits %+v, got %+v", i, tC.expected, ranges)
+		}
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	if err := c.request("textDocument/unknown", map[string]interface{}{}, nil); err == nil ||
+		err.Code != methodNotFoundCode {
+		t.Errorf("expected method not found, got %v", err)
+	}
+
+	if err := c.request("textDocument/hover", position("file:///closed.yum", 0, 0), nil); err == nil ||
+		err.Code != invalidParamsCode {
+		t.Errorf("expected invalid params for a closed document, got %v", err)
+	}
+
+	if err := c.request("textDocument/hover", []int{1}, nil); err == nil || err.Code != invalidParamsCode {
+		t.Errorf("expected invalid params, got %v", err)
+	}
+
+	// unknown notifications are ignored
+	c.notify("$/cancelRequest", map[string]interface{}{"id": 1})
+	c.open("var x = ;\nfunc")
+}
+
+func TestReadMessage(t *testing.T) {
+	type readMessageTestCase struct {
+		input    string
+		expected string
+		fail     bool
+	}
+
+	tCs := []readMessageTestCase{
+		{"Content-Length: 2\r\n\r\n{}", "{}", false},
+		{"content-length: 2\r\nContent-Type: application/vscode-jsonrpc\r\n\r\n[]", "[]", false},
+		{"Content-Type: application/vscode-jsonrpc\r\n\r\n{}", "", true},
+		{"Content-Length 2\r\n\r\n{}", "", true},
+		{"Content-Length: two\r\n\r\n{}", "", true},
+	}
+
+	for i, tC := range tCs {
+		msg, err := internal.ReadMessage(bufio.NewReader(strings.NewReader(tC.input)))
+		if tC.fail != (err != nil) {
+			t.Errorf("test case %v: expected failure %v, got %v", i, tC.fail, err)
+		} else if !tC.fail && string(msg) != tC.expected {
+			t.Errorf("test case %v: expected %q, got %q", i, tC.expected, msg)
+		}
+	}
+}
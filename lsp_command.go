@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/lsp"
+	"log"
+	"os"
+)
+
+// lspCommand implements yum lsp, running a language server over stdin and stdout
+func lspCommand(args []string) int {
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -2,39 +2,77 @@ package main
 
 import (
 	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/cover"
 	"github.com/EricNRodriguez/yum/eval"
 	"github.com/EricNRodriguez/yum/internal"
-	"github.com/EricNRodriguez/yum/lexer"
-	"github.com/EricNRodriguez/yum/parser"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/optimize"
+	"github.com/EricNRodriguez/yum/profile"
 	"github.com/EricNRodriguez/yum/semantic"
+	"flag"
 	"fmt"
 	"github.com/spf13/afero"
 	"log"
 	"os"
 )
 
+// commands are tools invoked as yum <command> [arguments], rather than running a script
+var commands = map[string]func(args []string) int{
+	"fmt":    formatCommand,
+	"tokens": tokensCommand,
+	"ast":    astCommand,
+	"lsp":    lspCommand,
+	"debug":  debugCommand,
+	"dap":    dapCommand,
+	"cover":  coverCommand,
+	"test":   testCommand,
+}
+
 func main() {
 	var (
-		l     lexer.Lexer
 		appFs afero.Fs
 		fp    string
-		f     afero.File
-		p     parser.Parser
 		prog  ast.Node
 		sA    semantic.SemanticAnalyser
-		e     eval.Evaluator
+		caps  object.CapabilitySet
+		code  int
 		err   error
 		errs  []error
 	)
 
-	if len(os.Args[1:]) == 0 {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	allow := flag.String("allow", object.AllCapabilities.String(),
+		"comma separated capabilities granted to the script's native functions")
+	typecheck := flag.Bool("typecheck", false, "statically check types before running the script")
+	optimized := flag.Bool("optimize", false, "fold constants, remove dead branches and inline trivial functions")
+	dumpOptimized := flag.Bool("dump-optimized", false, "print the optimized program instead of running it")
+	profilePath := flag.String("profile", "", "write a pprof profile of the script's execution to this file")
+	coverPath := flag.String("cover", "", "write a profile of the statements and branches the script ran to this file")
+	root := flag.String("root", "", "restrict the script's file functions to this directory")
+	readOnly := flag.Bool("readonly", false, "prevent the script's file functions from writing")
+	flag.Parse()
+
+	if set, unknown, ok := object.ParseCapabilitySet(*allow); !ok {
+		fmt.Printf(internal.ErrUnknownCapability+"\n", unknown)
+		os.Exit(0)
+	} else {
+		caps = set
+	}
+
+	if flag.NArg() == 0 {
 		fmt.Println(internal.ErrFileNotProvided)
 		os.Exit(0)
 	}
 
 	appFs = afero.NewOsFs()
 
-	fp = os.Args[1:][0]
+	fp = flag.Arg(0)
 	if _, err := os.Stat(fp); err != nil {
 		if os.IsNotExist(err) {
 			fmt.Printf(internal.ErrFileNotFound+"\n", fp)
@@ -44,41 +82,136 @@ func main() {
 		os.Exit(0)
 	}
 
-	if f, err = appFs.Open(fp); err != nil {
-		log.Println(fmt.Sprintf(internal.ErrFailedToReadFile+"\n", fp, err))
+	// parses the script and the modules it imports
+	if prog, errs = module.Load(appFs, fp); len(errs) != 0 {
+		for _, e := range errs {
+			log.Println(e)
+		}
 		os.Exit(0)
 	}
 
-	if l, err = lexer.NewLexer(f); err != nil {
-		log.Println(err)
+	sA = semantic.NewSemanticAnalyser(semantic.WithCapabilities(caps))
+
+	if errs = sA.Analyse(prog); errs != nil && len(errs) != 0 {
+		for _, e := range errs {
+			log.Println(e)
+		}
+		os.Exit(0)
+	}
+
+	// warnings do not prevent the program from running
+	for _, w := range sA.Warnings() {
+		log.Println(w)
+	}
+
+	if *typecheck {
+		if errs = semantic.NewTypeChecker().Analyse(prog); len(errs) != 0 {
+			for _, e := range errs {
+				log.Println(e)
+			}
+			os.Exit(0)
+		}
+	}
+
+	if *optimized || *dumpOptimized {
+		prog = optimize.NewOptimizer().Optimize(prog.(*ast.Program))
+	}
+
+	if *dumpOptimized {
+		fmt.Print(prog.String())
 		os.Exit(0)
 	}
 
-	defer l.Close()
+	// the file functions act on the host's file system, unless restricted
+	scriptFs := appFs
+	if *root != "" {
+		scriptFs = afero.NewBasePathFs(scriptFs, *root)
+	}
+	if *readOnly {
+		scriptFs = afero.NewReadOnlyFs(scriptFs)
+	}
+
+	// arguments after the script are passed to it
+	opts := []eval.Option{
+		eval.WithCapabilities(caps),
+		eval.WithFileSystem(scriptFs),
+		eval.WithProcess(flag.Args()[1:], os.Stdin),
+	}
 
-	if p, err = parser.NewRecursiveDescentParser(l); err != nil {
+	if code, err = execute(appFs, prog.(*ast.Program), opts, *profilePath, *coverPath); err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
+	os.Exit(code)
+}
 
-	if prog, errs = p.Parse(); errs != nil && len(errs) != 0 {
-		for _, e := range errs {
-			log.Println(e)
-		}
-		os.Exit(0)
+// execute evaluates prog, writing its profile and coverage to profilePath and coverPath, if they are given, once the
+// program has ended, even if it was ended by an error. It returns the program's exit status
+func execute(fs afero.Fs, prog *ast.Program, opts []eval.Option, profilePath, coverPath string) (int, error) {
+	var (
+		profiler profile.Profiler
+		recorder cover.Recorder
+	)
+
+	if profilePath != "" {
+		profiler = profile.NewProfiler(prog)
+		opts = append(opts, profiler.Options()...)
+	}
+	if coverPath != "" {
+		recorder = cover.NewRecorder(prog)
+		opts = append(opts, recorder.Options()...)
 	}
 
-	sA = semantic.NewSemanticAnalyser()
+	e := eval.NewEvaluator(append(opts, eval.WithRecoverableErrors())...)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					panic(r)
+				}
+				eval.LogError(err, e.StackTrace())
+			}
+		}()
+		e.Evaluate(prog)
+	}()
 
-	if errs = sA.Analyse(prog); errs != nil && len(errs) != 0 {
-		for _, e := range errs {
-			log.Println(e)
+	if profiler != nil {
+		if err := writeProfile(fs, profilePath, profiler); err != nil {
+			return 0, err
 		}
-		os.Exit(0)
 	}
 
-	e = eval.NewEvaluator()
-	e.Evaluate(prog)
+	if recorder != nil {
+		if err := writeCoverage(fs, coverPath, recorder.Profile()); err != nil {
+			return 0, err
+		}
+	}
+	return e.ExitCode(), nil
+}
+
+func writeProfile(fs afero.Fs, fp string, profiler profile.Profiler) error {
+	f, err := fs.Create(fp)
+	if err != nil {
+		return err
+	}
+
+	if err = profiler.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
-	return
+func writeCoverage(fs afero.Fs, fp string, p *cover.Profile) error {
+	f, err := fs.Create(fp)
+	if err != nil {
+		return err
+	}
+
+	if err = p.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
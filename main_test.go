@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestExecuteFailure(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	input := "func f(x) {\n    return x / 0;\n};\nvar y = f(1);\n"
+	if err := afero.WriteFile(fs, "fail.yum", []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, errs := module.Load(fs, "fail.yum")
+	if len(errs) != 0 {
+		t.Fatalf("failed to load test program: %v", errs)
+	}
+
+	if _, err := execute(fs, prog, nil, "fail.prof", "fail.cover"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the profile and the coverage are written, although the program ended with an error
+	for _, fp := range []string{"fail.prof", "fail.cover"} {
+		if info, err := fs.Stat(fp); err != nil || info.Size() == 0 {
+			t.Errorf("expected %v to be written", fp)
+		}
+	}
+
+	// the statement that raised the error is counted as run
+	cover, err := afero.ReadFile(fs, "fail.cover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "mode: count\nfail.yum:2 stmt 1 1\nfail.yum:4 stmt 1 1\n"; string(cover) != expected {
+		t.Errorf("expected coverage\n%v\ngot\n%v", expected, string(cover))
+	}
+}
@@ -0,0 +1,148 @@
+package module
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/parser"
+	"fmt"
+	"github.com/spf13/afero"
+	"path/filepath"
+	"strings"
+)
+
+// Extension is added to import paths that do not have one
+const Extension = ".yum"
+
+type loader struct {
+	fs      afero.Fs
+	modules map[string]*ast.Program // by path, so that each module is parsed once
+	loading []string                // the chain of imports being resolved, to detect cycles
+	errs    []error
+}
+
+// Load parses the program at fp and the modules it imports, see Resolve
+func Load(fs afero.Fs, fp string) (*ast.Program, []error) {
+	prog, errs := parse(fs, fp)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	if errs = Resolve(fs, prog); len(errs) != 0 {
+		return nil, errs
+	}
+	return prog, nil
+}
+
+// Resolve sets the Program of each of prog's import statements, parsing the imported files and their own imports.
+// Paths are relative to the importing file, and a module imported more than once is shared
+func Resolve(fs afero.Fs, prog *ast.Program) []error {
+	ld := &loader{
+		fs:      fs,
+		modules: make(map[string]*ast.Program),
+		loading: make([]string, 0),
+		errs:    make([]error, 0),
+	}
+
+	fp := filepath.Clean(prog.FileName())
+	ld.modules[fp] = prog
+	ld.resolve(fp, prog)
+	return ld.errs
+}
+
+// Programs returns prog and every module it imports, directly or not, each once and breadth first
+func Programs(prog *ast.Program) []*ast.Program {
+	progs := []*ast.Program{prog}
+	seen := map[*ast.Program]bool{prog: true}
+	for i := 0; i < len(progs); i++ {
+		for _, stmt := range progs[i].Statements {
+			if importStmt, ok := stmt.(*ast.ImportStatement); ok && importStmt.Program != nil &&
+				!seen[importStmt.Program] {
+				seen[importStmt.Program] = true
+				progs = append(progs, importStmt.Program)
+			}
+		}
+	}
+	return progs
+}
+
+// Path returns the file imported by the import statement stmt of the file at fp
+func Path(fp string, stmt *ast.ImportStatement) string {
+	path := filepath.FromSlash(stmt.Path)
+	if filepath.Ext(path) == "" {
+		path += Extension
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(fp), path)
+	}
+	return filepath.Clean(path)
+}
+
+func (ld *loader) resolve(fp string, prog *ast.Program) {
+	ld.loading = append(ld.loading, fp)
+	defer func() {
+		ld.loading = ld.loading[:len(ld.loading)-1]
+	}()
+
+	// imports must be at the top level, those nested in blocks are reported by the semantic analyser
+	for _, stmt := range prog.Statements {
+		importStmt, ok := stmt.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+
+		path := Path(fp, importStmt)
+		for i, loading := range ld.loading {
+			if loading == path {
+				cycle := strings.Join(append(ld.loading[i:], path), " -> ")
+				ld.errs = append(ld.errs, internal.NewError(importStmt, fmt.Sprintf(internal.ErrImportCycle, cycle),
+					internal.SemanticErr))
+				return
+			}
+		}
+
+		if m, ok := ld.modules[path]; ok {
+			importStmt.Program = m
+			continue
+		}
+
+		m, errs := parse(ld.fs, path)
+		if len(errs) != 0 {
+			if _, err := ld.fs.Stat(path); err != nil {
+				errs = []error{internal.NewError(importStmt, fmt.Sprintf(internal.ErrImportNotFound, importStmt.Path, err),
+					internal.SemanticErr)}
+			}
+			ld.errs = append(ld.errs, errs...)
+			continue
+		}
+
+		ld.modules[path] = m
+		importStmt.Program = m
+		ld.resolve(path, m)
+	}
+}
+
+func parse(fs afero.Fs, fp string) (*ast.Program, []error) {
+	f, err := fs.Open(fp)
+	if err != nil {
+		return nil, []error{fmt.Errorf(internal.ErrFailedToReadFile, fp, err)}
+	}
+
+	l, err := lexer.NewLexer(f)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer l.Close()
+
+	p, err := parser.NewRecursiveDescentParser(l)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	prog, errs := p.Parse()
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return prog, nil
+}
@@ -0,0 +1,228 @@
+package module
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/semantic"
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for fp, src := range files {
+		if err := afero.WriteFile(fs, fp, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fs
+}
+
+func TestLoad(t *testing.T) {
+	fs := writeFiles(t, map[string]string{
+		"main.yum":     "import \"lib/math.yum\";\nimport \"lib/util\" as u;\nprint(math.square(u.double(2)));\n",
+		"lib/math.yum": "import \"util\";\nexport func square(x) {\n    return x * x;\n};\n",
+		"lib/util.yum": "export func double(x) {\n    return x * 2;\n};\n",
+	})
+
+	prog, errs := Load(fs, "main.yum")
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	progs := Programs(prog)
+	if len(progs) != 3 {
+		t.Fatalf("expected 3 programs, got %v", len(progs))
+	}
+
+	// util is imported by main and math, but parsed once
+	util := prog.Statements[1].(*ast.ImportStatement).Program
+	if util.FileName() != "lib/util.yum" {
+		t.Errorf("expected lib/util.yum, got %v", util.FileName())
+	}
+	if math := prog.Statements[0].(*ast.ImportStatement).Program; math.Statements[0].(*ast.ImportStatement).Program != util {
+		t.Error("expected lib/util.yum to be shared")
+	}
+
+	if errs := semantic.NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+		t.Errorf("expected no semantic errors, got %v", errs)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tCs := []struct {
+		files  map[string]string
+		errMsg string
+	}{
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\n",
+				"b.yum": "import \"c\";\n",
+				"c.yum": "import \"a\";\n",
+			},
+			"semantic error c.yum 1 | import cycle a.yum -> b.yum -> c.yum -> a.yum",
+		},
+		{
+			map[string]string{
+				"a.yum": "\nimport \"missing\";\n",
+			},
+			"semantic error a.yum 2 | unable to import missing",
+		},
+		{
+			map[string]string{
+				"a.yum":     "import \"lib/b\";\n",
+				"lib/b.yum": "var x = ;\n",
+			},
+			"syntax error lib/b.yum 1",
+		},
+	}
+
+	for i, tC := range tCs {
+		_, errs := Load(writeFiles(t, tC.files), "a.yum")
+		if len(errs) != 1 {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest, i+1, 1, len(errs))
+			continue
+		}
+
+		if !strings.HasPrefix(errs[0].Error(), tC.errMsg) {
+			t.Errorf("test case %v | expected error starting %q, received %q", i+1, tC.errMsg, errs[0].Error())
+		}
+	}
+}
+
+func TestAnalyseModules(t *testing.T) {
+	tCs := []struct {
+		files   map[string]string
+		errMsgs []string
+	}{
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\nb.missing();\nprint(c.x);\n",
+				"b.yum": "var x = 1;\n",
+			},
+			[]string{"semantic error a.yum 2", "semantic error a.yum 3"},
+		},
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\n",
+				"b.yum": "\nprint(y);\n",
+			},
+			[]string{"semantic error b.yum 2"},
+		},
+		{
+			map[string]string{
+				"a.yum":     "import \"b\";\nimport \"lib/b\";\n",
+				"b.yum":     "var x = 1;\n",
+				"lib/b.yum": "var x = 2;\n",
+			},
+			[]string{"semantic error a.yum 2"}, // both are named b
+		},
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\nprint(b.x, b.y);\nb.f();\nb.g();\n",
+				"b.yum": "var x = 1;\nexport var y = 2;\nfunc f() {};\nexport func g() {};\n",
+			},
+			[]string{"semantic error a.yum 2 | x is not exported by b", "semantic error a.yum 3 | f is not exported by b"},
+		},
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\n",
+				"b.yum": "func f() {\n    export var x = 1;\n};\nif (true) {\n    export func g() {};\n};\n",
+			},
+			[]string{"semantic error b.yum 2", "semantic error b.yum 5"},
+		},
+		{
+			map[string]string{
+				"a.yum": "import \"m\";\nstruct P { x };\nvar m = P{x: 1};\nprint(m.x);\n",
+				"m.yum": "export var x = 2;\n",
+			},
+			[]string{"semantic error a.yum 3 | m is the name of an imported module"},
+		},
+		{
+			map[string]string{
+				"a.yum": "import \"b\";\nfunc f(b) {\n    return b;\n};\ntry {\n} catch (b) {\n};\n",
+				"b.yum": "var x = 1;\n",
+			},
+			[]string{"semantic error a.yum 2", "semantic error a.yum 6"}, // parameters and caught errors are variables
+		},
+	}
+
+	for i, tC := range tCs {
+		prog, errs := Load(writeFiles(t, tC.files), "a.yum")
+		if len(errs) != 0 {
+			t.Fatalf("test case %v | expected no errors, got %v", i+1, errs)
+		}
+
+		errs = semantic.NewSemanticAnalyser().Analyse(prog)
+		if len(errs) != len(tC.errMsgs) {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest, i+1, len(tC.errMsgs), len(errs))
+			continue
+		}
+
+		for j, err := range errs {
+			if !strings.HasPrefix(err.Error(), tC.errMsgs[j]) {
+				t.Errorf("test case %v | expected error starting %q, received %q", i+1, tC.errMsgs[j], err.Error())
+			}
+		}
+	}
+}
+
+func TestEvaluateModules(t *testing.T) {
+	fs := writeFiles(t, map[string]string{
+		"a.yum": "import \"b\";\nimport \"c\";\nb.fail(c.zero);\n",
+		"b.yum": "export var count = 0;\nexport func fail(x) {\n    var y = 1;\n    return y / x;\n};\n",
+		"c.yum": "import \"b\";\nexport var zero = b.count;\n",
+	})
+
+	prog, errs := Load(fs, "a.yum")
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if errs = semantic.NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+		t.Fatalf("expected no semantic errors, got %v", errs)
+	}
+
+	// the division by zero is reported in the file of the function that divides
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = r.(error)
+			}
+		}()
+		eval.NewEvaluator(eval.WithRecoverableErrors()).Evaluate(prog)
+		return nil
+	}()
+
+	if err == nil || !strings.HasPrefix(err.Error(), "runtime error b.yum 4") {
+		t.Errorf("expected a runtime error in b.yum, got %v", err)
+	}
+}
+
+func TestModuleStructs(t *testing.T) {
+	fs := writeFiles(t, map[string]string{
+		"a.yum": "import \"b\";\nstruct P { x };\nvar e = P(1) == b.p;\nvar f = b.p == b.p;\n",
+		"b.yum": "struct P { x };\nexport var p = P(1);\n",
+	})
+
+	prog, errs := Load(fs, "a.yum")
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if errs = semantic.NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+		t.Fatalf("expected no semantic errors, got %v", errs)
+	}
+
+	e := eval.NewEvaluator()
+	e.Evaluate(prog)
+
+	// structs declared by different modules are different structs, even if they have the same name
+	globals := e.Frames()[0][0]
+	if v := globals["e"]; v == nil || v.Literal() != "false" {
+		t.Errorf("expected structs of different modules not to be equal, got %v", v)
+	}
+	if v := globals["f"]; v == nil || v.Literal() != "true" {
+		t.Errorf("expected a struct to equal itself, got %v", v)
+	}
+}
@@ -0,0 +1,36 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+)
+
+var annotationObjectTypes = map[string]ObjectType{
+	ast.IntTypeName:    IntegerObject,
+	ast.FloatTypeName:  FloatingPointObject,
+	ast.BoolTypeName:   BooleanObject,
+	ast.StringTypeName: StringObject,
+	ast.NullTypeName:   NullObject,
+}
+
+// MatchesAnnotation reports whether o is a value of the annotated type. Missing annotations match every value
+func MatchesAnnotation(o Object, ta *ast.TypeAnnotation) bool {
+	if ta == nil || ta.Name == ast.AnyTypeName {
+		return true
+	}
+
+	if ta.IsArray() {
+		arr, ok := o.(*ArrayNode)
+		if !ok {
+			return false
+		}
+		for _, e := range arr.Data {
+			if !MatchesAnnotation(e, ta.Elem) {
+				return false
+			}
+		}
+		return true
+	}
+
+	t, ok := annotationObjectTypes[ta.Name]
+	return ok && o.Type() == t
+}
@@ -0,0 +1,72 @@
+package object
+
+import (
+	"sort"
+	"strings"
+)
+
+// Capability names a class of host resource that a native function touches
+type Capability string
+
+const (
+	IOCapability     Capability = "io"
+	FSCapability     Capability = "fs"
+	TimeCapability   Capability = "time"
+	RandomCapability Capability = "random"
+	EnvCapability    Capability = "env"
+)
+
+var AllCapabilities = NewCapabilitySet(IOCapability, FSCapability, TimeCapability, RandomCapability, EnvCapability)
+
+// CapabilitySet is the set of capabilities granted to a script by its host
+type CapabilitySet map[Capability]bool
+
+func NewCapabilitySet(cs ...Capability) CapabilitySet {
+	set := make(CapabilitySet, len(cs))
+	for _, c := range cs {
+		set[c] = true
+	}
+	return set
+}
+
+// ParseCapabilitySet converts a comma separated list of capability names into a set,
+// returning the first unknown name if one is present
+func ParseCapabilitySet(s string) (set CapabilitySet, unknown string, ok bool) {
+	set = NewCapabilitySet()
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !AllCapabilities.Grants(Capability(name)) {
+			return nil, name, false
+		}
+		set[Capability(name)] = true
+	}
+	return set, "", true
+}
+
+func (cs CapabilitySet) Grants(c Capability) bool {
+	return cs[c]
+}
+
+// Missing returns the first capability required by nf that has not been granted
+func (cs CapabilitySet) Missing(nf *NativeFunction) (c Capability, ok bool) {
+	for _, c = range nf.Capabilities {
+		if !cs.Grants(c) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+func (cs CapabilitySet) String() string {
+	names := make([]string, 0, len(cs))
+	for c, granted := range cs {
+		if granted {
+			names = append(names, string(c))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,141 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"errors"
+	"fmt"
+	"github.com/spf13/afero"
+	"os"
+	"strings"
+)
+
+const (
+	filePerm = 0644
+	dirPerm  = 0755
+)
+
+// NewFileFunctions returns the native functions that read and write files, all of which act on fs. A host may restrict
+// scripts to part of its file system by passing an afero.NewBasePathFs, or prevent writes with an afero.NewReadOnlyFs
+func NewFileFunctions(fs afero.Fs) map[string]*NativeFunction {
+	fns := []*NativeFunction{
+		NewNativeFunction("readFile", 1, func(o ...Object) (r Object, err error) {
+			var b []byte
+			if err = fileOperation("readFile", o, func(args []string) (err error) {
+				b, err = afero.ReadFile(fs, args[0])
+				return
+			}); err == nil {
+				r = NewString(string(b))
+			}
+			return
+		}, FSCapability),
+
+		NewNativeFunction("writeFile", 2, func(o ...Object) (r Object, err error) {
+			err = fileOperation("writeFile", o, func(args []string) error {
+				return afero.WriteFile(fs, args[0], []byte(args[1]), filePerm)
+			})
+			return NewNull(), err
+		}, FSCapability),
+
+		NewNativeFunction("appendFile", 2, func(o ...Object) (r Object, err error) {
+			err = fileOperation("appendFile", o, func(args []string) error {
+				f, err := fs.OpenFile(args[0], os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+				if err != nil {
+					return err
+				}
+				if _, err = f.WriteString(args[1]); err != nil {
+					f.Close()
+					return err
+				}
+				return f.Close()
+			})
+			return NewNull(), err
+		}, FSCapability),
+
+		// lines are split on \n or \r\n, and a trailing newline does not start another line
+		NewNativeFunction("readLines", 1, func(o ...Object) (r Object, err error) {
+			var b []byte
+			if err = fileOperation("readLines", o, func(args []string) (err error) {
+				b, err = afero.ReadFile(fs, args[0])
+				return
+			}); err != nil {
+				return
+			}
+
+			lines := make([]Object, 0)
+			if s := strings.TrimSuffix(strings.Replace(string(b), "\r\n", "\n", -1), "\n"); s != "" {
+				for _, line := range strings.Split(s, "\n") {
+					lines = append(lines, NewString(line))
+				}
+			}
+			return NewArrayNode(lines), nil
+		}, FSCapability),
+
+		NewNativeFunction("exists", 1, func(o ...Object) (r Object, err error) {
+			var ok bool
+			if err = fileOperation("exists", o, func(args []string) (err error) {
+				ok, err = afero.Exists(fs, args[0])
+				return
+			}); err == nil {
+				r = NewBoolean(ok)
+			}
+			return
+		}, FSCapability),
+
+		// names are sorted, and do not include the directory
+		NewNativeFunction("listDir", 1, func(o ...Object) (r Object, err error) {
+			var infos []os.FileInfo
+			if err = fileOperation("listDir", o, func(args []string) (err error) {
+				infos, err = afero.ReadDir(fs, args[0])
+				return
+			}); err != nil {
+				return
+			}
+
+			names := make([]Object, len(infos))
+			for i, info := range infos {
+				names[i] = NewString(info.Name())
+			}
+			return NewArrayNode(names), nil
+		}, FSCapability),
+
+		// creates any missing parents, and succeeds if the directory already exists
+		NewNativeFunction("mkdir", 1, func(o ...Object) (r Object, err error) {
+			err = fileOperation("mkdir", o, func(args []string) error {
+				return fs.MkdirAll(args[0], dirPerm)
+			})
+			return NewNull(), err
+		}, FSCapability),
+
+		// removes a file or an empty directory
+		NewNativeFunction("remove", 1, func(o ...Object) (r Object, err error) {
+			err = fileOperation("remove", o, func(args []string) error {
+				return fs.Remove(args[0])
+			})
+			return NewNull(), err
+		}, FSCapability),
+	}
+
+	functions := make(map[string]*NativeFunction, len(fns))
+	for _, f := range fns {
+		functions[f.Name] = f
+	}
+	return functions
+}
+
+// fileOperation checks that the arguments of the native function name are strings, then runs op with them. Errors
+// are prefixed with name, as those of the file system do not say which function failed
+func fileOperation(name string, o []Object, op func(args []string) error) error {
+	args := make([]string, len(o))
+	for i, arg := range o {
+		s, ok := arg.(*String)
+		if !ok {
+			return errors.New(fmt.Sprintf(internal.ErrType, arg.Literal(), StringObject))
+		}
+		args[i] = s.Lit
+	}
+
+	if err := op(args); err != nil {
+		return errors.New(fmt.Sprintf(internal.ErrFileOperation, name, err))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package object
+
+import (
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+func TestFileFunctions(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fns := NewFileFunctions(fs)
+
+	call := func(name string, args ...Object) (Object, error) {
+		return fns[name].Function(args...)
+	}
+
+	tCs := []struct {
+		name     string
+		args     []Object
+		expected string // the literal of the result, ignored if an error is expected
+		errMsg   string
+	}{
+		{"exists", []Object{NewString("dir")}, "false", ""},
+		{"mkdir", []Object{NewString("dir/sub")}, "null", ""},
+		{"mkdir", []Object{NewString("dir/sub")}, "null", ""}, // already exists
+		{"writeFile", []Object{NewString("dir/a.txt"), NewString("one")}, "null", ""},
+		{"appendFile", []Object{NewString("dir/a.txt"), NewString("\r\ntwo\n")}, "null", ""},
+		{"appendFile", []Object{NewString("dir/b.txt"), NewString("")}, "null", ""}, // created
+		{"readFile", []Object{NewString("dir/a.txt")}, "\"one\r\ntwo\n\"", ""},
+		{"readLines", []Object{NewString("dir/a.txt")}, "[\"one\",\"two\"]", ""},
+		{"readLines", []Object{NewString("dir/b.txt")}, "[]", ""},
+		{"exists", []Object{NewString("dir/a.txt")}, "true", ""},
+		{"listDir", []Object{NewString("dir")}, "[\"a.txt\",\"b.txt\",\"sub\"]", ""},
+		{"remove", []Object{NewString("dir/sub")}, "null", ""},
+		{"listDir", []Object{NewString("dir")}, "[\"a.txt\",\"b.txt\"]", ""},
+		{"readFile", []Object{NewString("missing.txt")}, "", "readFile failed"},
+		{"writeFile", []Object{NewString("dir/c.txt"), NewInteger(1)}, "", "1 not of type string"},
+	}
+
+	for i, tC := range tCs {
+		o, err := call(tC.name, tC.args...)
+		if tC.errMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tC.errMsg) {
+				t.Errorf("test case %v | expected error containing %q, received %v", i+1, tC.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+		if o.Literal() != tC.expected {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.expected, o.Literal())
+		}
+	}
+}
+
+func TestFileFunctionsRestricted(t *testing.T) {
+	base := afero.NewMemMapFs()
+	if err := afero.WriteFile(base, "root/a.txt", []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fns := NewFileFunctions(afero.NewReadOnlyFs(afero.NewBasePathFs(base, "root")))
+
+	if o, err := fns["readFile"].Function(NewString("a.txt")); err != nil || o.Literal() != "\"a\"" {
+		t.Errorf("expected \"a\", received %v %v", o, err)
+	}
+
+	if _, err := fns["writeFile"].Function(NewString("b.txt"), NewString("b")); err == nil {
+		t.Error("expected writes to a read only file system to fail")
+	}
+
+	if _, err := fns["readFile"].Function(NewString("../outside.txt")); err == nil {
+		t.Error("expected paths outside the base path to fail")
+	}
+}
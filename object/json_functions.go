@@ -0,0 +1,198 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NewJSONFunctions returns the native functions that convert between objects and JSON. Objects decode to maps, whose
+// keys keep the order they appear in, numbers with a fraction or exponent decode to floats and all others to integers
+func NewJSONFunctions() map[string]*NativeFunction {
+	fns := []*NativeFunction{
+		NewNativeFunction("jsonParse", 1, func(o ...Object) (Object, error) {
+			s, ok := o[0].(*String)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), StringObject))
+			}
+
+			dec := json.NewDecoder(strings.NewReader(s.Lit))
+			dec.UseNumber()
+			v, err := decodeJSON(dec)
+			if err == nil {
+				// only a single value may be decoded
+				if _, err = dec.Token(); err == io.EOF {
+					return v, nil
+				} else if err == nil {
+					err = errors.New("unexpected data after the top level value")
+				}
+			}
+			if err == io.EOF {
+				err = errors.New("unexpected end of JSON input")
+			}
+			return nil, errors.New(fmt.Sprintf(internal.ErrInvalidJSON, err))
+		}),
+
+		// an indent greater than zero puts each element of an array or map on its own line, indented by that many spaces
+		// per level, otherwise the JSON is compact
+		NewNativeFunction("jsonStringify", 2, func(o ...Object) (Object, error) {
+			indent, ok := o[1].(*Integer)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf(internal.ErrType, o[1].Literal(), IntegerObject))
+			}
+
+			buff := bytes.Buffer{}
+			if err := encodeJSON(&buff, o[0], make(map[*Struct]bool)); err != nil {
+				return nil, err
+			}
+			if indent.Value <= 0 {
+				return NewString(buff.String()), nil
+			}
+
+			indented := bytes.Buffer{}
+			if err := json.Indent(&indented, buff.Bytes(), "", strings.Repeat(" ", int(indent.Value))); err != nil {
+				return nil, err
+			}
+			return NewString(indented.String()), nil
+		}),
+	}
+
+	functions := make(map[string]*NativeFunction, len(fns))
+	for _, f := range fns {
+		functions[f.Name] = f
+	}
+	return functions
+}
+
+// decodeJSON decodes the next value of dec. Tokens are read one at a time, rather than unmarshalled into an interface,
+// so that the order of keys is kept
+func decodeJSON(dec *json.Decoder) (Object, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '[' {
+			data := make([]Object, 0)
+			for dec.More() {
+				e, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				data = append(data, e)
+			}
+			_, err = dec.Token() // ]
+			return NewArrayNode(data), err
+		}
+
+		m := NewMap()
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			e, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(k.(string), e)
+		}
+		_, err = dec.Token() // }
+		return m, err
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if i, err := v.Int64(); err == nil {
+				return NewInteger(i), nil
+			}
+		}
+		// integers too large for an int64 lose precision rather than failing
+		f, err := v.Float64()
+		return NewFloat(f), err
+	case string:
+		return NewString(v), nil
+	case bool:
+		return NewBoolean(v), nil
+	default:
+		return NewNull(), nil
+	}
+}
+
+// encodeJSON writes o to buff as compact JSON. A struct in encoding contains o, so can not be represented
+func encodeJSON(buff *bytes.Buffer, o Object, encoding map[*Struct]bool) error {
+	switch v := o.(type) {
+	case *Integer:
+		buff.WriteString(strconv.FormatInt(v.Value, 10))
+	case *Float:
+		if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+			return errors.New(fmt.Sprintf(internal.ErrJSONType, v.Literal()))
+		}
+		// the literal keeps a fraction or exponent, so that it decodes to a float
+		buff.WriteString(v.Literal())
+	case *Boolean:
+		buff.WriteString(strconv.FormatBool(v.Value))
+	case *String:
+		writeJSONString(buff, v.Lit)
+	case *Null:
+		buff.WriteString("null")
+	case *ArrayNode:
+		buff.WriteString("[")
+		for i, e := range v.Data {
+			if i != 0 {
+				buff.WriteString(",")
+			}
+			if err := encodeJSON(buff, e, encoding); err != nil {
+				return err
+			}
+		}
+		buff.WriteString("]")
+	case *Map:
+		buff.WriteString("{")
+		for i, k := range v.Keys {
+			if i != 0 {
+				buff.WriteString(",")
+			}
+			writeJSONString(buff, k)
+			buff.WriteString(":")
+			if err := encodeJSON(buff, v.Data[k], encoding); err != nil {
+				return err
+			}
+		}
+		buff.WriteString("}")
+	case *Struct:
+		if encoding[v] {
+			return errors.New(fmt.Sprintf(internal.ErrJSONCycle, v.Name))
+		}
+		encoding[v] = true
+		defer delete(encoding, v)
+
+		buff.WriteString("{")
+		for i, f := range v.Fields {
+			if i != 0 {
+				buff.WriteString(",")
+			}
+			writeJSONString(buff, f)
+			buff.WriteString(":")
+			if err := encodeJSON(buff, v.Values[f], encoding); err != nil {
+				return err
+			}
+		}
+		buff.WriteString("}")
+	default:
+		return errors.New(fmt.Sprintf(internal.ErrJSONType, o.Type()))
+	}
+	return nil
+}
+
+func writeJSONString(buff *bytes.Buffer, s string) {
+	// marshalling a string can not fail
+	b, _ := json.Marshal(s)
+	buff.Write(b)
+}
@@ -0,0 +1,129 @@
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONFunctions(t *testing.T) {
+	fns := NewJSONFunctions()
+
+	m := NewMap()
+	m.Set("b", NewInteger(1))
+	m.Set("a", NewArrayNode([]Object{NewFloat(2), NewNull()}))
+	m.Set("c", NewMap())
+
+	// each representable object type, which is stringified then parsed back into an object with the same literal
+	roundTrips := []struct {
+		o    Object
+		json string
+	}{
+		{NewInteger(-12), "-12"},
+		{NewFloat(1.5), "1.5"},
+		{NewFloat(3), "3.0"},
+		{NewBoolean(true), "true"},
+		{NewString("a \"quoted\"\n\tline"), "\"a \\\"quoted\\\"\\n\\tline\""},
+		{NewNull(), "null"},
+		{NewArrayNode([]Object{}), "[]"},
+		{NewArrayNode([]Object{NewInteger(1), NewString("a"), NewBoolean(false)}), "[1,\"a\",false]"},
+		{NewMap(), "{}"},
+		{m, "{\"b\":1,\"a\":[2.0,null],\"c\":{}}"},
+	}
+
+	for i, tC := range roundTrips {
+		s, err := fns["jsonStringify"].Function(tC.o, NewInteger(0))
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+		if s.(*String).Lit != tC.json {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.json, s.(*String).Lit)
+		}
+
+		o, err := fns["jsonParse"].Function(s)
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+		if o.Type() != tC.o.Type() || o.Literal() != tC.o.Literal() {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.o.Literal(), o.Literal())
+		}
+	}
+
+	// the remaining object types can not be represented
+	for _, o := range []Object{
+		NewReturnValue(NewInteger(1)),
+		NewUserFunction("f", []string{}, nil),
+		NewNativeFunction("g", 0, nil),
+		NewArrayNode([]Object{NewUserFunction("f", []string{}, nil)}),
+	} {
+		if _, err := fns["jsonStringify"].Function(o, NewInteger(0)); err == nil ||
+			!strings.HasSuffix(err.Error(), "can not be represented as json") {
+			t.Errorf("expected %v not to be represented as json, received %v", o.Type(), err)
+		}
+	}
+
+	// structs are shared, so may contain themselves
+	n := NewStructDefinition("N", []string{"next"}).New()
+	n.Set("next", NewArrayNode([]Object{n}))
+	if _, err := fns["jsonStringify"].Function(n, NewInteger(0)); err == nil ||
+		err.Error() != "N can not be represented as json, as it contains itself" {
+		t.Errorf("expected a struct that contains itself not to be represented as json, received %v", err)
+	}
+}
+
+func TestJSONParse(t *testing.T) {
+	parse := NewJSONFunctions()["jsonParse"]
+
+	tCs := []struct {
+		input    Object
+		expected string // the literal of the result, ignored if an error is expected
+		errMsg   string
+	}{
+		{NewString(" {\"z\": 1, \"a\": {\"b\": [1e2, 10]}, \"z\": true} "), "{\"z\":true,\"a\":{\"b\":[100.0,10]}}", ""},
+		{NewString("92233720368547758070"), "9.223372036854776e+19", ""}, // too large for an integer
+		{NewString(""), "", "invalid json | unexpected end of JSON input"},
+		{NewString("[1, 2"), "", "invalid json | unexpected end of JSON input"},
+		{NewString("{\"a\" 1}"), "", "invalid json | invalid character '1' after object key"},
+		{NewString("1 2"), "", "invalid json | unexpected data after the top level value"},
+		{NewInteger(1), "", "1 not of type string"},
+	}
+
+	for i, tC := range tCs {
+		o, err := parse.Function(tC.input)
+		if tC.errMsg != "" {
+			if err == nil || err.Error() != tC.errMsg {
+				t.Errorf("test case %v | expected error %q, received %v", i+1, tC.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+		if o.Literal() != tC.expected {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.expected, o.Literal())
+		}
+	}
+}
+
+func TestJSONStringifyIndent(t *testing.T) {
+	m := NewMap()
+	m.Set("a", NewArrayNode([]Object{NewInteger(1), NewInteger(2)}))
+	m.Set("b", NewMap())
+
+	s, err := NewJSONFunctions()["jsonStringify"].Function(m, NewInteger(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"
+	if s.(*String).Lit != expected {
+		t.Errorf("expected %q, received %q", expected, s.(*String).Lit)
+	}
+
+	if _, err := NewJSONFunctions()["jsonStringify"].Function(m, NewString("2")); err == nil {
+		t.Error("expected a non integer indent to fail")
+	}
+}
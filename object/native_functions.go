@@ -4,23 +4,35 @@ import (
 	"github.com/EricNRodriguez/yum/internal"
 	"errors"
 	"fmt"
+	"github.com/spf13/afero"
+	"os"
 )
 
 var (
-	print = NewNativeFunction("print", -1, func(os ...Object) (o Object, err error) {
-		for _, o := range os {
-			fmt.Println(o.Literal())
+	length = NewNativeFunction("length", 1, func(o ...Object) (l Object, err error) {
+		switch c := o[0].(type) {
+		case *ArrayNode:
+			l = NewInteger(c.Length)
+		case *Map:
+			l = NewInteger(int64(len(c.Keys)))
+		default:
+			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Type(), ArrayObject))
 		}
-		return NewNull(), nil
+		return
 	})
 
-	length = NewNativeFunction("length", 1, func(o ...Object) (l Object, err error) {
-		if o[0].Type() != ArrayObject {
-			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Type(), ArrayObject))
+	// keys returns the keys of a map in insertion order
+	keys = NewNativeFunction("keys", 1, func(o ...Object) (l Object, err error) {
+		m, ok := o[0].(*Map)
+		if !ok {
+			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Type(), MapObject))
 			return
 		}
-		arr := o[0].(*ArrayNode)
-		l = NewInteger(arr.Length)
+		ks := make([]Object, len(m.Keys))
+		for i, k := range m.Keys {
+			ks[i] = NewString(k)
+		}
+		l = NewArrayNode(ks)
 		return
 	})
 
@@ -29,14 +41,61 @@ var (
 		return
 	})
 
+	// errorFn creates an error with the message msg, which may be returned or thrown. Its line is that of the first
+	// throw statement to throw it
+	errorFn = NewNativeFunction("error", 1, func(o ...Object) (l Object, err error) {
+		msg, ok := o[0].(*String)
+		if !ok {
+			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), StringObject))
+			return
+		}
+		l = NewError(msg.Lit, internal.ThrownErr, 0)
+		return
+	})
+
+	isError = NewNativeFunction("isError", 1, func(o ...Object) (l Object, err error) {
+		l = NewBoolean(o[0].Type() == ErrorObject)
+		return
+	})
+
+	errorMessage = NewNativeFunction("errorMessage", 1, func(o ...Object) (l Object, err error) {
+		e, ok := o[0].(*Error)
+		if !ok {
+			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), ErrorObject))
+			return
+		}
+		l = NewString(e.Message)
+		return
+	})
+
+	// NativeFunctions are available to every program. Its file functions act on the host's file system, its output
+	// functions write to the host's standard output, and its process functions read the host's standard input and have
+	// no arguments, see NewFileFunctions, NewOutputFunctions and NewProcessFunctions
 	NativeFunctions map[string]*NativeFunction
 )
 
 func init() {
 
 	NativeFunctions = map[string]*NativeFunction{
-		print.Name:  print,
 		length.Name: length,
 		isNull.Name: isNull,
+		keys.Name:   keys,
+
+		errorFn.Name:      errorFn,
+		isError.Name:      isError,
+		errorMessage.Name: errorMessage,
+	}
+
+	for name, f := range NewFileFunctions(afero.NewOsFs()) {
+		NativeFunctions[name] = f
+	}
+	for name, f := range NewOutputFunctions(os.Stdout) {
+		NativeFunctions[name] = f
+	}
+	for name, f := range NewProcessFunctions(nil, os.Stdin, os.Stdout) {
+		NativeFunctions[name] = f
+	}
+	for name, f := range NewJSONFunctions() {
+		NativeFunctions[name] = f
 	}
 }
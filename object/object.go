@@ -2,8 +2,10 @@ package object
 
 import (
 	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -71,10 +73,14 @@ func (a *ArrayNode) Type() ObjectType {
 }
 
 func (a *ArrayNode) Literal() string {
+	return a.literal(nil)
+}
+
+func (a *ArrayNode) literal(printing map[*Struct]bool) string {
 	buff := bytes.Buffer{}
 	buff.WriteString("[")
 	for i, o := range a.Data {
-		buff.WriteString(o.Literal())
+		buff.WriteString(literal(o, printing))
 		if i != len(a.Data)-1 {
 			buff.WriteString(",")
 		}
@@ -83,6 +89,58 @@ func (a *ArrayNode) Literal() string {
 	return buff.String()
 }
 
+// Keyed is an object whose values are read by indexing it with a string key
+type Keyed interface {
+	Object
+	Get(k string) (Object, bool)
+}
+
+// Map is an object with string keys, which are kept in insertion order so that its literal is deterministic
+type Map struct {
+	Keys []string
+	Data map[string]Object
+}
+
+func NewMap() *Map {
+	return &Map{
+		Data: make(map[string]Object),
+	}
+}
+
+// Set adds or replaces the value of k, a new key is ordered after the existing keys
+func (m *Map) Set(k string, v Object) {
+	if _, ok := m.Data[k]; !ok {
+		m.Keys = append(m.Keys, k)
+	}
+	m.Data[k] = v
+}
+
+func (m *Map) Get(k string) (Object, bool) {
+	v, ok := m.Data[k]
+	return v, ok
+}
+
+func (m *Map) Type() ObjectType {
+	return MapObject
+}
+
+func (m *Map) Literal() string {
+	return m.literal(nil)
+}
+
+func (m *Map) literal(printing map[*Struct]bool) string {
+	buff := bytes.Buffer{}
+	buff.WriteString("{")
+	for i, k := range m.Keys {
+		buff.WriteString(fmt.Sprintf("\"%v\":%v", k, literal(m.Data[k], printing)))
+		if i != len(m.Keys)-1 {
+			buff.WriteString(",")
+		}
+	}
+	buff.WriteString("}")
+	return buff.String()
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -99,8 +157,13 @@ func (f *Float) Type() ObjectType {
 	return FloatingPointObject
 }
 
+// Literal is the shortest form that parses back to the same value, with a fraction so it is not mistaken for an integer
 func (f *Float) Literal() string {
-	return fmt.Sprintf("%f", f.Value)
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
 }
 
 func NewBoolean(b bool) *Boolean {
@@ -152,16 +215,19 @@ func (r *ReturnValue) Literal() string {
 }
 
 type UserFunction struct {
-	Name       string
-	Parameters []string
-	Body       []ast.Statement
+	Name           string
+	Parameters     []string
+	ParameterTypes []*ast.TypeAnnotation // nil entries for unannotated parameters
+	ReturnType     *ast.TypeAnnotation
+	Body           []ast.Statement
 }
 
 func NewUserFunction(n string, params []string, body []ast.Statement) *UserFunction {
 	return &UserFunction{
-		Name:       n,
-		Parameters: params,
-		Body:       body,
+		Name:           n,
+		Parameters:     params,
+		ParameterTypes: make([]*ast.TypeAnnotation, len(params)),
+		Body:           body,
 	}
 }
 
@@ -180,16 +246,18 @@ func (f *UserFunction) Literal() string {
 }
 
 type NativeFunction struct {
-	Name      string
-	NumParams int // -1 for variadic
-	Function  func(args ...Object) (Object, error)
+	Name         string
+	NumParams    int // -1 for variadic
+	Function     func(args ...Object) (Object, error)
+	Capabilities []Capability // host resources the function requires
 }
 
-func NewNativeFunction(n string, nPs int, f func(args ...Object) (Object, error)) *NativeFunction {
+func NewNativeFunction(n string, nPs int, f func(args ...Object) (Object, error), cs ...Capability) *NativeFunction {
 	return &NativeFunction{
-		Name:      n,
-		NumParams: nPs,
-		Function:  f,
+		Name:         n,
+		NumParams:    nPs,
+		Function:     f,
+		Capabilities: cs,
 	}
 }
 
@@ -200,3 +268,186 @@ func (nf *NativeFunction) Type() ObjectType {
 func (nf *NativeFunction) Literal() string {
 	return fmt.Sprintf("%v", *nf)
 }
+
+// Error is a runtime error, raised by the evaluator or by a program's throw statement. Its fields are read by indexing
+// it with their names: message, type, line and value
+type Error struct {
+	Message string
+	ErrType internal.ErrorType
+	Line    int
+	Value   Object // the value thrown by the program, null for errors raised by the evaluator
+}
+
+func NewError(msg string, t internal.ErrorType, line int) *Error {
+	return &Error{
+		Message: msg,
+		ErrType: t,
+		Line:    line,
+		Value:   NewNull(),
+	}
+}
+
+func (e *Error) Get(k string) (Object, bool) {
+	switch k {
+	case "message":
+		return NewString(e.Message), true
+	case "type":
+		return NewString(e.ErrType.String()), true
+	case "line":
+		return NewInteger(int64(e.Line)), true
+	case "value":
+		return e.Value, true
+	}
+	return nil, false
+}
+
+func (e *Error) Type() ObjectType {
+	return ErrorObject
+}
+
+func (e *Error) Literal() string {
+	return fmt.Sprintf("error(%v)", NewString(e.Message).Literal())
+}
+
+// StructDefinition is a struct declared by a program, its fields are in declaration order
+type StructDefinition struct {
+	Name   string
+	Fields []string
+}
+
+func NewStructDefinition(name string, fields []string) *StructDefinition {
+	return &StructDefinition{
+		Name:   name,
+		Fields: fields,
+	}
+}
+
+// New returns an instance of the struct, each of whose fields is null
+func (d *StructDefinition) New() *Struct {
+	s := &Struct{
+		StructDefinition: d,
+		Values:           make(map[string]Object, len(d.Fields)),
+	}
+	for _, f := range d.Fields {
+		s.Values[f] = NewNull()
+	}
+	return s
+}
+
+func (d *StructDefinition) HasField(f string) bool {
+	for _, df := range d.Fields {
+		if df == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Struct is an instance of a declared struct. Its fields are read by field access, or by indexing it with their names
+type Struct struct {
+	*StructDefinition
+	Values map[string]Object
+}
+
+func (s *Struct) Get(k string) (Object, bool) {
+	v, ok := s.Values[k]
+	return v, ok
+}
+
+// Set replaces the value of field k, returning false if the struct has no such field
+func (s *Struct) Set(k string, v Object) bool {
+	if _, ok := s.Values[k]; !ok {
+		return false
+	}
+	s.Values[k] = v
+	return true
+}
+
+// Equal reports whether o is an instance of the same struct definition, with equal values in each field. Values are
+// equal if they are structs that are themselves equal, arrays of equal elements, or otherwise have the same type and
+// literal. Structs that contain themselves are equal if no field tells them apart
+func (s *Struct) Equal(o *Struct) bool {
+	return s.equal(o, make(map[[2]*Struct]bool))
+}
+
+// equal compares s and o, assuming that the pairs of structs already being compared are equal
+func (s *Struct) equal(o *Struct, comparing map[[2]*Struct]bool) bool {
+	if s == o || comparing[[2]*Struct{s, o}] {
+		return true
+	}
+	if s.StructDefinition != o.StructDefinition {
+		return false
+	}
+
+	comparing[[2]*Struct{s, o}] = true
+	for _, f := range s.Fields {
+		if !equal(s.Values[f], o.Values[f], comparing) {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(v, w Object, comparing map[[2]*Struct]bool) bool {
+	switch v := v.(type) {
+	case *Struct:
+		wS, ok := w.(*Struct)
+		return ok && v.equal(wS, comparing)
+	case *ArrayNode:
+		wA, ok := w.(*ArrayNode)
+		if !ok || len(v.Data) != len(wA.Data) {
+			return false
+		}
+		for i := range v.Data {
+			if !equal(v.Data[i], wA.Data[i], comparing) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.Type() == w.Type() && v.Literal() == w.Literal()
+}
+
+func (s *Struct) Type() ObjectType {
+	return StructObject
+}
+
+func (s *Struct) Literal() string {
+	return s.literal(nil)
+}
+
+// literal prints a struct that is already being printed, as it contains itself, as <cycle>
+func (s *Struct) literal(printing map[*Struct]bool) string {
+	if printing[s] {
+		return "<cycle>"
+	}
+	if printing == nil {
+		printing = make(map[*Struct]bool)
+	}
+	printing[s] = true
+	defer delete(printing, s)
+
+	buff := bytes.Buffer{}
+	buff.WriteString(s.Name + "{")
+	for i, f := range s.Fields {
+		buff.WriteString(fmt.Sprintf("%v: %v", f, literal(s.Values[f], printing)))
+		if i != len(s.Fields)-1 {
+			buff.WriteString(", ")
+		}
+	}
+	buff.WriteString("}")
+	return buff.String()
+}
+
+// literal returns the literal of o, printing the structs in printing, which contain o, as <cycle>
+func literal(o Object, printing map[*Struct]bool) string {
+	switch o := o.(type) {
+	case *Struct:
+		return o.literal(printing)
+	case *ArrayNode:
+		return o.literal(printing)
+	case *Map:
+		return o.literal(printing)
+	}
+	return o.Literal()
+}
@@ -11,5 +11,8 @@ const (
 	UserFunctionObject   = "user function"
 	NativeFunctionObject = "native function"
 	ArrayObject          = "ArrayNode"
+	MapObject            = "map"
+	ErrorObject          = "error"
+	StructObject         = "struct"
 	NullObject           = "null"
 )
@@ -0,0 +1,173 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewOutputFunctions returns the native functions that write a program's output to out, and sprintf, which formats
+// its output as a string
+func NewOutputFunctions(out io.Writer) map[string]*NativeFunction {
+	fns := []*NativeFunction{
+		// print writes its arguments on a single line, separated by spaces
+		NewNativeFunction("print", -1, func(o ...Object) (Object, error) {
+			_, err := fmt.Fprintln(out, join(o))
+			return NewNull(), err
+		}, IOCapability),
+
+		// write is print without the trailing newline
+		NewNativeFunction("write", -1, func(o ...Object) (Object, error) {
+			_, err := fmt.Fprint(out, join(o))
+			return NewNull(), err
+		}, IOCapability),
+
+		NewNativeFunction("printf", -1, func(o ...Object) (Object, error) {
+			s, err := sprintf(o)
+			if err != nil {
+				return nil, err
+			}
+			_, err = fmt.Fprint(out, s)
+			return NewNull(), err
+		}, IOCapability),
+
+		NewNativeFunction("sprintf", -1, func(o ...Object) (Object, error) {
+			s, err := sprintf(o)
+			if err != nil {
+				return nil, err
+			}
+			return NewString(s), nil
+		}),
+	}
+
+	functions := make(map[string]*NativeFunction, len(fns))
+	for _, f := range fns {
+		functions[f.Name] = f
+	}
+	return functions
+}
+
+// Display returns the form of o written by print, which is its literal, except that strings are not quoted
+func Display(o Object) string {
+	if s, ok := o.(*String); ok {
+		return s.Lit
+	}
+	return o.Literal()
+}
+
+// sprintf formats the arguments of printf and sprintf, the first of which is the format
+func sprintf(o []Object) (string, error) {
+	if len(o) == 0 {
+		return "", errors.New(internal.ErrFormatMissing)
+	}
+	format, ok := o[0].(*String)
+	if !ok {
+		return "", errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), StringObject))
+	}
+	return Sprintf(format.Lit, o[1:]...)
+}
+
+func join(o []Object) string {
+	ds := make([]string, len(o))
+	for i, e := range o {
+		ds[i] = Display(e)
+	}
+	return strings.Join(ds, " ")
+}
+
+// Sprintf formats args according to format. Verbs take the flags, width and precision of Go's fmt package, and are
+//
+//	%v  the display form of any value, as written by print
+//	%s  the display form of a string
+//	%q  the quoted literal of a string
+//	%d  an integer, %x and %X in hexadecimal
+//	%f  a float or integer, %e and %g in scientific or the shortest notation
+//	%t  a boolean
+//	%%  a percent sign
+func Sprintf(format string, args ...Object) (string, error) {
+	buff := bytes.Buffer{}
+	n := 0
+	for f := format; f != ""; {
+		i := strings.IndexByte(f, '%')
+		if i == -1 {
+			buff.WriteString(f)
+			break
+		}
+		buff.WriteString(f[:i])
+		f = f[i:]
+
+		// the verb is the first letter after the flags, width and precision
+		end := 1
+		for end < len(f) && strings.IndexByte("+-# 0123456789.", f[end]) != -1 {
+			end++
+		}
+		if end == len(f) {
+			return "", errors.New(fmt.Sprintf(internal.ErrFormatVerb, f))
+		}
+		spec, verb := f[:end+1], f[end]
+		f = f[end+1:]
+
+		if verb == '%' {
+			buff.WriteByte('%')
+			continue
+		}
+		if n == len(args) {
+			return "", errors.New(fmt.Sprintf(internal.ErrFormatArgs, countVerbs(format), len(args)))
+		}
+
+		v, err := formatArg(verb, args[n])
+		if err != nil {
+			return "", err
+		}
+		buff.WriteString(fmt.Sprintf(spec, v))
+		n++
+	}
+
+	if n != len(args) {
+		return "", errors.New(fmt.Sprintf(internal.ErrFormatArgs, n, len(args)))
+	}
+	return buff.String(), nil
+}
+
+// formatArg returns the Go value that verb formats arg as, or an error if the verb does not apply to arg
+func formatArg(verb byte, arg Object) (interface{}, error) {
+	var want ObjectType
+	switch verb {
+	case 'v':
+		return Display(arg), nil
+	case 's', 'q':
+		if s, ok := arg.(*String); ok {
+			return s.Lit, nil
+		}
+		want = StringObject
+	case 'd', 'x', 'X':
+		if i, ok := arg.(*Integer); ok {
+			return i.Value, nil
+		}
+		want = IntegerObject
+	case 'f', 'e', 'g':
+		switch n := arg.(type) {
+		case *Float:
+			return n.Value, nil
+		case *Integer:
+			return float64(n.Value), nil
+		}
+		want = FloatingPointObject
+	case 't':
+		if b, ok := arg.(*Boolean); ok {
+			return b.Value, nil
+		}
+		want = BooleanObject
+	default:
+		return nil, errors.New(fmt.Sprintf(internal.ErrFormatVerb, "%"+string(verb)))
+	}
+	return nil, errors.New(fmt.Sprintf(internal.ErrFormatType, "%"+string(verb), arg.Literal(), want))
+}
+
+// countVerbs returns the number of arguments format requires, assuming each verb is valid
+func countVerbs(format string) int {
+	return strings.Count(format, "%") - 2*strings.Count(format, "%%")
+}
@@ -0,0 +1,79 @@
+package object
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputFunctions(t *testing.T) {
+	out := bytes.Buffer{}
+	fns := NewOutputFunctions(&out)
+
+	arr := NewArrayNode([]Object{NewString("a"), NewFloat(0.1)})
+
+	tCs := []struct {
+		name     string
+		args     []Object
+		expected string // what is written to out, or the literal of the result of sprintf
+		errMsg   string
+	}{
+		{"print", []Object{NewString("a b"), NewInteger(1), NewFloat(2.5), arr}, "a b 1 2.5 [\"a\",0.1]\n", ""},
+		{"print", []Object{}, "\n", ""},
+		{"write", []Object{NewString("no"), NewString("newline")}, "no newline", ""},
+		{"printf", []Object{NewString("%v=%5.2f|%-3d|%t\n"), NewString("x"), NewFloat(3.14159), NewInteger(7), TrueConst}, "x= 3.14|7  |true\n", ""},
+		{"printf", []Object{NewString("100%%")}, "100%", ""},
+		{"sprintf", []Object{NewString("%q %s %x %e"), NewString("a"), NewString("b"), NewInteger(255), NewInteger(1)}, "\"\"a\" b ff 1.000000e+00\"", ""},
+		{"sprintf", []Object{NewString("%v %v"), arr, NewNull()}, "\"[\"a\",0.1] null\"", ""},
+		{"sprintf", []Object{}, "", "a format string is required"},
+		{"sprintf", []Object{NewInteger(1)}, "", "1 not of type string"},
+		{"sprintf", []Object{NewString("%d %d"), NewInteger(1)}, "", "format requires 2 arguments, 1 given"},
+		{"sprintf", []Object{NewString("%d"), NewInteger(1), NewInteger(2)}, "", "format requires 1 arguments, 2 given"},
+		{"sprintf", []Object{NewString("%d"), NewString("1")}, "", "%d can not format \"1\", which is not of type integer"},
+		{"sprintf", []Object{NewString("%y"), NewInteger(1)}, "", "%y is not a valid format verb"},
+		{"sprintf", []Object{NewString("%5")}, "", "%5 is not a valid format verb"},
+	}
+
+	for i, tC := range tCs {
+		out.Reset()
+		o, err := fns[tC.name].Function(tC.args...)
+		if tC.errMsg != "" {
+			if err == nil || err.Error() != tC.errMsg {
+				t.Errorf("test case %v | expected error %q, received %v", i+1, tC.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+
+		received := out.String()
+		if tC.name == "sprintf" {
+			received = o.Literal()
+		}
+		if received != tC.expected {
+			t.Errorf("test case %v | expected %q, received %q", i+1, tC.expected, received)
+		}
+	}
+}
+
+func TestFloatLiteral(t *testing.T) {
+	tCs := []struct {
+		f        float64
+		expected string
+	}{
+		{2.5, "2.5"},
+		{3, "3.0"},
+		{-0.1, "-0.1"},
+		{1.0 / 3, "0.3333333333333333"},
+		{1e21, "1e+21"},
+		{1e-7, "1e-07"},
+	}
+
+	for i, tC := range tCs {
+		if l := NewFloat(tC.f).Literal(); l != tC.expected {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.expected, l)
+		}
+	}
+}
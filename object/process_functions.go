@@ -0,0 +1,86 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExitError is returned by the exit native function, ending the program with Code as its exit status
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf(internal.ErrExit, e.Code)
+}
+
+// NewProcessFunctions returns the native functions that give a program its command line arguments, its environment
+// and its standard input, which is read from in. Prompts for input are written to out
+func NewProcessFunctions(args []string, in io.Reader, out io.Writer) map[string]*NativeFunction {
+	r := bufio.NewReader(in)
+
+	// readLine returns the next line of input without its line ending, or null once the input is exhausted
+	readLine := func() (Object, error) {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF && line == "" {
+			return NewNull(), nil
+		}
+		return NewString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), nil
+	}
+
+	fns := []*NativeFunction{
+		NewNativeFunction("args", 0, func(o ...Object) (Object, error) {
+			arr := make([]Object, len(args))
+			for i, arg := range args {
+				arr[i] = NewString(arg)
+			}
+			return NewArrayNode(arr), nil
+		}),
+
+		// env returns null for variables that are not set
+		NewNativeFunction("env", 1, func(o ...Object) (Object, error) {
+			name, ok := o[0].(*String)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), StringObject))
+			}
+			if v, ok := os.LookupEnv(name.Lit); ok {
+				return NewString(v), nil
+			}
+			return NewNull(), nil
+		}, EnvCapability),
+
+		// input writes the prompt, without a newline, then reads a line
+		NewNativeFunction("input", 1, func(o ...Object) (Object, error) {
+			if _, err := fmt.Fprint(out, Display(o[0])); err != nil {
+				return nil, err
+			}
+			return readLine()
+		}, IOCapability),
+
+		NewNativeFunction("readLine", 0, func(o ...Object) (Object, error) {
+			return readLine()
+		}, IOCapability),
+
+		NewNativeFunction("exit", 1, func(o ...Object) (Object, error) {
+			code, ok := o[0].(*Integer)
+			if !ok {
+				return nil, errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), IntegerObject))
+			}
+			return nil, &ExitError{Code: int(code.Value)}
+		}),
+	}
+
+	functions := make(map[string]*NativeFunction, len(fns))
+	for _, f := range fns {
+		functions[f.Name] = f
+	}
+	return functions
+}
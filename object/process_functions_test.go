@@ -0,0 +1,62 @@
+package object
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProcessFunctions(t *testing.T) {
+	if err := os.Setenv("YUM_TEST_VAR", "value"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("YUM_TEST_VAR")
+
+	out := bytes.Buffer{}
+	fns := NewProcessFunctions([]string{"a", "b c"}, strings.NewReader("first\r\nsecond\nlast"), &out)
+
+	tCs := []struct {
+		name     string
+		args     []Object
+		expected string // the literal of the result, ignored if an error is expected
+		errMsg   string
+	}{
+		{"args", []Object{}, "[\"a\",\"b c\"]", ""},
+		{"env", []Object{NewString("YUM_TEST_VAR")}, "\"value\"", ""},
+		{"env", []Object{NewString("YUM_TEST_UNSET_VAR")}, "null", ""},
+		{"env", []Object{NewInteger(1)}, "", "1 not of type string"},
+		{"readLine", []Object{}, "\"first\"", ""},
+		{"input", []Object{NewString("> ")}, "\"second\"", ""},
+		{"readLine", []Object{}, "\"last\"", ""}, // without a line ending
+		{"readLine", []Object{}, "null", ""},
+		{"exit", []Object{NewInteger(2)}, "", "exit status 2"},
+		{"exit", []Object{NewString("2")}, "", "\"2\" not of type integer"},
+	}
+
+	for i, tC := range tCs {
+		o, err := fns[tC.name].Function(tC.args...)
+		if tC.errMsg != "" {
+			if err == nil || err.Error() != tC.errMsg {
+				t.Errorf("test case %v | expected error %q, received %v", i+1, tC.errMsg, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test case %v | unexpected error %v", i+1, err)
+			continue
+		}
+		if o.Literal() != tC.expected {
+			t.Errorf("test case %v | expected %v, received %v", i+1, tC.expected, o.Literal())
+		}
+	}
+
+	if out.String() != "> " {
+		t.Errorf("expected the prompt \"> \" to be written, received %q", out.String())
+	}
+
+	if _, err := fns["exit"].Function(NewInteger(4)); err.(*ExitError).Code != 4 {
+		t.Errorf("expected exit status 4, received %v", err)
+	}
+}
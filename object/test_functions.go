@@ -0,0 +1,64 @@
+package object
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"errors"
+	"fmt"
+)
+
+// TestFailure is returned by the test functions when a test fails. Unlike a runtime error, it is not caught by a try
+// statement, so a test can not swallow its own failure
+type TestFailure struct {
+	Message string
+}
+
+func (f *TestFailure) Error() string {
+	return f.Message
+}
+
+var (
+	assert = NewNativeFunction("assert", 1, func(o ...Object) (r Object, err error) {
+		if o[0].Type() != BooleanObject {
+			err = errors.New(fmt.Sprintf(internal.ErrType, o[0].Literal(), BooleanObject))
+			return
+		}
+		if !o[0].(*Boolean).Value {
+			err = &TestFailure{internal.ErrAssertionFailed}
+			return
+		}
+		r = NewNull()
+		return
+	})
+
+	// values are equal if they have the same type and literal, so arrays are compared element by element
+	assertEqual = NewNativeFunction("assertEqual", 2, func(o ...Object) (r Object, err error) {
+		if o[0].Type() != o[1].Type() || o[0].Literal() != o[1].Literal() {
+			err = &TestFailure{fmt.Sprintf(internal.ErrAssertEqualFailed, o[0].Literal(), o[0].Type(),
+				o[1].Literal(), o[1].Type())}
+			return
+		}
+		r = NewNull()
+		return
+	})
+
+	fail = NewNativeFunction("fail", 1, func(o ...Object) (r Object, err error) {
+		if s, ok := o[0].(*String); ok {
+			err = &TestFailure{s.Lit}
+		} else {
+			err = &TestFailure{o[0].Literal()}
+		}
+		return
+	})
+
+	// TestFunctions are only available to test files
+	TestFunctions map[string]*NativeFunction
+)
+
+func init() {
+
+	TestFunctions = map[string]*NativeFunction{
+		assert.Name:      assert,
+		assertEqual.Name: assertEqual,
+		fail.Name:        fail,
+	}
+}
@@ -0,0 +1,164 @@
+package optimize
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/token"
+)
+
+// foldPrefix evaluates a prefix operator applied to a literal, ok is false if the operation can not be folded
+func foldPrefix(md token.Metadata, op token.TokenType, expr ast.Expression) (folded ast.Expression, ok bool) {
+	switch e := expr.(type) {
+	case *ast.IntegerExpression:
+		switch op {
+		case token.AddToken:
+			return &ast.IntegerExpression{Metadata: md, Value: e.Value}, true
+		case token.SubToken:
+			return &ast.IntegerExpression{Metadata: md, Value: -1 * e.Value}, true
+		}
+	case *ast.FloatingPointExpression:
+		switch op {
+		case token.AddToken:
+			return &ast.FloatingPointExpression{Metadata: md, Value: e.Value}, true
+		case token.SubToken:
+			return &ast.FloatingPointExpression{Metadata: md, Value: -1 * e.Value}, true
+		}
+	case *ast.BooleanExpression:
+		if op == token.NegateToken {
+			return &ast.BooleanExpression{Metadata: md, Value: !e.Value}, true
+		}
+	}
+	return nil, false
+}
+
+// foldInfix evaluates an infix operator applied to two literals, mirroring the evaluator's semantics. ok is false if
+// the operation can not be folded, including operations that would fail at runtime
+func foldInfix(md token.Metadata, op token.TokenType, left, right ast.Expression) (folded ast.Expression, ok bool) {
+	switch l := left.(type) {
+	case *ast.IntegerExpression:
+		switch r := right.(type) {
+		case *ast.IntegerExpression:
+			return foldIntegers(md, op, l.Value, r.Value)
+		case *ast.FloatingPointExpression:
+			return foldFloats(md, op, float64(l.Value), r.Value)
+		}
+	case *ast.FloatingPointExpression:
+		switch r := right.(type) {
+		case *ast.IntegerExpression:
+			return foldFloats(md, op, l.Value, float64(r.Value))
+		case *ast.FloatingPointExpression:
+			return foldFloats(md, op, l.Value, r.Value)
+		}
+	case *ast.BooleanExpression:
+		if r, isBool := right.(*ast.BooleanExpression); isBool {
+			return foldBooleans(md, op, l.Value, r.Value)
+		}
+	case *ast.StringExpression:
+		if r, isString := right.(*ast.StringExpression); isString {
+			return foldStrings(md, op, l.Literal, r.Literal)
+		}
+	}
+	return nil, false
+}
+
+func foldIntegers(md token.Metadata, op token.TokenType, l, r int64) (ast.Expression, bool) {
+	switch op {
+	case token.AddToken:
+		return &ast.IntegerExpression{Metadata: md, Value: l + r}, true
+	case token.SubToken:
+		return &ast.IntegerExpression{Metadata: md, Value: l - r}, true
+	case token.MultToken:
+		return &ast.IntegerExpression{Metadata: md, Value: l * r}, true
+	case token.DivToken:
+		if r == 0 {
+			return nil, false
+		}
+		return &ast.IntegerExpression{Metadata: md, Value: l / r}, true
+	}
+	return foldComparison(md, op, compareIntegers(l, r))
+}
+
+func foldFloats(md token.Metadata, op token.TokenType, l, r float64) (ast.Expression, bool) {
+	switch op {
+	case token.AddToken:
+		return &ast.FloatingPointExpression{Metadata: md, Value: l + r}, true
+	case token.SubToken:
+		return &ast.FloatingPointExpression{Metadata: md, Value: l - r}, true
+	case token.MultToken:
+		return &ast.FloatingPointExpression{Metadata: md, Value: l * r}, true
+	case token.DivToken:
+		if r == 0 {
+			return nil, false
+		}
+		return &ast.FloatingPointExpression{Metadata: md, Value: l / r}, true
+	}
+	return foldComparison(md, op, compareFloats(l, r))
+}
+
+func compareIntegers(l, r int64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+// foldComparison folds a comparison given the sign of the difference between its operands
+func foldComparison(md token.Metadata, op token.TokenType, cmp int) (ast.Expression, bool) {
+	var v bool
+	switch op {
+	case token.GThanToken:
+		v = cmp > 0
+	case token.LThanToken:
+		v = cmp < 0
+	case token.GThanEqualToken:
+		v = cmp >= 0
+	case token.LThanEqualToken:
+		v = cmp <= 0
+	case token.EqualToken:
+		v = cmp == 0
+	case token.NotEqualToken:
+		v = cmp != 0
+	default:
+		return nil, false
+	}
+	return &ast.BooleanExpression{Metadata: md, Value: v}, true
+}
+
+func foldBooleans(md token.Metadata, op token.TokenType, l, r bool) (ast.Expression, bool) {
+	var v bool
+	switch op {
+	case token.EqualToken:
+		v = l == r
+	case token.NotEqualToken:
+		v = l != r
+	case token.AndToken:
+		v = l && r
+	case token.OrToken:
+		v = l || r
+	default:
+		return nil, false
+	}
+	return &ast.BooleanExpression{Metadata: md, Value: v}, true
+}
+
+func foldStrings(md token.Metadata, op token.TokenType, l, r string) (ast.Expression, bool) {
+	switch op {
+	case token.AddToken:
+		return &ast.StringExpression{Metadata: md, Literal: l + r}, true
+	case token.EqualToken:
+		return &ast.BooleanExpression{Metadata: md, Value: l == r}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,88 @@
+package optimize
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+)
+
+// isTrivial is true for unannotated functions whose body is a single return of an expression built only from
+// literals, parameters and operators. Annotated functions are not inlined, as their types are checked at runtime
+func isTrivial(fDec *ast.FunctionDeclarationStatement) bool {
+	if len(fDec.Body) != 1 || fDec.ReturnType != nil {
+		return false
+	}
+
+	for _, pt := range fDec.ParameterTypes {
+		if pt != nil {
+			return false
+		}
+	}
+
+	rS, ok := fDec.Body[0].(*ast.ReturnStatement)
+	if !ok || rS.Expression == nil {
+		return false
+	}
+
+	params := make(map[string]bool, len(fDec.Parameters))
+	for _, p := range fDec.Parameters {
+		params[p.Name] = true
+	}
+	return inlinableExpression(rS.Expression, params)
+}
+
+func inlinableExpression(expr ast.Expression, params map[string]bool) (ok bool) {
+	ok = true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case nil, *ast.IntegerExpression, *ast.FloatingPointExpression, *ast.BooleanExpression, *ast.StringExpression,
+			*ast.PrefixExpression, *ast.InfixExpression, *ast.ArrayExpression:
+		case *ast.IdentifierExpression:
+			ok = ok && params[n.Name]
+		default:
+			ok = false
+		}
+		return ok
+	})
+	return
+}
+
+// duplicable is true if evaluating each argument any number of times, in any order, is equivalent to evaluating it
+// once, which is required as parameters may be used several times, or not at all, in an inlined expression
+func duplicable(args []ast.Expression) bool {
+	for _, a := range args {
+		switch a.(type) {
+		case *ast.IntegerExpression, *ast.FloatingPointExpression, *ast.BooleanExpression, *ast.StringExpression,
+			*ast.IdentifierExpression:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// inline returns a copy of fDec's returned expression, with its parameters replaced by fCall's arguments
+func inline(fDec *ast.FunctionDeclarationStatement, fCall *ast.FunctionCallExpression) ast.Expression {
+	args := make(map[string]ast.Expression, len(fDec.Parameters))
+	for i, p := range fDec.Parameters {
+		args[p.Name] = fCall.Parameters[i]
+	}
+	return substitute(fDec.Body[0].(*ast.ReturnStatement).Expression, args)
+}
+
+// substitute copies every node that the optimizer may rewrite, so the function's own body is left unchanged
+func substitute(expr ast.Expression, args map[string]ast.Expression) ast.Expression {
+	switch e := expr.(type) {
+	case *ast.IdentifierExpression:
+		return args[e.Name]
+	case *ast.PrefixExpression:
+		return ast.NewPrefixExpression(e.Token, substitute(e.Expression, args))
+	case *ast.InfixExpression:
+		return ast.NewInfixExpression(e.Token, substitute(e.LeftExpression, args), substitute(e.RightExpression, args))
+	case *ast.ArrayExpression:
+		data := make([]ast.Expression, len(e.Data))
+		for i, d := range e.Data {
+			data[i] = substitute(d, args)
+		}
+		return ast.NewArray(e.Metadata, data)
+	}
+	return expr
+}
@@ -0,0 +1,276 @@
+package optimize
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+)
+
+type statementMethod func(stmt ast.Statement) []ast.Statement // returns the statements replacing stmt
+type expressionMethod func(expr ast.Expression) ast.Expression
+
+type Optimizer interface {
+	Optimize(prog *ast.Program) *ast.Program
+}
+
+// optimizer transforms a semantically valid program into an equivalent program that does less work at runtime. It
+// folds constant expressions, removes branches and loops whose conditions are constant, and inlines calls to trivial
+// functions. Expressions that would fail at runtime, such as division by zero, are left in place so that the error
+// is still raised when, and if, they are evaluated.
+type optimizer struct {
+	statementRouter  map[ast.NodeType]statementMethod
+	expressionRouter map[ast.NodeType]expressionMethod
+	inlinable        map[string]*ast.FunctionDeclarationStatement
+}
+
+func NewOptimizer() (o *optimizer) {
+	o = &optimizer{
+		inlinable: make(map[string]*ast.FunctionDeclarationStatement),
+	}
+
+	o.statementRouter = map[ast.NodeType]statementMethod{
+		ast.VarStatementNode:                 o.optimizeVarStatement,
+		ast.AssignmentStatementNode:          o.optimizeAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     o.optimizeFieldAssignmentStatement,
+		ast.ReturnStatementNode:              o.optimizeReturnStatement,
+		ast.IfStatementNode:                  o.optimizeIfStatement,
+		ast.WhileStatementNode:               o.optimizeWhileStatement,
+		ast.ThrowStatementNode:               o.optimizeThrowStatement,
+		ast.TryStatementNode:                 o.optimizeTryStatement,
+		ast.FunctionDeclarationStatementNode: o.optimizeFunctionDeclarationStatement,
+		ast.FunctionCallStatementNode:        o.optimizeFunctionCallStatement,
+	}
+
+	o.expressionRouter = map[ast.NodeType]expressionMethod{
+		ast.PrefixExpressionNode:             o.optimizePrefixExpression,
+		ast.SafeCallExpressionNode:           o.optimizeSafeCallExpression,
+		ast.InfixExpressionNode:              o.optimizeInfixExpression,
+		ast.ArrayExpressionNode:              o.optimizeArrayExpression,
+		ast.InterpolatedStringExpressionNode: o.optimizeInterpolatedStringExpression,
+		ast.ArrayIndexExpressionNode:         o.optimizeArrayIndexExpression,
+		ast.FieldExpressionNode:              o.optimizeFieldExpression,
+		ast.StructExpressionNode:             o.optimizeStructExpression,
+		ast.FunctionCallExpressionNode:       o.optimizeFunctionCallExpression,
+	}
+
+	return
+}
+
+// Optimize rewrites prog in place, returning it for convenience
+func (o *optimizer) Optimize(prog *ast.Program) *ast.Program {
+	// function names are unique within a program, so every call to an inlinable function refers to it
+	for _, s := range prog.Statements {
+		if fDec, ok := s.(*ast.FunctionDeclarationStatement); ok && isTrivial(fDec) {
+			o.inlinable[fDec.Name] = fDec
+		}
+	}
+
+	prog.Statements = o.optimizeBlock(prog.Statements)
+	return prog
+}
+
+func (o *optimizer) optimizeBlock(stmts []ast.Statement) []ast.Statement {
+	optimized := make([]ast.Statement, 0, len(stmts))
+	for _, s := range stmts {
+		optimized = append(optimized, o.optimizeStatement(s)...)
+	}
+	return optimized
+}
+
+func (o *optimizer) optimizeStatement(stmt ast.Statement) []ast.Statement {
+	if method, ok := o.statementRouter[stmt.Type()]; ok {
+		return method(stmt)
+	}
+	return []ast.Statement{stmt}
+}
+
+func (o *optimizer) optimizeExpression(expr ast.Expression) ast.Expression {
+	if method, ok := o.expressionRouter[expr.Type()]; ok {
+		return method(expr)
+	}
+	return expr
+}
+
+func (o *optimizer) optimizeVarStatement(stmt ast.Statement) []ast.Statement {
+	vStmt := stmt.(*ast.VarStatement)
+	vStmt.Expression = o.optimizeExpression(vStmt.Expression)
+	return []ast.Statement{vStmt}
+}
+
+func (o *optimizer) optimizeAssignmentStatement(stmt ast.Statement) []ast.Statement {
+	aStmt := stmt.(*ast.AssignmentStatement)
+	aStmt.Expression = o.optimizeExpression(aStmt.Expression)
+	return []ast.Statement{aStmt}
+}
+
+func (o *optimizer) optimizeFieldAssignmentStatement(stmt ast.Statement) []ast.Statement {
+	fStmt := stmt.(*ast.FieldAssignmentStatement)
+	fStmt.Field.Target = o.optimizeExpression(fStmt.Field.Target)
+	fStmt.Expression = o.optimizeExpression(fStmt.Expression)
+	return []ast.Statement{fStmt}
+}
+
+func (o *optimizer) optimizeReturnStatement(stmt ast.Statement) []ast.Statement {
+	rS := stmt.(*ast.ReturnStatement)
+	if rS.Expression != nil {
+		rS.Expression = o.optimizeExpression(rS.Expression)
+	}
+	return []ast.Statement{rS}
+}
+
+func (o *optimizer) optimizeFunctionCallStatement(stmt ast.Statement) []ast.Statement {
+	fCallStmt := stmt.(*ast.FunctionCallStatement)
+	for i, p := range fCallStmt.Parameters {
+		fCallStmt.Parameters[i] = o.optimizeExpression(p)
+	}
+	return []ast.Statement{fCallStmt}
+}
+
+func (o *optimizer) optimizeFunctionDeclarationStatement(stmt ast.Statement) []ast.Statement {
+	fDec := stmt.(*ast.FunctionDeclarationStatement)
+	fDec.Body = o.optimizeBlock(fDec.Body)
+	return []ast.Statement{fDec}
+}
+
+func (o *optimizer) optimizeIfStatement(stmt ast.Statement) []ast.Statement {
+	ifStmt := stmt.(*ast.IfStatement)
+	ifStmt.Condition = o.optimizeExpression(ifStmt.Condition)
+	ifStmt.IfBlock = o.optimizeBlock(ifStmt.IfBlock)
+	if ifStmt.ElseBlock != nil {
+		ifStmt.ElseBlock = o.optimizeBlock(ifStmt.ElseBlock)
+	}
+
+	cond, ok := ifStmt.Condition.(*ast.BooleanExpression)
+	if !ok {
+		return []ast.Statement{ifStmt}
+	}
+
+	taken := ifStmt.ElseBlock
+	if cond.Value {
+		taken = ifStmt.IfBlock
+	}
+
+	if declaresVariables(taken) {
+		// the branch's variables must remain in a nested scope
+		ifStmt.IfBlock = taken
+		ifStmt.ElseBlock = nil
+		ifStmt.Condition = &ast.BooleanExpression{Metadata: cond.Metadata, Value: true}
+		return []ast.Statement{ifStmt}
+	}
+	return taken
+}
+
+func (o *optimizer) optimizeWhileStatement(stmt ast.Statement) []ast.Statement {
+	wStmt := stmt.(*ast.WhileStatement)
+	wStmt.Condition = o.optimizeExpression(wStmt.Condition)
+
+	if cond, ok := wStmt.Condition.(*ast.BooleanExpression); ok && !cond.Value {
+		return []ast.Statement{}
+	}
+
+	wStmt.Block = o.optimizeBlock(wStmt.Block)
+	return []ast.Statement{wStmt}
+}
+
+func (o *optimizer) optimizeThrowStatement(stmt ast.Statement) []ast.Statement {
+	tS := stmt.(*ast.ThrowStatement)
+	tS.Expression = o.optimizeExpression(tS.Expression)
+	return []ast.Statement{tS}
+}
+
+func (o *optimizer) optimizeTryStatement(stmt ast.Statement) []ast.Statement {
+	tStmt := stmt.(*ast.TryStatement)
+	tStmt.TryBlock = o.optimizeBlock(tStmt.TryBlock)
+	tStmt.CatchBlock = o.optimizeBlock(tStmt.CatchBlock)
+	if tStmt.FinallyBlock != nil {
+		tStmt.FinallyBlock = o.optimizeBlock(tStmt.FinallyBlock)
+	}
+	return []ast.Statement{tStmt}
+}
+
+// the call is not inlined, so that the expression remains a call
+func (o *optimizer) optimizeSafeCallExpression(expr ast.Expression) ast.Expression {
+	sc := expr.(*ast.SafeCallExpression)
+	for i, p := range sc.Call.Parameters {
+		sc.Call.Parameters[i] = o.optimizeExpression(p)
+	}
+	return sc
+}
+
+func (o *optimizer) optimizeArrayExpression(expr ast.Expression) ast.Expression {
+	arr := expr.(*ast.ArrayExpression)
+	for i, e := range arr.Data {
+		arr.Data[i] = o.optimizeExpression(e)
+	}
+	return arr
+}
+
+func (o *optimizer) optimizeInterpolatedStringExpression(expr ast.Expression) ast.Expression {
+	s := expr.(*ast.InterpolatedStringExpression)
+	for i, e := range s.Expressions {
+		s.Expressions[i] = o.optimizeExpression(e)
+	}
+	return s
+}
+
+func (o *optimizer) optimizeStructExpression(expr ast.Expression) ast.Expression {
+	sExpr := expr.(*ast.StructExpression)
+	for i, v := range sExpr.Values {
+		sExpr.Values[i] = o.optimizeExpression(v)
+	}
+	return sExpr
+}
+
+func (o *optimizer) optimizeArrayIndexExpression(expr ast.Expression) ast.Expression {
+	aIExpr := expr.(*ast.ArrayIndexExpression)
+	aIExpr.IndexExpr = o.optimizeExpression(aIExpr.IndexExpr)
+	return aIExpr
+}
+
+func (o *optimizer) optimizeFieldExpression(expr ast.Expression) ast.Expression {
+	fExpr := expr.(*ast.FieldExpression)
+	fExpr.Target = o.optimizeExpression(fExpr.Target)
+	return fExpr
+}
+
+func (o *optimizer) optimizePrefixExpression(expr ast.Expression) ast.Expression {
+	pExpr := expr.(*ast.PrefixExpression)
+	pExpr.Expression = o.optimizeExpression(pExpr.Expression)
+
+	if folded, ok := foldPrefix(pExpr.Data(), pExpr.Token.Type(), pExpr.Expression); ok {
+		return folded
+	}
+	return pExpr
+}
+
+func (o *optimizer) optimizeInfixExpression(expr ast.Expression) ast.Expression {
+	iExpr := expr.(*ast.InfixExpression)
+	iExpr.LeftExpression = o.optimizeExpression(iExpr.LeftExpression)
+	iExpr.RightExpression = o.optimizeExpression(iExpr.RightExpression)
+
+	if folded, ok := foldInfix(iExpr.Data(), iExpr.Token.Type(), iExpr.LeftExpression, iExpr.RightExpression); ok {
+		return folded
+	}
+	return iExpr
+}
+
+func (o *optimizer) optimizeFunctionCallExpression(expr ast.Expression) ast.Expression {
+	fCall := expr.(*ast.FunctionCallExpression)
+	for i, p := range fCall.Parameters {
+		fCall.Parameters[i] = o.optimizeExpression(p)
+	}
+
+	// calls qualified by a module are to functions of other files, which are not optimized
+	if fDec, ok := o.inlinable[fCall.FunctionName]; ok && fCall.Module == "" &&
+		len(fCall.Parameters) == len(fDec.Parameters) && duplicable(fCall.Parameters) {
+		return o.optimizeExpression(inline(fDec, fCall))
+	}
+	return fCall
+}
+
+func declaresVariables(stmts []ast.Statement) bool {
+	for _, s := range stmts {
+		if s.Type() == ast.VarStatementNode {
+			return true
+		}
+	}
+	return false
+}
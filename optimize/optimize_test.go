@@ -0,0 +1,99 @@
+package optimize
+
+import (
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"github.com/EricNRodriguez/yum/semantic"
+	"fmt"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+type optimizerTestCase struct {
+	input    []byte
+	expected string
+}
+
+func TestOptimizer(t *testing.T) {
+	tCs := []optimizerTestCase{
+		{
+			[]byte("var x = 1 + 2 * 3;"),
+			"var x = 7;\n",
+		},
+		{
+			[]byte("var y = -(4 - 6); var z = !true; var s = \"a\" + \"b\";"),
+			"var y = 2;\nvar z = false;\nvar s = \"ab\";\n",
+		},
+		{
+			[]byte("var b = 2.5 * 2.0; var c = 3 < 4; var d = \"a\" == \"b\";"),
+			"var b = 5.000000;\nvar c = true;\nvar d = false;\n",
+		},
+		{
+			// division by zero must still fail at runtime
+			[]byte("var a = 1 / 0; var b = 1.5 / 0.0;"),
+			"var a = (1 / 0);\nvar b = (1.500000 / 0.000000);\n",
+		},
+		{
+			[]byte("var x = 0; var y = x + 1 * 2;"),
+			"var x = 0;\nvar y = (x + 2);\n",
+		},
+		{
+			[]byte("var x = 0; if (1 < 2) { x = 1; } else { x = 2; };"),
+			"var x = 0;\nx = 1;\n",
+		},
+		{
+			[]byte("var x = 0; if (false) { x = 1; };"),
+			"var x = 0;\n",
+		},
+		{
+			// declarations must remain in their own scope
+			[]byte("var x = 0; if (false) { x = 1; } else { var y = 2; x = y; };"),
+			"var x = 0;\nif (true) { var y = 2;x = y; };\n",
+		},
+		{
+			[]byte("var x = 0; while (1 > 2) { x = x + 1; };"),
+			"var x = 0;\n",
+		},
+		{
+			[]byte("var x = 0; while (x < 2 + 1) { x = x + 1; };"),
+			"var x = 0;\nwhile ((x < 3)) { x = (x + 1); };\n",
+		},
+		{
+			[]byte("func sq(n) { return n * n; }; var x = 4; var y = sq(x) + sq(3);"),
+			"func sq(n) { return (n * n); };\nvar x = 4;\nvar y = ((x * x) + 9);\n",
+		},
+		{
+			[]byte("func sq(n) { return n * n; }; func two() { return 2; }; var y = sq(two());"),
+			"func sq(n) { return (n * n); };\nfunc two() { return 2; };\nvar y = 4;\n",
+		},
+		{
+			// arguments with side effects are not duplicated
+			[]byte("func sq(n) { return n * n; }; func f(n) { print(n); return n; }; var y = sq(f(2));"),
+			"func sq(n) { return (n * n); };\nfunc f(n) { print(n);return n; };\nvar y = sq(f(2));\n",
+		},
+		{
+			// annotated functions are checked at runtime, so are not inlined
+			[]byte("func sq(n: int): int { return n * n; }; var y = sq(3);"),
+			"func sq(n: int): int { return (n * n); };\nvar y = sq(3);\n",
+		},
+		{
+			// fields are not parameters, even if they share their names
+			[]byte("struct P { x }; var p = P{x: 1 + 2}; func getX(x, p) { return p.x; }; p.x = 2 * 2; var y = getX(1, p);"),
+			"struct P { x };\nfunc getX(x, p) { return p.x; };\nvar p = P{x: 3};\np.x = 4;\nvar y = getX(1, p);\n",
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+
+	for i, tC := range tCs {
+		fp := fmt.Sprintf("test_files/optimizer/test_%v.txt", i)
+		prog := testutil.Parse(t, fs, fp, tC.input)
+
+		if errs := semantic.NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+			t.Fatalf("test case %v failed semantic analysis: %v", i+1, errs)
+		}
+
+		if out := NewOptimizer().Optimize(prog).String(); out != tC.expected {
+			t.Errorf("test case %v: expected %q, got %q", i+1, tC.expected, out)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type ParserData interface {
 	checkNextToken() bool
 	recordError(error)
 	errors() []error
+	comments() []token.Token
 	consumeBlockStatement()
 	consumeIfStatement()
 	consumeStatement()
@@ -24,6 +25,7 @@ type parserData struct {
 	tokBuf       []token.Token
 	currTok      token.Token
 	syntaxErrors []error
+	commentToks  []token.Token // comments are removed from the token stream, but kept for the formatter
 }
 
 func newParserData(l lexer.Lexer) (*parserData, error) {
@@ -32,7 +34,13 @@ func newParserData(l lexer.Lexer) (*parserData, error) {
 		err error
 	)
 
-	if cT, err = l.NextToken(); err != nil {
+	commentToks := make([]token.Token, 0)
+
+	for cT, err = l.NextToken(); err == nil && cT.Type() == token.CommentToken; cT, err = l.NextToken() {
+		commentToks = append(commentToks, cT)
+	}
+
+	if err != nil {
 		err = internal.NewError(cT.Data(), internal.ErrInitParser, internal.InternalErr)
 		return nil, err
 
@@ -46,10 +54,15 @@ func newParserData(l lexer.Lexer) (*parserData, error) {
 		tokBuf:       make([]token.Token, 0),
 		syntaxErrors: make([]error, 0),
 		currTok:      cT,
+		commentToks:  commentToks,
 	}
 
 	for cT.Type() != token.EOFToken {
 		cT, _ = l.NextToken()
+		if cT.Type() == token.CommentToken {
+			pd.commentToks = append(pd.commentToks, cT)
+			continue
+		}
 		pd.addToken(cT)
 	}
 
@@ -104,6 +117,10 @@ func (pd *parserData) errors() []error {
 	return pd.syntaxErrors
 }
 
+func (pd *parserData) comments() []token.Token {
+	return pd.commentToks
+}
+
 // need to update to account for nested block statement s
 func (pd *parserData) consumeBlockStatement() {
 	for pd.currentToken().Type() != token.RightBraceToken {
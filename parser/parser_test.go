@@ -234,6 +234,180 @@ func TestParser(t *testing.T) {
 			"func defineFunc() { func helloWorld() { print(\"hello world\"); }; print(\"made hello " +
 				"world\");}; defineFunc();helloWorld();",
 		},
+		{
+			[]byte("var x: int = 3;"),
+			[]ast.NodeType{ast.VarStatementNode},
+			0,
+			"var x: int = 3;",
+		},
+		{
+			[]byte("var x: [[float]] = [[1.5]];"),
+			[]ast.NodeType{ast.VarStatementNode},
+			0,
+			"var x: [[float]] = [[1.500000]];",
+		},
+		{
+			[]byte("func add(a: int, b: int): int { return a + b; };"),
+			[]ast.NodeType{ast.FunctionDeclarationStatementNode},
+			0,
+			"func add(a: int, b: int): int { return (a + b); };",
+		},
+		{
+			[]byte("func first(a: [string], b): string { return a[0]; };"),
+			[]ast.NodeType{ast.FunctionDeclarationStatementNode},
+			0,
+			"func first(a: [string], b): string { return a[0]; };",
+		},
+		{
+			[]byte("var x: = 3;"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // missing type
+			"",
+		},
+		{
+			[]byte("var x: [int = 3;"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // unclosed array type
+			"",
+		},
+		{
+			[]byte("func add(a: int,): int { return a; };"),
+			[]ast.NodeType{ast.FunctionDeclarationStatementNode},
+			1, // trailing comma
+			"",
+		},
+		{
+			[]byte("print(m.square(m.pi));\nimport \"lib/math\" as m;"),
+			[]ast.NodeType{ast.ImportStatementNode, ast.FunctionCallStatementNode},
+			0, // imports are hoisted
+			"import \"lib/math\" as m; print(m.square(m.pi));",
+		},
+		{
+			[]byte("util.log(1);"),
+			[]ast.NodeType{ast.FunctionCallStatementNode},
+			0,
+			"util.log(1);",
+		},
+		{
+			[]byte("import math;"),
+			[]ast.NodeType{ast.ImportStatementNode},
+			1, // the path must be a string
+			"",
+		},
+		{
+			[]byte("var x = m.3;"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // only names are qualified
+			"",
+		},
+		{
+			[]byte("export var x: int = 1;\nexport func f() {};"),
+			[]ast.NodeType{ast.FunctionDeclarationStatementNode, ast.VarStatementNode},
+			0,
+			"export func f() {}; export var x: int = 1;",
+		},
+		{
+			[]byte("export print(1);"),
+			[]ast.NodeType{},
+			1, // only declarations are exported
+			"",
+		},
+		{
+			[]byte("var s = \"${a} + ${b * 2} = ${\"${a + b}\"}!\";"),
+			[]ast.NodeType{ast.VarStatementNode},
+			0,
+			"var s = \"${a} + ${(b*2)} = ${\"${(a+b)}\"}!\";",
+		},
+		{
+			[]byte("var s = \"a\\\"b\\\\${c}\\n\";"),
+			[]ast.NodeType{ast.VarStatementNode},
+			0,
+			"var s = \"a\\\"b\\\\${c}\\n\";",
+		},
+		{
+			[]byte("var s = \"a\\qb\";"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // \q is not an escape sequence
+			"",
+		},
+		{
+			[]byte("var s = \"a ${1 2} b\";"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // a single expression is embedded
+			"",
+		},
+		{
+			[]byte("throw \"bad\" + x;"),
+			[]ast.NodeType{ast.ThrowStatementNode},
+			0,
+			"throw (\"bad\"+x);",
+		},
+		{
+			[]byte("try {f();} catch (e) {print(e);} finally {g();};\ntry {f();} catch (e) {};\ntry {} finally {};"),
+			[]ast.NodeType{ast.TryStatementNode, ast.TryStatementNode, ast.TryStatementNode},
+			0,
+			"try {f();} catch (e) {print(e);} finally {g();}; try {f();} catch (e) {}; try {} finally {};",
+		},
+		{
+			[]byte("try {f();};"),
+			[]ast.NodeType{},
+			1, // a catch or finally block is required
+			"",
+		},
+		{
+			[]byte("try {f();} catch {g();};"),
+			[]ast.NodeType{},
+			1, // the caught error must be named
+			"",
+		},
+		{
+			[]byte("throw;"),
+			[]ast.NodeType{},
+			1,
+			"",
+		},
+		{
+			[]byte("var r = try f(1) + 1; var s = -try m.g();"),
+			[]ast.NodeType{ast.VarStatementNode, ast.VarStatementNode},
+			0,
+			"var r = ((try f(1)) + 1); var s = (-(try m.g()));",
+		},
+		{
+			[]byte("var r = try x;"),
+			[]ast.NodeType{ast.VarStatementNode},
+			1, // only calls are safe
+			"",
+		},
+		{
+			[]byte("var p = 1; struct Point { x, y }; struct Empty {};"),
+			[]ast.NodeType{ast.StructDeclarationStatementNode, ast.StructDeclarationStatementNode, ast.VarStatementNode},
+			0, // declarations are hoisted
+			"struct Point { x, y }; struct Empty {}; var p = 1;",
+		},
+		{
+			[]byte("var p = Point{x: 1 + 2, y: f(a.b)} == Point(1, 2); var e = Point{}; p.x = p.y * 2;"),
+			[]ast.NodeType{ast.VarStatementNode, ast.VarStatementNode, ast.FieldAssignmentStatementNode},
+			0,
+			"var p = (Point{x: (1 + 2), y: f(a.b)} == Point(1, 2)); var e = Point{}; p.x = (p.y * 2);",
+		},
+		{
+			[]byte("var x = l.a.x + f(1).x * -ps[0].x; l.a.x = 1; ps[i + 1].x = m.f(l.a);"),
+			[]ast.NodeType{ast.VarStatementNode, ast.FieldAssignmentStatementNode, ast.FieldAssignmentStatementNode},
+			0, // fields bind tighter than any operator
+			"var x = (l.a.x + (f(1).x * (-ps[0].x))); l.a.x = 1; ps[(i + 1)].x = m.f(l.a);",
+		},
+		{
+			[]byte("var x = l.a.f(1); p.1 = 2; ps[0]; l.a;"),
+			[]ast.NodeType{},
+			4, // only modules qualify calls, fields are names and reads are not statements
+			"",
+		},
+		{
+			[]byte("struct Point { x, }; struct { x }; var p = Point{x 1}; var q = Point{x: 1,};"),
+			[]ast.NodeType{},
+			4, // trailing commas, a missing name and a missing colon
+			"",
+		},
 	}
 
 	var (
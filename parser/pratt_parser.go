@@ -24,6 +24,7 @@ const (
 	AddSubPrecedence
 	MultDivPrecedence
 	PrefixPrecedence
+	PostfixPrecedence
 )
 
 var (
@@ -40,9 +41,17 @@ var (
 		token.GThanToken:      ConditionalPrecedence,
 		token.LThanEqualToken: ConditionalPrecedence,
 		token.GThanEqualToken: ConditionalPrecedence,
+		token.DotToken:        PostfixPrecedence,
 	}
 )
 
+// Precedence returns the binding power of an infix operator, ok is false for tokens that are not infix operators
+func Precedence(tt token.TokenType) (p int, ok bool) {
+	var op operatorPrecedence
+	op, ok = tokenOperPrecedence[tt]
+	return int(op), ok
+}
+
 type PrattParser interface {
 	parseExpression(precedence operatorPrecedence) (ast.Expression, error)
 	parseParameters(bool) ([]ast.Expression, error)
@@ -84,6 +93,7 @@ func newPrattParser(l lexer.Lexer) (*prattParser, error) {
 	nMs[token.QuotationMarkToken] = pp.parseString
 	nMs[token.LeftParenToken] = pp.parseGroupExpression
 	nMs[token.LeftBracketToken] = pp.parseArrayNodeDeclaration
+	nMs[token.TryToken] = pp.parseSafeCall
 
 	// initialise led methods
 	lMs[token.AddToken] = pp.parseInfixOperator
@@ -98,6 +108,7 @@ func newPrattParser(l lexer.Lexer) (*prattParser, error) {
 	lMs[token.NotEqualToken] = pp.parseInfixOperator
 	lMs[token.AndToken] = pp.parseInfixOperator
 	lMs[token.OrToken] = pp.parseInfixOperator
+	lMs[token.DotToken] = pp.parseField
 
 	return pp, err
 }
@@ -168,6 +179,26 @@ func (pp *prattParser) parsePrefixOperator() (expr ast.Expression, err error) {
 	return
 }
 
+// parseSafeCall parses try followed by a function call, e.g. try f(x)
+func (pp *prattParser) parseSafeCall() (expr ast.Expression, err error) {
+	tryToken := pp.currentToken()
+	pp.consume(1) // consume try
+
+	var call ast.Expression
+	if call, err = pp.parseExpression(PrefixPrecedence); err != nil {
+		return
+	}
+
+	fCall, ok := call.(*ast.FunctionCallExpression)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrInvalidSafeCall, call.String())
+		return nil, internal.NewError(tryToken.Data(), errMsg, internal.SyntaxErr)
+	}
+
+	expr = ast.NewSafeCallExpression(tryToken, fCall)
+	return
+}
+
 func (pp *prattParser) parseInteger() (expr ast.Expression, err error) {
 	var (
 		i int64
@@ -208,7 +239,11 @@ func (pp *prattParser) parseString() (expr ast.Expression, err error) {
 	md := pp.currentToken().Data()
 	pp.consume(1) // consume left quotation mark
 
-	sBuff := bytes.Buffer{}
+	var (
+		sBuff = bytes.Buffer{}
+		segs  = make([]string, 0)
+		exprs = make([]ast.Expression, 0)
+	)
 	for pp.currentToken().Type() != token.QuotationMarkToken {
 		if pp.currentToken().Type() == token.EOFToken {
 			err = internal.NewError(pp.currentToken().Data(), fmt.Sprintf(internal.ErrEndOfFile,
@@ -216,11 +251,55 @@ func (pp *prattParser) parseString() (expr ast.Expression, err error) {
 			return
 		}
 
+		if pp.currentToken().Type() == token.InterpolationStartToken {
+			var e ast.Expression
+			if e, err = pp.parseInterpolation(); err != nil {
+				return
+			}
+			segs = append(segs, sBuff.String())
+			exprs = append(exprs, e)
+			sBuff.Reset()
+			continue
+		}
+
+		if pp.currentToken().Type() == token.EscapeToken {
+			c, ok := token.Escapes[pp.currentToken().Literal()]
+			if !ok {
+				err = internal.NewError(pp.currentToken().Data(), fmt.Sprintf(internal.ErrInvalidEscape,
+					pp.currentToken().Literal()), internal.SyntaxErr)
+				return
+			}
+			sBuff.WriteString(c)
+			pp.consume(1)
+			continue
+		}
+
 		sBuff.WriteString(pp.currentToken().Literal())
 		pp.consume(1)
 	}
 	pp.consume(1) // consume right quotation mark
-	expr = ast.NewStringExpression(md, sBuff.String())
+
+	if len(exprs) == 0 {
+		expr = ast.NewStringExpression(md, sBuff.String())
+		return
+	}
+	expr = ast.NewInterpolatedStringExpression(md, append(segs, sBuff.String()), exprs)
+	return
+}
+
+// parseInterpolation parses an expression embedded in a string, from ${ to its closing brace
+func (pp *prattParser) parseInterpolation() (expr ast.Expression, err error) {
+	pp.consume(1) // consume ${
+	if expr, err = pp.parseExpression(MinPrecedence); err != nil {
+		return
+	}
+
+	if pp.currentToken().Type() != token.InterpolationEndToken {
+		errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.RightBraceToken, pp.currentToken().Literal())
+		err = internal.NewError(pp.currentToken().Data(), errMsg, internal.SyntaxErr)
+		return
+	}
+	pp.consume(1) // consume }
 	return
 }
 
@@ -296,6 +375,9 @@ func (pp *prattParser) parseIdent() (expr ast.Expression, err error) {
 		}
 		expr = ast.NewFunctionCallExpression(idenToken.Data(), idenToken.Literal(), params...)
 
+	} else if pp.peekToken().Type() == token.LeftBraceToken {
+		expr, err = pp.parseStruct()
+
 	} else if pp.peekToken().Type() == token.LeftBracketToken {
 		// ArrayNode index
 		pp.consume(2) // consume iden and left bracket
@@ -320,6 +402,91 @@ func (pp *prattParser) parseIdent() (expr ast.Expression, err error) {
 	return
 }
 
+// parses the field read from target, such as p.x, or the call of a function declared in the imported module that
+// target names, such as m.f(x). The current token is the dot
+func (pp *prattParser) parseField(target ast.Expression) (expr ast.Expression, err error) {
+	pp.consume(1) // consume dot
+
+	fieldToken := pp.currentToken()
+	if fieldToken.Type() != token.IdentifierToken {
+		errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.IdentifierToken, fieldToken.Literal())
+		err = internal.NewError(fieldToken.Data(), errMsg, internal.SyntaxErr)
+		return
+	}
+
+	if pp.peekToken().Type() != token.LeftParenToken {
+		expr = ast.NewFieldExpression(fieldToken.Data(), target, fieldToken.Literal())
+		pp.consume(1) // consume field
+		return
+	}
+
+	// only the name of an imported module qualifies a function call
+	qualifier, ok := target.(*ast.IdentifierExpression)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrInvalidQualified, target.String(), fieldToken.Literal())
+		err = internal.NewError(fieldToken.Data(), errMsg, internal.SyntaxErr)
+		return
+	}
+	pp.consume(1) // consume function name
+
+	var params []ast.Expression
+	if params, err = pp.parseParameters(false); err != nil {
+		return
+	}
+	fCall := ast.NewFunctionCallExpression(qualifier.Metadata, fieldToken.Literal(), params...).(*ast.FunctionCallExpression)
+	fCall.Module = qualifier.Name
+	expr = fCall
+	return
+}
+
+// parses a struct literal such as Point{x: 1, y: 2}, the current token is the name of the struct
+func (pp *prattParser) parseStruct() (expr ast.Expression, err error) {
+	var (
+		nameToken = pp.currentToken()
+		fields    = make([]ast.IdentifierExpression, 0)
+		values    = make([]ast.Expression, 0)
+	)
+	pp.consume(2) // consume name and left brace
+
+	for pp.currentToken().Type() != token.RightBraceToken {
+		var value ast.Expression
+
+		if pp.currentToken().Type() != token.IdentifierToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.IdentifierToken, pp.currentToken().Literal())
+			err = internal.NewError(pp.currentToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		field := ast.NewIdentifierExpression(pp.currentToken())
+
+		if pp.peekToken().Type() != token.ColonToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.ColonToken, pp.peekToken().Literal())
+			err = internal.NewError(pp.peekToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		pp.consume(2) // consume field name and colon
+
+		if value, err = pp.parseExpression(MinPrecedence); err != nil {
+			return
+		}
+		fields, values = append(fields, *field), append(values, value)
+
+		if pp.currentToken().Type() == token.RightBraceToken {
+			break
+		}
+
+		if pp.currentToken().Type() != token.CommaToken || pp.peekToken().Type() == token.RightBraceToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.RightBraceToken, pp.currentToken().Literal())
+			err = internal.NewError(pp.currentToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		pp.consume(1) // consume comma
+	}
+
+	pp.consume(1) // consume right brace
+	expr = ast.NewStructExpression(nameToken.Data(), nameToken.Literal(), fields, values)
+	return
+}
+
 func (pp *prattParser) parseBoolean() (expr ast.Expression, err error) {
 	expr = ast.NewBooleanExpression(pp.currentToken(), pp.currentToken().Literal() == "true")
 	pp.consume(1)
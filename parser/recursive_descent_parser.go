@@ -10,12 +10,25 @@ import (
 
 type parseMethod func() ast.Statement
 
+const asKeyword = "as"
+
 type RecursiveDescentParser struct {
 	parseMethodRouter map[token.TokenType]parseMethod
+	preserveOrder     bool
 	PrattParser
 }
 
-func NewRecursiveDescentParser(l lexer.Lexer) (Parser, error) {
+type Option func(*RecursiveDescentParser)
+
+// WithoutHoisting keeps top level statements in source order, rather than moving function declarations to the start
+// of the program. The resulting program is suitable for formatting, but not evaluation
+func WithoutHoisting() Option {
+	return func(rdp *RecursiveDescentParser) {
+		rdp.preserveOrder = true
+	}
+}
+
+func NewRecursiveDescentParser(l lexer.Lexer, opts ...Option) (Parser, error) {
 	var (
 		pMR         = make(map[token.TokenType]parseMethod) // parse method router
 		prattParser PrattParser
@@ -31,6 +44,10 @@ func NewRecursiveDescentParser(l lexer.Lexer) (Parser, error) {
 		PrattParser:       prattParser,
 	}
 
+	for _, opt := range opts {
+		opt(rdp)
+	}
+
 	// initialise pMR
 	pMR[token.VarToken] = rdp.parseVarStatement
 	pMR[token.ReturnToken] = rdp.parseReturnStatement
@@ -38,6 +55,11 @@ func NewRecursiveDescentParser(l lexer.Lexer) (Parser, error) {
 	pMR[token.IfToken] = rdp.parseIfStatement
 	pMR[token.FuncToken] = rdp.parseFuncDeclarationStatement
 	pMR[token.WhileToken] = rdp.parseWhileStatement
+	pMR[token.ImportToken] = rdp.parseImportStatement
+	pMR[token.ExportToken] = rdp.parseExportStatement
+	pMR[token.ThrowToken] = rdp.parseThrowStatement
+	pMR[token.TryToken] = rdp.parseTryStatement
+	pMR[token.StructToken] = rdp.parseStructDeclarationStatement
 
 	return rdp, err
 }
@@ -59,7 +81,13 @@ func (rdp *RecursiveDescentParser) Parse() (prog *ast.Program, errs []error) {
 	}
 
 	prog = ast.NewProgram(rdp.currentToken().Data(), stmts...)
-	prog.Hoist() // moves declarations to the top of the file
+	if !rdp.preserveOrder {
+		prog.Hoist() // moves declarations to the top of the file
+	}
+
+	for _, c := range rdp.comments() {
+		prog.Comments = append(prog.Comments, ast.NewComment(c.Data(), c.Literal()))
+	}
 
 	return prog, rdp.errors()
 }
@@ -90,6 +118,7 @@ func (rdp *RecursiveDescentParser) parseStatement() (stmt ast.Statement, err err
 func (rdp *RecursiveDescentParser) parseVarStatement() (stmt ast.Statement) {
 	var (
 		iden     *ast.IdentifierExpression
+		ta       *ast.TypeAnnotation
 		expr     ast.Expression
 		varToken = rdp.currentToken()
 		err      error
@@ -103,11 +132,30 @@ func (rdp *RecursiveDescentParser) parseVarStatement() (stmt ast.Statement) {
 
 	iden = ast.NewIdentifierExpression(rdp.currentToken())
 
-	if !rdp.expectTokenType(token.AssignToken) {
-		rdp.consumeStatement()
-		return
+	if rdp.peekToken().Type() == token.ColonToken {
+		rdp.consume(2) // consume identifier and colon
+
+		if ta, err = rdp.parseTypeAnnotation(); err != nil {
+			rdp.recordError(err)
+			rdp.consumeStatement()
+			return
+		}
+
+		if rdp.currentToken().Type() != token.AssignToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.AssignToken, rdp.currentToken().Literal())
+			rdp.recordError(internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr))
+			rdp.consumeStatement()
+			return
+		}
+		rdp.consume(1)
+
+	} else {
+		if !rdp.expectTokenType(token.AssignToken) {
+			rdp.consumeStatement()
+			return
+		}
+		rdp.consume(2)
 	}
-	rdp.consume(2)
 
 	// skip stmts with syntax errors
 	if expr, err = rdp.parseExpression(MinPrecedence); err != nil {
@@ -116,7 +164,7 @@ func (rdp *RecursiveDescentParser) parseVarStatement() (stmt ast.Statement) {
 		return
 	}
 
-	stmt = ast.NewVarStatement(varToken, iden, expr)
+	stmt = ast.NewVarStatement(varToken, iden, ta, expr)
 
 	return
 }
@@ -166,8 +214,8 @@ func (rdp *RecursiveDescentParser) parseIdenStatement() (stmt ast.Statement) {
 
 		stmt = ast.NewAssignmentStatement(iden.Metadata, iden, expr)
 
-	case token.LeftParenToken:
-		stmt = rdp.parseFunctionCallStatement()
+	case token.LeftParenToken, token.DotToken, token.LeftBracketToken:
+		stmt = rdp.parseCallOrFieldStatement()
 
 	default:
 		errMsg := fmt.Sprintf(internal.ErrInvalidStatement, rdp.currentToken().Literal())
@@ -179,19 +227,114 @@ func (rdp *RecursiveDescentParser) parseIdenStatement() (stmt ast.Statement) {
 	return
 }
 
-func (rdp *RecursiveDescentParser) parseFunctionCallStatement() (stmt ast.Statement) {
+// parses a function call, or the assignment of a field of a struct such as p.x = 1 or ps[0].x = 1
+func (rdp *RecursiveDescentParser) parseCallOrFieldStatement() (stmt ast.Statement) {
 	var (
 		expr ast.Expression
 		err  error
 	)
 
+	md := rdp.currentToken().Data()
+	if expr, err = rdp.parseExpression(MinPrecedence); err != nil {
+		rdp.recordError(err)
+		rdp.consumeStatement()
+		return
+	}
+
+	if field, ok := expr.(*ast.FieldExpression); ok && rdp.currentToken().Type() == token.AssignToken {
+		rdp.consume(1) // consume assign
+
+		if expr, err = rdp.parseExpression(MinPrecedence); err != nil {
+			rdp.recordError(err)
+			rdp.consumeStatement()
+			return
+		}
+
+		stmt = ast.NewFieldAssignmentStatement(md, field, expr)
+		return
+	}
+
+	// a field or array element read is not a statement
+	fCall, ok := expr.(*ast.FunctionCallExpression)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrInvalidStatement, expr.String())
+		rdp.recordError(internal.NewError(md, errMsg, internal.SyntaxErr))
+		rdp.consumeStatement()
+		return
+	}
+
+	stmt = ast.NewFunctionCallStatement(fCall)
+	return
+}
+
+// parses import "path"; or import "path" as name;
+func (rdp *RecursiveDescentParser) parseImportStatement() (stmt ast.Statement) {
+	var (
+		md    = rdp.currentToken().Data()
+		expr  ast.Expression
+		alias string
+		err   error
+	)
+
+	if !rdp.expectTokenType(token.QuotationMarkToken) {
+		rdp.consumeStatement()
+		return
+	}
+	rdp.consume(1) // consume import
+
 	if expr, err = rdp.parseExpression(MinPrecedence); err != nil {
 		rdp.recordError(err)
 		rdp.consumeStatement()
 		return
 	}
 
-	stmt = ast.NewFunctionCallStatement(expr.(*ast.FunctionCallExpression))
+	path, ok := expr.(*ast.StringExpression)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrInvalidImportPath, expr.String())
+		rdp.recordError(internal.NewError(expr, errMsg, internal.SyntaxErr))
+		rdp.consumeStatement()
+		return
+	}
+
+	// as is only a keyword in import statements
+	if rdp.currentToken().Type() == token.IdentifierToken && rdp.currentToken().Literal() == asKeyword {
+		if !rdp.expectTokenType(token.IdentifierToken) {
+			rdp.consumeStatement()
+			return
+		}
+		rdp.consume(1) // consume as
+
+		alias = rdp.currentToken().Literal()
+		rdp.consume(1) // consume alias
+	}
+
+	stmt = ast.NewImportStatement(md, path.Literal, alias)
+	return
+}
+
+// parses export var ...; or export func ...;
+func (rdp *RecursiveDescentParser) parseExportStatement() (stmt ast.Statement) {
+	rdp.consume(1) // consume export
+
+	switch rdp.currentToken().Type() {
+	case token.VarToken:
+		if vStmt, ok := rdp.parseVarStatement().(*ast.VarStatement); ok {
+			vStmt.Exported = true
+			stmt = vStmt
+		}
+
+	case token.FuncToken:
+		if fDec, ok := rdp.parseFuncDeclarationStatement().(*ast.FunctionDeclarationStatement); ok {
+			fDec.Exported = true
+			stmt = fDec
+		}
+
+	default:
+		errMsg := fmt.Sprintf(internal.ErrInvalidExport, rdp.currentToken().Literal())
+		rdp.recordError(internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr))
+		rdp.consumeStatement()
+	}
+
 	return
 }
 
@@ -200,6 +343,8 @@ func (rdp *RecursiveDescentParser) parseIfStatement() (stmt ast.Statement) {
 		t          = rdp.currentToken()
 		trueBlock  []ast.Statement
 		falseBlock []ast.Statement
+		trueEnd    token.Metadata
+		falseEnd   token.Metadata
 		cond       ast.Expression
 		err        error
 	)
@@ -216,7 +361,7 @@ func (rdp *RecursiveDescentParser) parseIfStatement() (stmt ast.Statement) {
 		return
 	}
 
-	if trueBlock, err = rdp.parseBlockStatement(); err != nil {
+	if trueBlock, trueEnd, err = rdp.parseBlockStatement(); err != nil {
 		rdp.recordError(err)
 		rdp.consumeIfStatement()
 		return
@@ -225,14 +370,16 @@ func (rdp *RecursiveDescentParser) parseIfStatement() (stmt ast.Statement) {
 	// else
 	if rdp.currentToken().Type() == token.ElseToken {
 		rdp.consume(1) // consume ELSE
-		if falseBlock, err = rdp.parseBlockStatement(); err != nil {
+		if falseBlock, falseEnd, err = rdp.parseBlockStatement(); err != nil {
 			rdp.recordError(err)
 			rdp.consumeBlockStatement()
 			return
 		}
 	}
 
-	stmt = ast.NewIfStatement(t, cond, trueBlock, falseBlock)
+	ifStmt := ast.NewIfStatement(t, cond, trueBlock, falseBlock)
+	ifStmt.IfBlockEnd, ifStmt.ElseBlockEnd = trueEnd, falseEnd
+	stmt = ifStmt
 	return
 }
 
@@ -241,6 +388,7 @@ func (rdp *RecursiveDescentParser) parseWhileStatement() (stmt ast.Statement) {
 		md    = rdp.currentToken().Data()
 		cond  ast.Expression
 		block []ast.Statement
+		end   token.Metadata
 		err   error
 	)
 
@@ -264,16 +412,105 @@ func (rdp *RecursiveDescentParser) parseWhileStatement() (stmt ast.Statement) {
 	}
 	rdp.consume(1) // consume right parenthesis
 
-	if block, err = rdp.parseBlockStatement(); err != nil {
+	if block, end, err = rdp.parseBlockStatement(); err != nil {
 		rdp.recordError(err)
 		rdp.consumeBlockStatement()
 		return
 	}
-	stmt = ast.NewWhileStatement(md, cond, block)
+	wStmt := ast.NewWhileStatement(md, cond, block)
+	wStmt.BlockEnd = end
+	stmt = wStmt
 	return
 }
 
-func (rdp *RecursiveDescentParser) parseBlockStatement() (bStmt []ast.Statement, err error) {
+func (rdp *RecursiveDescentParser) parseThrowStatement() (stmt ast.Statement) {
+	throwToken := rdp.currentToken()
+	rdp.consume(1) // consume throw
+
+	if expr, err := rdp.parseExpression(MinPrecedence); err != nil {
+		rdp.recordError(err)
+		rdp.consumeStatement()
+
+	} else {
+		stmt = ast.NewThrowStatement(throwToken, expr)
+
+	}
+
+	return
+}
+
+func (rdp *RecursiveDescentParser) parseTryStatement() (stmt ast.Statement) {
+	var (
+		t          = rdp.currentToken()
+		tryBlock   []ast.Statement
+		catchName  *ast.IdentifierExpression
+		catchBlock []ast.Statement
+		finally    []ast.Statement
+		tryEnd     token.Metadata
+		catchEnd   token.Metadata
+		finallyEnd token.Metadata
+		err        error
+	)
+	rdp.consume(1) // consume try
+
+	if tryBlock, tryEnd, err = rdp.parseBlockStatement(); err != nil {
+		rdp.recordError(err)
+		rdp.consumeBlockStatement()
+		return
+	}
+
+	// catch
+	if rdp.currentToken().Type() == token.CatchToken {
+		if !rdp.expectTokenType(token.LeftParenToken) {
+			rdp.consumeStatement()
+			return
+		}
+		rdp.consume(2) // consume catch and left parenthesis
+
+		if rdp.currentToken().Type() != token.IdentifierToken {
+			rdp.recordError(internal.NewError(rdp.currentToken().Data(), fmt.Sprintf(internal.ErrInvalidToken,
+				token.IdentifierToken, rdp.currentToken().Type()), internal.SyntaxErr))
+			rdp.consumeStatement()
+			return
+		}
+		catchName = ast.NewIdentifierExpression(rdp.currentToken())
+
+		if !rdp.expectTokenType(token.RightParenToken) {
+			rdp.consumeStatement()
+			return
+		}
+		rdp.consume(2) // consume identifier and right parenthesis
+
+		if catchBlock, catchEnd, err = rdp.parseBlockStatement(); err != nil {
+			rdp.recordError(err)
+			rdp.consumeBlockStatement()
+			return
+		}
+	}
+
+	// finally
+	if rdp.currentToken().Type() == token.FinallyToken {
+		rdp.consume(1) // consume finally
+		if finally, finallyEnd, err = rdp.parseBlockStatement(); err != nil {
+			rdp.recordError(err)
+			rdp.consumeBlockStatement()
+			return
+		}
+	}
+
+	if catchName == nil && finally == nil {
+		rdp.recordError(internal.NewError(t.Data(), internal.ErrInvalidTry, internal.SyntaxErr))
+		return
+	}
+
+	tryStmt := ast.NewTryStatement(t, tryBlock, catchName, catchBlock, finally)
+	tryStmt.TryBlockEnd, tryStmt.CatchBlockEnd, tryStmt.FinallyBlockEnd = tryEnd, catchEnd, finallyEnd
+	stmt = tryStmt
+	return
+}
+
+// parses a braced block, end is the position of the closing brace
+func (rdp *RecursiveDescentParser) parseBlockStatement() (bStmt []ast.Statement, end token.Metadata, err error) {
 	bStmt = make([]ast.Statement, 0)
 	if rdp.currentToken().Type() != token.LeftBraceToken {
 		errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.LeftBraceToken, rdp.currentToken().Literal())
@@ -303,18 +540,21 @@ func (rdp *RecursiveDescentParser) parseBlockStatement() (bStmt []ast.Statement,
 	}
 
 	// consume right brace
+	end = rdp.currentToken().Data()
 	rdp.consume(1)
 	return
 }
 
 func (rdp *RecursiveDescentParser) parseFuncDeclarationStatement() (stmt ast.Statement) {
 	var (
-		t      = rdp.currentToken()
-		iden   string
-		params []ast.IdentifierExpression
-		body   []ast.Statement
-		pExprs []ast.Expression // function parameter expressions
-		err    error
+		t          = rdp.currentToken()
+		iden       string
+		params     []ast.IdentifierExpression
+		paramTypes []*ast.TypeAnnotation
+		returnType *ast.TypeAnnotation
+		body       []ast.Statement
+		bodyEnd    token.Metadata
+		err        error
 	)
 
 	if !rdp.expectTokenType(token.IdentifierToken) {
@@ -327,30 +567,157 @@ func (rdp *RecursiveDescentParser) parseFuncDeclarationStatement() (stmt ast.Sta
 	iden = rdp.currentToken().Literal()
 	rdp.consume(1) // consume function name
 
-	if pExprs, err = rdp.parseParameters(false); err != nil {
+	if params, paramTypes, err = rdp.parseFunctionParameters(); err != nil {
 		rdp.recordError(err)
 		rdp.consumeStatement()
 		return
 	}
 
-	params = make([]ast.IdentifierExpression, len(pExprs))
-
-	for i, pExpr := range pExprs {
-		if pExpr.Type() != ast.IdentifierExpressionNode {
-			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.IdentifierToken, pExpr.String())
-			rdp.recordError(internal.NewError(token.NewMetatadata(pExpr.LineNumber(), pExpr.FileName()), errMsg,
-				internal.SyntaxErr))
+	if rdp.currentToken().Type() == token.ColonToken {
+		rdp.consume(1) // consume colon
+		if returnType, err = rdp.parseTypeAnnotation(); err != nil {
+			rdp.recordError(err)
 			rdp.consumeStatement()
 			return
 		}
-		params[i] = *pExpr.(*ast.IdentifierExpression)
 	}
 
-	if body, err = rdp.parseBlockStatement(); err != nil {
+	if body, bodyEnd, err = rdp.parseBlockStatement(); err != nil {
 		rdp.recordError(err)
 		rdp.consumeBlockStatement()
 		return
 	}
-	stmt = ast.NewFuntionDeclarationStatement(t, iden, body, params)
+	fDec := ast.NewFuntionDeclarationStatement(t, iden, body, params, paramTypes, returnType)
+	fDec.BodyEnd = bodyEnd
+	stmt = fDec
+	return
+}
+
+// parses struct Name { field, ... };
+func (rdp *RecursiveDescentParser) parseStructDeclarationStatement() (stmt ast.Statement) {
+	var (
+		t      = rdp.currentToken()
+		iden   string
+		fields = make([]ast.IdentifierExpression, 0)
+	)
+
+	if !rdp.expectTokenType(token.IdentifierToken) {
+		rdp.consumeStatement()
+		return
+	}
+	rdp.consume(1) // consume struct token
+
+	iden = rdp.currentToken().Literal()
+	if !rdp.expectTokenType(token.LeftBraceToken) {
+		rdp.consumeStatement()
+		return
+	}
+	rdp.consume(2) // consume struct name and left brace
+
+	for rdp.currentToken().Type() != token.RightBraceToken {
+		if rdp.currentToken().Type() != token.IdentifierToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.IdentifierToken, rdp.currentToken().Literal())
+			rdp.recordError(internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr))
+			rdp.consumeBlockStatement()
+			return
+		}
+		fields = append(fields, *ast.NewIdentifierExpression(rdp.currentToken()))
+		rdp.consume(1) // consume field name
+
+		if rdp.currentToken().Type() == token.RightBraceToken {
+			break
+		}
+
+		if rdp.currentToken().Type() != token.CommaToken || rdp.peekToken().Type() == token.RightBraceToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.RightBraceToken, rdp.currentToken().Literal())
+			rdp.recordError(internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr))
+			rdp.consumeBlockStatement()
+			return
+		}
+		rdp.consume(1) // consume comma
+	}
+
+	rdp.consume(1) // consume right brace
+	stmt = ast.NewStructDeclarationStatement(t, iden, fields)
+	return
+}
+
+// parses a parenthesised list of parameter names, each optionally followed by a type annotation
+func (rdp *RecursiveDescentParser) parseFunctionParameters() (params []ast.IdentifierExpression,
+	paramTypes []*ast.TypeAnnotation, err error) {
+	params = make([]ast.IdentifierExpression, 0)
+	paramTypes = make([]*ast.TypeAnnotation, 0)
+
+	if rdp.currentToken().Type() != token.LeftParenToken {
+		errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.LeftParenToken, rdp.currentToken().Literal())
+		err = internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr)
+		return
+	}
+	rdp.consume(1) // consume left paren
+
+	for rdp.currentToken().Type() != token.RightParenToken {
+		var ta *ast.TypeAnnotation
+
+		if rdp.currentToken().Type() != token.IdentifierToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.IdentifierToken, rdp.currentToken().Literal())
+			err = internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		params = append(params, *ast.NewIdentifierExpression(rdp.currentToken()))
+		rdp.consume(1) // consume parameter name
+
+		if rdp.currentToken().Type() == token.ColonToken {
+			rdp.consume(1) // consume colon
+			if ta, err = rdp.parseTypeAnnotation(); err != nil {
+				return
+			}
+		}
+		paramTypes = append(paramTypes, ta)
+
+		if rdp.currentToken().Type() == token.RightParenToken {
+			break
+		}
+
+		if rdp.currentToken().Type() != token.CommaToken || rdp.peekToken().Type() == token.RightParenToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.RightParenToken, rdp.currentToken().Literal())
+			err = internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		rdp.consume(1) // consume comma
+	}
+
+	rdp.consume(1) // consume right paren
+	return
+}
+
+// parses a type name such as int, or an array type such as [int]
+func (rdp *RecursiveDescentParser) parseTypeAnnotation() (ta *ast.TypeAnnotation, err error) {
+	md := rdp.currentToken().Data()
+
+	switch rdp.currentToken().Type() {
+	case token.IdentifierToken:
+		ta = ast.NewTypeAnnotation(md, rdp.currentToken().Literal())
+		rdp.consume(1) // consume type name
+
+	case token.LeftBracketToken:
+		rdp.consume(1) // consume left bracket
+
+		var elem *ast.TypeAnnotation
+		if elem, err = rdp.parseTypeAnnotation(); err != nil {
+			return
+		}
+
+		if rdp.currentToken().Type() != token.RightBracketToken {
+			errMsg := fmt.Sprintf(internal.ErrInvalidToken, token.RightBracketToken, rdp.currentToken().Literal())
+			err = internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr)
+			return
+		}
+		rdp.consume(1) // consume right bracket
+		ta = ast.NewArrayTypeAnnotation(md, elem)
+
+	default:
+		errMsg := fmt.Sprintf(internal.ErrInvalidToken, "type", rdp.currentToken().Literal())
+		err = internal.NewError(rdp.currentToken().Data(), errMsg, internal.SyntaxErr)
+	}
 	return
 }
@@ -0,0 +1,338 @@
+package profile
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/token"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// the program's top level statements are attributed to a function, named as pprof names Go's entry point
+const mainFunction = "main"
+
+// the function a module's top level statements are attributed to
+const initFunction = "init"
+
+// sample values, in the order of the profile's sample types
+const (
+	callsValue = iota
+	statementsValue
+	timeValue
+	numValues
+)
+
+var sampleTypes = [numValues][2]string{
+	callsValue:      {"calls", "count"},
+	statementsValue: {"statements", "count"},
+	timeValue:       {"time", "nanoseconds"},
+}
+
+// Profiler records the calls to each function, the time spent in them and the statements evaluated on each line
+type Profiler interface {
+	// Options installs the profiler in an evaluator
+	Options() []eval.Option
+	// Write writes the profile in pprof's gzipped protocol buffer format
+	Write(w io.Writer) error
+}
+
+type function struct {
+	id        uint64
+	name      string
+	file      string // empty for native functions
+	startLine int
+}
+
+type location struct {
+	id       uint64
+	function *function
+	line     int
+}
+
+type locationKey struct {
+	function *function
+	line     int
+}
+
+// sample accumulates values for a call stack
+type sample struct {
+	locations []uint64 // innermost first
+	values    [numValues]int64
+}
+
+// frame is a call being evaluated, paused at line
+type frame struct {
+	function *function
+	line     int
+}
+
+// importKey is the name a file imports a module as
+type importKey struct {
+	file, name string
+}
+
+type profiler struct {
+	now          func() time.Time
+	start, last  time.Time
+	file         string                    // the program's file, whose functions are not qualified
+	declarations map[string]token.Metadata // user function declarations by qualified name
+	imports      map[importKey]string      // the module names of imports
+	functions    map[string]*function
+	locations    map[locationKey]*location
+	samples      map[string]*sample
+	order        []*sample // samples in the order they were first recorded
+	stack        []*frame
+}
+
+func NewProfiler(prog *ast.Program) Profiler {
+	return newProfiler(prog, time.Now)
+}
+
+func newProfiler(prog *ast.Program, now func() time.Time) *profiler {
+	p := &profiler{
+		now:          now,
+		file:         prog.FileName(),
+		declarations: make(map[string]token.Metadata),
+		imports:      make(map[importKey]string),
+		functions:    make(map[string]*function),
+		locations:    make(map[locationKey]*location),
+		samples:      make(map[string]*sample),
+		order:        make([]*sample, 0),
+	}
+
+	// functions are named by their module, unless declared by the program itself
+	for _, m := range module.Programs(prog) {
+		name := p.moduleName(m.FileName())
+		if m != prog {
+			// the evaluation of a module's top level is reported as a call to its init function
+			p.declarations[name+"."+initFunction] = token.NewMetatadata(1, m.FileName())
+		}
+
+		for _, stmt := range m.Statements {
+			if importStmt, ok := stmt.(*ast.ImportStatement); ok && importStmt.Program != nil {
+				p.imports[importKey{m.FileName(), importStmt.Name()}] = p.moduleName(importStmt.Program.FileName())
+			}
+		}
+
+		ast.Inspect(m, func(n ast.Node) bool {
+			if fDec, ok := n.(*ast.FunctionDeclarationStatement); ok {
+				p.declarations[qualify(name, fDec.Name)] = fDec
+			}
+			return true
+		})
+	}
+
+	// registered without a name, as the program may declare a function with the same name
+	main := &function{id: 1, name: mainFunction, file: prog.FileName(), startLine: 1}
+	p.functions[""] = main
+	p.stack = []*frame{{function: main, line: 1}}
+
+	p.start = p.now()
+	p.last = p.start
+	return p
+}
+
+func (p *profiler) Options() []eval.Option {
+	return []eval.Option{eval.WithHook(p.statement), eval.WithTracer(p)}
+}
+
+// moduleName is the name of the module of file, empty for the program's own file
+func (p *profiler) moduleName(file string) string {
+	if file == p.file {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// functionName qualifies the called function by the module that declares it, native functions are global
+func (p *profiler) functionName(fCall *ast.FunctionCallExpression, native bool) string {
+	switch {
+	case native:
+		return fCall.FunctionName
+	case fCall.Module != "":
+		return qualify(p.imports[importKey{fCall.FileName(), fCall.Module}], fCall.FunctionName)
+	default:
+		return qualify(p.moduleName(fCall.FileName()), fCall.FunctionName)
+	}
+}
+
+func qualify(module, name string) string {
+	if module == "" {
+		return name
+	}
+	return module + "." + name
+}
+
+func (p *profiler) function(name string, native bool) *function {
+	if f, ok := p.functions[name]; ok {
+		return f
+	}
+
+	f := &function{id: uint64(len(p.functions) + 1), name: name}
+	if md, ok := p.declarations[name]; ok && !native {
+		f.file, f.startLine = md.FileName(), md.LineNumber()
+	}
+	p.functions[name] = f
+	return f
+}
+
+func (p *profiler) location(f *function, line int) uint64 {
+	key := locationKey{f, line}
+	if l, ok := p.locations[key]; ok {
+		return l.id
+	}
+
+	l := &location{id: uint64(len(p.locations) + 1), function: f, line: line}
+	p.locations[key] = l
+	return l.id
+}
+
+// current returns the sample of the current call stack
+func (p *profiler) current() *sample {
+	ids := make([]uint64, len(p.stack))
+	keys := make([]string, len(p.stack))
+	for i := range p.stack {
+		fr := p.stack[len(p.stack)-1-i]
+		ids[i] = p.location(fr.function, fr.line)
+		keys[i] = fmt.Sprint(ids[i])
+	}
+
+	key := strings.Join(keys, ",")
+	if s, ok := p.samples[key]; ok {
+		return s
+	}
+
+	s := &sample{locations: ids}
+	p.samples[key] = s
+	p.order = append(p.order, s)
+	return s
+}
+
+// tick attributes the time since the last event to the current call stack
+func (p *profiler) tick() {
+	now := p.now()
+	p.current().values[timeValue] += int64(now.Sub(p.last))
+	p.last = now
+}
+
+func (p *profiler) statement(md token.Metadata, s eval.State) {
+	p.tick()
+	p.stack[len(p.stack)-1].line = md.LineNumber()
+	p.current().values[statementsValue]++
+}
+
+func (p *profiler) Call(fCall *ast.FunctionCallExpression, native bool) {
+	p.tick()
+	p.stack[len(p.stack)-1].line = fCall.LineNumber()
+
+	f := p.function(p.functionName(fCall, native), native)
+	p.stack = append(p.stack, &frame{function: f, line: f.startLine})
+	p.current().values[callsValue]++
+}
+
+func (p *profiler) Return(fCall *ast.FunctionCallExpression) {
+	p.tick()
+	if len(p.stack) > 1 {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+}
+
+func (p *profiler) Write(w io.Writer) error {
+	p.tick()
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(p.encode()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// encode builds the profile message, see
+// https://github.com/google/pprof/blob/master/proto/profile.proto
+func (p *profiler) encode() []byte {
+	strs := []string{""}
+	index := map[string]int64{"": 0}
+	str := func(s string) int64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = int64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+
+	valueType := func(t [2]string) func(enc *encoder) {
+		return func(enc *encoder) {
+			enc.int64(1, str(t[0]))
+			enc.int64(2, str(t[1]))
+		}
+	}
+
+	var enc encoder
+
+	for _, t := range sampleTypes {
+		enc.message(1, valueType(t))
+	}
+
+	for _, s := range p.order {
+		if s.values == [numValues]int64{} {
+			continue
+		}
+		s := s
+		enc.message(2, func(enc *encoder) {
+			enc.packed(1, s.locations)
+			values := make([]uint64, numValues)
+			for i, v := range s.values {
+				values[i] = uint64(v)
+			}
+			enc.packed(2, values)
+		})
+	}
+
+	locations := make([]*location, len(p.locations))
+	for _, l := range p.locations {
+		locations[l.id-1] = l
+	}
+	for _, l := range locations {
+		l := l
+		enc.message(4, func(enc *encoder) {
+			enc.uint64(1, l.id)
+			enc.message(4, func(enc *encoder) {
+				enc.uint64(1, l.function.id)
+				enc.int64(2, int64(l.line))
+			})
+		})
+	}
+
+	functions := make([]*function, len(p.functions))
+	for _, f := range p.functions {
+		functions[f.id-1] = f
+	}
+	for _, f := range functions {
+		f := f
+		enc.message(5, func(enc *encoder) {
+			enc.uint64(1, f.id)
+			enc.int64(2, str(f.name))
+			enc.int64(3, str(f.name))
+			enc.int64(4, str(f.file))
+			enc.int64(5, int64(f.startLine))
+		})
+	}
+
+	// strings are interned as the other fields are encoded, so the table is written last
+	enc.int64(9, p.start.UnixNano())
+	enc.int64(10, int64(p.last.Sub(p.start)))
+	enc.message(11, valueType(sampleTypes[timeValue]))
+	enc.int64(12, 1)
+	enc.int64(14, str(sampleTypes[timeValue][0]))
+
+	for _, s := range strs {
+		enc.string(6, s)
+	}
+	return enc.Bytes()
+}
@@ -0,0 +1,200 @@
+package profile
+
+import (
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/spf13/afero"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProgram = `func fib(n) {
+    if (n < 2) {
+        return n;
+    };
+    return fib(n - 1) + fib(n - 2);
+};
+var x = fib(4);
+var y = length([x]);
+`
+
+// decoded is a protocol buffer message, decoded without its schema
+type decoded map[int][]interface{} // varints are uint64, length delimited fields are []byte
+
+func decode(t *testing.T, b []byte) decoded {
+	msg := make(decoded)
+	for len(b) > 0 {
+		key, n := uvarint(t, b)
+		b = b[n:]
+
+		field, wireType := int(key>>3), int(key&7)
+		switch wireType {
+		case varintType:
+			v, n := uvarint(t, b)
+			msg[field] = append(msg[field], v)
+			b = b[n:]
+		case bytesType:
+			l, n := uvarint(t, b)
+			b = b[n:]
+			msg[field] = append(msg[field], b[:l])
+			b = b[l:]
+		default:
+			t.Fatalf("unexpected wire type %v", wireType)
+		}
+	}
+	return msg
+}
+
+func uvarint(t *testing.T, b []byte) (x uint64, n int) {
+	for shift := uint(0); n < len(b); shift += 7 {
+		x |= uint64(b[n]&0x7f) << shift
+		n++
+		if b[n-1] < 0x80 {
+			return
+		}
+	}
+	t.Fatal("truncated varint")
+	return
+}
+
+// packed decodes a repeated scalar field, which may be written packed or not
+func (msg decoded) packed(t *testing.T, field int) (xs []uint64) {
+	for _, v := range msg[field] {
+		switch v := v.(type) {
+		case uint64:
+			xs = append(xs, v)
+		case []byte:
+			for len(v) > 0 {
+				x, n := uvarint(t, v)
+				xs = append(xs, x)
+				v = v[n:]
+			}
+		}
+	}
+	return
+}
+
+func (msg decoded) uint(field int) uint64 {
+	if len(msg[field]) == 0 {
+		return 0
+	}
+	return msg[field][0].(uint64)
+}
+
+func (msg decoded) message(t *testing.T, field int) decoded {
+	if len(msg[field]) == 0 {
+		return decoded{}
+	}
+	return decode(t, msg[field][0].([]byte))
+}
+
+func TestProfile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "fib.yum", []byte(testProgram), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prog := testutil.ParseFile(t, fs, "fib.yum")
+
+	// each event takes a millisecond
+	clock := time.Unix(0, 0)
+	p := newProfiler(prog, func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	})
+	eval.NewEvaluator(p.Options()...).Evaluate(prog)
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := decode(t, b)
+
+	strs := make([]string, len(msg[6]))
+	for i, s := range msg[6] {
+		strs[i] = string(s.([]byte))
+	}
+	if len(strs) == 0 || strs[0] != "" {
+		t.Fatalf("expected the string table to start with the empty string, got %q", strs)
+	}
+
+	types := make([]string, 0)
+	for _, v := range msg[1] {
+		vt := decode(t, v.([]byte))
+		types = append(types, strs[vt.uint(1)]+"/"+strs[vt.uint(2)])
+	}
+	if expected := []string{"calls/count", "statements/count", "time/nanoseconds"}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected sample types %v, got %v", expected, types)
+	}
+
+	functions := make(map[uint64]string)
+	for _, v := range msg[5] {
+		f := decode(t, v.([]byte))
+		functions[f.uint(1)] = fmt.Sprintf("%v %v:%v", strs[f.uint(2)], strs[f.uint(4)], f.uint(5))
+	}
+	expectedFunctions := []string{"main fib.yum:1", "fib fib.yum:1", "length :0"}
+	for _, f := range expectedFunctions {
+		found := false
+		for _, g := range functions {
+			found = found || f == g
+		}
+		if !found {
+			t.Errorf("expected function %v in %v", f, functions)
+		}
+	}
+
+	// locations are reported as function:line
+	locations := make(map[uint64]string)
+	for _, v := range msg[4] {
+		l := decode(t, v.([]byte))
+		line := l.message(t, 4)
+		locations[l.uint(1)] = fmt.Sprintf("%v:%v", strings.Fields(functions[line.uint(1)])[0], line.uint(2))
+	}
+
+	// aggregate each value by the innermost location of its samples
+	calls := make(map[string]uint64)
+	statements := make(map[string]uint64)
+	var total uint64
+	for _, v := range msg[2] {
+		s := decode(t, v.([]byte))
+		ids, values := s.packed(t, 1), s.packed(t, 2)
+		if len(values) != 3 {
+			t.Fatalf("expected 3 values, got %v", values)
+		}
+
+		leaf := locations[ids[0]]
+		calls[strings.Split(leaf, ":")[0]] += values[callsValue]
+		if values[statementsValue] != 0 {
+			statements[leaf] += values[statementsValue]
+		}
+		total += values[timeValue]
+	}
+
+	if expected := map[string]uint64{"main": 0, "fib": 9, "length": 1}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+
+	expectedStatements := map[string]uint64{"main:7": 1, "main:8": 1, "fib:2": 9, "fib:3": 5, "fib:5": 4}
+	if !reflect.DeepEqual(statements, expectedStatements) {
+		t.Errorf("expected statements %v, got %v", expectedStatements, statements)
+	}
+
+	// all of the program's time is attributed to some call stack
+	if duration := msg.uint(10); total != duration || duration == 0 {
+		t.Errorf("expected samples to total the duration %v, got %v", duration, total)
+	}
+}
@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"bytes"
+)
+
+// protobuf wire types
+const (
+	varintType = 0
+	bytesType  = 2
+)
+
+// encoder writes the protocol buffer encoding of a message, fields are written in the order they are added
+type encoder struct {
+	bytes.Buffer
+}
+
+func (enc *encoder) varint(x uint64) {
+	for x >= 0x80 {
+		enc.WriteByte(byte(x) | 0x80)
+		x >>= 7
+	}
+	enc.WriteByte(byte(x))
+}
+
+func (enc *encoder) key(field int, wireType int) {
+	enc.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+// uint64 writes a scalar field, omitting the default value
+func (enc *encoder) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	enc.key(field, varintType)
+	enc.varint(x)
+}
+
+func (enc *encoder) int64(field int, x int64) {
+	enc.uint64(field, uint64(x))
+}
+
+// bytes writes a length delimited field, even if it is empty, as repeated strings must keep their positions
+func (enc *encoder) bytes(field int, b []byte) {
+	enc.key(field, bytesType)
+	enc.varint(uint64(len(b)))
+	enc.Write(b)
+}
+
+func (enc *encoder) string(field int, s string) {
+	enc.bytes(field, []byte(s))
+}
+
+func (enc *encoder) message(field int, write func(enc *encoder)) {
+	var sub encoder
+	write(&sub)
+	enc.bytes(field, sub.Bytes())
+}
+
+// packed writes a repeated scalar field
+func (enc *encoder) packed(field int, xs []uint64) {
+	if len(xs) == 0 {
+		return
+	}
+
+	var sub encoder
+	for _, x := range xs {
+		sub.varint(x)
+	}
+	enc.bytes(field, sub.Bytes())
+}
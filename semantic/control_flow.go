@@ -0,0 +1,126 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/token"
+	"fmt"
+)
+
+// terminates is true if control can never fall through to the statement following stmts
+func terminates(stmts []ast.Statement) bool {
+	for _, s := range stmts {
+		if statementTerminates(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func statementTerminates(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement:
+		return true
+	case *ast.IfStatement:
+		return terminates(s.IfBlock) && terminates(s.ElseBlock)
+	case *ast.WhileStatement:
+		// yum has no break statement, so a while (true) loop can only be left by returning
+		b, ok := s.Condition.(*ast.BooleanExpression)
+		return ok && b.Value
+	case *ast.TryStatement:
+		// an error raised by the try block is passed to the catch block, or raised again if there is none
+		return terminates(s.FinallyBlock) ||
+			terminates(s.TryBlock) && (s.CatchName == nil || terminates(s.CatchBlock))
+	}
+	return false
+}
+
+// controlFlowAnalyser reports unreachable statements, and functions that return a value on some paths but not on
+// others
+type controlFlowAnalyser struct {
+	warnings []error
+}
+
+func newControlFlowAnalyser() *controlFlowAnalyser {
+	return &controlFlowAnalyser{
+		warnings: make([]error, 0),
+	}
+}
+
+func (cfa *controlFlowAnalyser) Analyse(prog *ast.Program) []error {
+	cfa.analyseBlock(prog.Statements)
+	return cfa.warnings
+}
+
+func (cfa *controlFlowAnalyser) analyseBlock(stmts []ast.Statement) {
+	for i, s := range stmts {
+		cfa.analyseStatement(s)
+
+		if statementTerminates(s) && i+1 < len(stmts) {
+			// only the first unreachable statement is reported, though nested blocks are still analysed
+			cfa.recordWarning(stmts[i+1], internal.ErrUnreachableCode)
+			for _, unreachable := range stmts[i+1:] {
+				cfa.analyseStatement(unreachable)
+			}
+			return
+		}
+	}
+	return
+}
+
+func (cfa *controlFlowAnalyser) analyseStatement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.IfStatement:
+		cfa.analyseBlock(s.IfBlock)
+		cfa.analyseBlock(s.ElseBlock)
+	case *ast.WhileStatement:
+		cfa.analyseBlock(s.Block)
+	case *ast.TryStatement:
+		cfa.analyseBlock(s.TryBlock)
+		cfa.analyseBlock(s.CatchBlock)
+		cfa.analyseBlock(s.FinallyBlock)
+	case *ast.FunctionDeclarationStatement:
+		cfa.analyseBlock(s.Body)
+		cfa.analyseReturns(s)
+	}
+	return
+}
+
+func (cfa *controlFlowAnalyser) analyseReturns(fDec *ast.FunctionDeclarationStatement) {
+	var valued, bare bool
+
+	for _, rS := range returnStatements(fDec.Body) {
+		if rS.Expression != nil {
+			valued = true
+		} else {
+			bare = true
+		}
+	}
+
+	if valued && (bare || !terminates(fDec.Body)) {
+		cfa.recordWarning(fDec, fmt.Sprintf(internal.ErrInconsistentReturn, fDec.Name))
+	}
+	return
+}
+
+// returnStatements collects the return statements of a function body, excluding those of nested functions
+func returnStatements(stmts []ast.Statement) (rSs []*ast.ReturnStatement) {
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ReturnStatement:
+				rSs = append(rSs, n)
+			case *ast.FunctionDeclarationStatement:
+				return false
+			}
+			return true
+		})
+	}
+	return
+}
+
+func (cfa *controlFlowAnalyser) recordWarning(md token.Metadata, msg string) {
+	cfa.warnings = append(cfa.warnings, internal.NewDiagnostic(token.NewMetatadata(md.LineNumber(), md.FileName()),
+		msg, internal.SemanticErr, internal.WarningSeverity))
+	return
+}
@@ -0,0 +1,93 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"fmt"
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+func TestControlFlowAnalysis(t *testing.T) {
+	tCs := []struct {
+		input    []byte
+		warnings []string
+	}{
+		{
+			[]byte("func f(a) { if (a) { return 1; }; return 2; }; print(f(true));"),
+			[]string{},
+		},
+		{
+			[]byte("func f() { return 1;\nprint(2); }; print(f());"),
+			[]string{"2 | unreachable code"},
+		},
+		{
+			[]byte("func f(a) { if (a) { return 1; } else { return 2; };\nprint(3); }; print(f(true));"),
+			[]string{"2 | unreachable code"},
+		},
+		{
+			[]byte("func f(a) { if (a) { return 1; };\nprint(3); }; print(f(true));"),
+			[]string{"returns a value on some paths but not on others"}, // falls through when a is false
+		},
+		{
+			[]byte("func f(a) { if (a) { return 1; }; return; }; print(f(true));"),
+			[]string{"f returns a value on some paths but not on others"},
+		},
+		{
+			[]byte("func f(a) { if (a) { return; }; print(a); }; f(true);"),
+			[]string{}, // never returns a value
+		},
+		{
+			[]byte("func f(a) { while (a) { return 1; }; }; print(f(true));"),
+			[]string{"f returns a value on some paths but not on others"}, // loop may not run
+		},
+		{
+			[]byte("func f() { while (true) { return 1; }; }; print(f());"),
+			[]string{}, // while (true) can only be left by returning
+		},
+		{
+			[]byte("func f() { while (true) { print(1); };\nreturn 1; }; print(f());"),
+			[]string{"2 | unreachable code"},
+		},
+		{
+			[]byte("func f() { var x = 1; while (x < 2) { return x;\nx = 3; }; return 0; }; print(f());"),
+			[]string{"2 | unreachable code"},
+		},
+		{
+			[]byte("func f() { func g() { return 1; }; print(g()); return; }; f();"),
+			[]string{}, // nested function returns are separate
+		},
+		{
+			[]byte("func f(a) { if (a) { throw a; };\nreturn 1; }; func g() { throw 1;\nprint(1); }; print(f(true)); g();"),
+			[]string{"3 | unreachable code"},
+		},
+		{
+			[]byte("func f() { try { return 1; } catch (e) { print(e); }; }; func g() { try { print(1); } finally { return 2; }; };" +
+				" func h() { try { return 1; } catch (e) { throw e; }; }; print(f()); print(g()); print(h());"),
+			[]string{"f returns a value on some paths but not on others"}, // the catch block of f falls through
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("test_files/control_flow/test_%v.txt", i), tC.input)
+
+		sA := NewSemanticAnalyser()
+		if errs := sA.Analyse(prog); len(errs) != 0 {
+			t.Fatalf(internal.ErrInvalidSemanticsEvaluationTestCases, i+1, len(errs))
+		}
+
+		warnings := newControlFlowAnalyser().Analyse(prog)
+		if len(warnings) != len(tC.warnings) {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest+" %v", i+1, len(tC.warnings), len(warnings), warnings)
+			continue
+		}
+
+		for j, w := range warnings {
+			if !strings.Contains(w.Error(), tC.warnings[j]) {
+				t.Errorf("test case %v | expected warning containing %q, received %q", i+1, tC.warnings[j], w.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/token"
+	"fmt"
+)
+
+type lintVariable struct {
+	name     string
+	md       token.Metadata
+	function string // name of the declaring function, if the variable is a parameter
+	read     bool
+}
+
+// pendingWrites maps each variable to the assignments whose values may not have been read yet
+type pendingWrites map[*lintVariable]map[*ast.AssignmentStatement]bool
+
+// linter reports suspicious but valid code as warnings. It assumes that the program has passed semantic analysis
+type linter struct {
+	methodRouter map[ast.NodeType]analysisMethod
+	scopes       []map[string]*lintVariable
+	cachedScopes [][]map[string]*lintVariable
+	functions    []*ast.FunctionDeclarationStatement // enclosing function declarations, innermost last
+
+	// assignments are tracked across control flow to find values that are overwritten before being read
+	pending       pendingWrites
+	assignments   []*ast.AssignmentStatement
+	assignedVars  map[*ast.AssignmentStatement]*lintVariable
+	read          map[*ast.AssignmentStatement]bool
+	declaredFuncs []*ast.FunctionDeclarationStatement
+	calledFuncs   map[string]bool
+
+	// while loop bodies are walked a second time to model the loop's back edge, without reporting twice
+	replaying bool
+	warnings  []error
+}
+
+func newLinter() (l *linter) {
+	l = &linter{
+		scopes:       []map[string]*lintVariable{{}},
+		pending:      make(pendingWrites),
+		assignedVars: make(map[*ast.AssignmentStatement]*lintVariable),
+		read:         make(map[*ast.AssignmentStatement]bool),
+		calledFuncs:  make(map[string]bool),
+		warnings:     make([]error, 0),
+	}
+
+	l.methodRouter = map[ast.NodeType]analysisMethod{
+		ast.ProgramNode:                      l.lintProgram,
+		ast.VarStatementNode:                 l.lintVarStatement,
+		ast.AssignmentStatementNode:          l.lintAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     l.lintFieldAssignmentStatement,
+		ast.ReturnStatementNode:              l.lintReturnStatement,
+		ast.IfStatementNode:                  l.lintIfStatement,
+		ast.WhileStatementNode:               l.lintWhileStatement,
+		ast.ThrowStatementNode:               l.lintThrowStatement,
+		ast.TryStatementNode:                 l.lintTryStatement,
+		ast.FunctionDeclarationStatementNode: l.lintFunctionDeclarationStatement,
+		ast.FunctionCallStatementNode:        l.lintFunctionCallStatement,
+	}
+
+	return
+}
+
+func (l *linter) Lint(node ast.Node) []error {
+	l.lint(node)
+	return l.warnings
+}
+
+func (l *linter) lint(node ast.Node) {
+	if method, ok := l.methodRouter[node.Type()]; ok {
+		method(node)
+	} else if _, ok := node.(ast.Expression); ok {
+		l.lintExpression(node)
+	}
+	return
+}
+
+func (l *linter) lintProgram(node ast.Node) {
+	l.lintBlock(node.(*ast.Program).Statements)
+	l.exitScope()
+
+	// exported functions may be called by the files that import the program
+	for _, fDec := range l.declaredFuncs {
+		if !l.calledFuncs[fDec.Name] && !fDec.Exported {
+			l.recordWarning(fDec, fmt.Sprintf(internal.ErrUnusedFunction, fDec.Name))
+		}
+	}
+
+	for _, a := range l.assignments {
+		// unused variables have already been reported
+		if !l.read[a] && l.assignedVars[a].read {
+			l.recordWarning(a, fmt.Sprintf(internal.ErrUnreadAssignment, a.IdentifierNode.Name))
+		}
+	}
+	return
+}
+
+func (l *linter) lintBlock(stmts []ast.Statement) {
+	for _, s := range stmts {
+		l.lint(s)
+	}
+	return
+}
+
+func (l *linter) lintNestedBlock(stmts []ast.Statement) {
+	l.scopes = append(l.scopes, map[string]*lintVariable{})
+	l.lintBlock(stmts)
+	l.exitScope()
+	return
+}
+
+// lintExpression reads the variables used by an expression, and the functions it calls. Expressions have no control
+// flow, so are walked in source order
+func (l *linter) lintExpression(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IdentifierExpression:
+			l.readVar(n.Name)
+		case *ast.ArrayIndexExpression:
+			l.readVar(n.ArrayName)
+		case *ast.StructExpression:
+			// fields are not variables
+			for _, v := range n.Values {
+				l.lintExpression(v)
+			}
+			return false
+		case *ast.InfixExpression:
+			l.lintComparison(n)
+		case *ast.FunctionCallExpression:
+			// recursive calls do not count as uses, and calls to other modules do not use this module's functions
+			if n.Module == "" && (len(l.functions) == 0 || l.functions[len(l.functions)-1].Name != n.FunctionName) {
+				l.calledFuncs[n.FunctionName] = true
+			}
+		}
+		return true
+	})
+	return
+}
+
+func (l *linter) lintComparison(iExpr *ast.InfixExpression) {
+	switch iExpr.Token.Type() {
+	case token.EqualToken, token.NotEqualToken, token.LThanToken, token.LThanEqualToken, token.GThanToken,
+		token.GThanEqualToken:
+		// function calls may return a different value each time
+		if iExpr.LeftExpression.String() == iExpr.RightExpression.String() && !containsCall(iExpr.LeftExpression) {
+			l.recordWarning(iExpr, fmt.Sprintf(internal.ErrSelfComparison, iExpr.String()))
+		}
+	}
+	return
+}
+
+func containsCall(expr ast.Expression) (found bool) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FunctionCallExpression); ok {
+			found = true
+		}
+		return !found
+	})
+	return
+}
+
+func (l *linter) lintFunctionCallStatement(node ast.Node) {
+	l.lint(node.(*ast.FunctionCallStatement).FunctionCallExpression)
+	return
+}
+
+func (l *linter) lintVarStatement(node ast.Node) {
+	stmt := node.(*ast.VarStatement)
+	l.lint(stmt.Expression)
+
+	if shadowed, ok := l.lookupOuter(stmt.IdentifierNode.Name); ok {
+		l.recordWarning(stmt, fmt.Sprintf(internal.ErrShadowedVariable, stmt.IdentifierNode.Name,
+			shadowed.md.LineNumber()))
+	}
+
+	l.scopes[len(l.scopes)-1][stmt.IdentifierNode.Name] = &lintVariable{
+		name: stmt.IdentifierNode.Name,
+		md:   stmt.Metadata,
+		read: stmt.Exported, // by the files that import the program
+	}
+	return
+}
+
+func (l *linter) lintAssignmentStatement(node ast.Node) {
+	stmt := node.(*ast.AssignmentStatement)
+	l.lint(stmt.Expression)
+
+	v, ok := l.lookup(stmt.IdentifierNode.Name)
+	if !ok {
+		return
+	}
+
+	if _, seen := l.assignedVars[stmt]; !seen {
+		l.assignments = append(l.assignments, stmt)
+	}
+	l.assignedVars[stmt] = v
+	l.pending[v] = map[*ast.AssignmentStatement]bool{stmt: true}
+	return
+}
+
+// assigning a field uses the struct held by the variable, rather than overwriting the variable
+func (l *linter) lintFieldAssignmentStatement(node ast.Node) {
+	stmt := node.(*ast.FieldAssignmentStatement)
+	l.lint(stmt.Expression)
+	l.lint(stmt.Field.Target)
+	return
+}
+
+func (l *linter) lintReturnStatement(node ast.Node) {
+	if rS := node.(*ast.ReturnStatement); rS.Expression != nil {
+		l.lint(rS.Expression)
+	}
+
+	// nothing after a return is reachable, so pending values can never be read
+	l.pending = make(pendingWrites)
+	return
+}
+
+func (l *linter) lintIfStatement(node ast.Node) {
+	ifStmt := node.(*ast.IfStatement)
+	l.lint(ifStmt.Condition)
+
+	before := l.pending.copy()
+	l.lintNestedBlock(ifStmt.IfBlock)
+	afterIf := l.pending
+
+	l.pending = before
+	l.lintNestedBlock(ifStmt.ElseBlock)
+	l.pending.union(afterIf)
+	return
+}
+
+func (l *linter) lintWhileStatement(node ast.Node) {
+	wStmt := node.(*ast.WhileStatement)
+	l.lint(wStmt.Condition)
+
+	before := l.pending.copy()
+	l.lintNestedBlock(wStmt.Block)
+	l.pending.union(before)
+
+	// values written by one iteration may be read by the next
+	replaying := l.replaying
+	l.replaying = true
+	l.lint(wStmt.Condition)
+	l.lintNestedBlock(wStmt.Block)
+	l.replaying = replaying
+
+	l.pending.union(before)
+	return
+}
+
+func (l *linter) lintThrowStatement(node ast.Node) {
+	l.lint(node.(*ast.ThrowStatement).Expression)
+
+	// nothing after a throw is reachable, values written within a try block are counted as read by its catch block
+	l.pending = make(pendingWrites)
+	return
+}
+
+func (l *linter) lintTryStatement(node ast.Node) {
+	tStmt := node.(*ast.TryStatement)
+
+	before := l.pending.copy()
+	tried := len(l.assignments)
+	l.lintNestedBlock(tStmt.TryBlock)
+
+	if tStmt.CatchName != nil {
+		// the catch block may be entered from any statement of the try block, so its values may all be read
+		for _, a := range l.assignments[tried:] {
+			l.read[a] = true
+		}
+		afterTry := l.pending
+		l.pending = before
+		l.pending.union(afterTry)
+
+		// the caught error need not be used
+		l.scopes = append(l.scopes, map[string]*lintVariable{
+			tStmt.CatchName.Name: {name: tStmt.CatchName.Name, md: tStmt.CatchName.Metadata, read: true},
+		})
+		l.lintBlock(tStmt.CatchBlock)
+		l.exitScope()
+		l.pending.union(afterTry)
+	}
+
+	l.lintNestedBlock(tStmt.FinallyBlock)
+	return
+}
+
+func (l *linter) lintFunctionDeclarationStatement(node ast.Node) {
+	fDec := node.(*ast.FunctionDeclarationStatement)
+	if !l.replaying {
+		l.declaredFuncs = append(l.declaredFuncs, fDec)
+	}
+
+	params := make(map[string]*lintVariable, len(fDec.Parameters))
+	for _, p := range fDec.Parameters {
+		params[p.Name] = &lintVariable{
+			name:     p.Name,
+			md:       p.Metadata,
+			function: fDec.Name,
+		}
+	}
+
+	// functions have their own namespace
+	cachedPending := l.pending
+	l.cachedScopes = append(l.cachedScopes, l.scopes)
+	l.scopes = []map[string]*lintVariable{params}
+	l.pending = make(pendingWrites)
+	l.functions = append(l.functions, fDec)
+
+	l.lintBlock(fDec.Body)
+	l.exitScope()
+
+	l.functions = l.functions[:len(l.functions)-1]
+	l.pending = cachedPending
+	l.scopes = l.cachedScopes[len(l.cachedScopes)-1]
+	l.cachedScopes = l.cachedScopes[:len(l.cachedScopes)-1]
+	return
+}
+
+func (l *linter) readVar(name string) {
+	if v, ok := l.lookup(name); ok {
+		v.read = true
+		for a := range l.pending[v] {
+			l.read[a] = true
+		}
+		delete(l.pending, v)
+	}
+	return
+}
+
+func (l *linter) lookup(name string) (v *lintVariable, ok bool) {
+	for s := len(l.scopes) - 1; s >= 0 && !ok; s-- {
+		v, ok = l.scopes[s][name]
+	}
+	return
+}
+
+// lookupOuter searches every scope of the current namespace except the innermost
+func (l *linter) lookupOuter(name string) (v *lintVariable, ok bool) {
+	for s := len(l.scopes) - 2; s >= 0 && !ok; s-- {
+		v, ok = l.scopes[s][name]
+	}
+	return
+}
+
+// exitScope reports the variables of the innermost scope that were never read
+func (l *linter) exitScope() {
+	for _, v := range l.scopes[len(l.scopes)-1] {
+		if v.read {
+			continue
+		}
+
+		if v.function != "" {
+			l.recordWarning(v.md, fmt.Sprintf(internal.ErrUnusedParameter, v.name, v.function))
+		} else {
+			l.recordWarning(v.md, fmt.Sprintf(internal.ErrUnusedVariable, v.name))
+		}
+	}
+	l.scopes = l.scopes[:len(l.scopes)-1]
+	return
+}
+
+func (l *linter) recordWarning(md token.Metadata, msg string) {
+	if !l.replaying {
+		l.warnings = append(l.warnings, internal.NewDiagnostic(token.NewMetatadata(md.LineNumber(), md.FileName()),
+			msg, internal.SemanticErr, internal.WarningSeverity))
+	}
+	return
+}
+
+func (p pendingWrites) copy() pendingWrites {
+	cp := make(pendingWrites, len(p))
+	for v, as := range p {
+		cp[v] = make(map[*ast.AssignmentStatement]bool, len(as))
+		for a := range as {
+			cp[v][a] = true
+		}
+	}
+	return cp
+}
+
+// union adds the pending writes of an alternate control flow path
+func (p pendingWrites) union(o pendingWrites) {
+	for v, as := range o {
+		if _, ok := p[v]; !ok {
+			p[v] = make(map[*ast.AssignmentStatement]bool, len(as))
+		}
+		for a := range as {
+			p[v][a] = true
+		}
+	}
+	return
+}
@@ -0,0 +1,133 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"fmt"
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+func TestSemanticAnalyserWarnings(t *testing.T) {
+	tCs := []struct {
+		input    []byte
+		warnings []string
+	}{
+		{
+			[]byte("var x = 3; print(x);"),
+			[]string{},
+		},
+		{
+			[]byte("var x = 3;"),
+			[]string{"x declared but not used"},
+		},
+		{
+			[]byte("func add(a, b) { return a; }; print(add(1, 2));"),
+			[]string{"parameter b of add is not used"},
+		},
+		{
+			[]byte("func add(a, b) { return a + b; };"),
+			[]string{"add declared but never called"},
+		},
+		{
+			[]byte("func fact(n) { if (n == 1) { return 1; }; return n * fact(n - 1); };"),
+			[]string{"fact declared but never called"}, // recursive calls are not uses
+		},
+		{
+			[]byte("var x = 1; if (true) { var x = 2; print(x); }; print(x);"),
+			[]string{"x shadows the variable declared on line 1"},
+		},
+		{
+			[]byte("func f(a) { if (true) { var a = 2; print(a); }; print(a); }; f(1);"),
+			[]string{"a shadows the variable declared on line 1"},
+		},
+		{
+			[]byte("var x = 1; x = 2; x = 3; print(x);"),
+			[]string{"value assigned to x is never read"},
+		},
+		{
+			[]byte("var x = 1; if (true) { x = 2; } else { print(x); }; print(x);"),
+			[]string{}, // read on one path
+		},
+		{
+			[]byte("var x = 1; print(x); if (true) { x = 2; };"),
+			[]string{"value assigned to x is never read"},
+		},
+		{
+			[]byte("var i = 0; while (i < 3) { i = i + 1; };"),
+			[]string{}, // read by the loop condition
+		},
+		{
+			[]byte("var x = 0; var i = 0; while (i < 3) { print(x); x = i; i = i + 1; };"),
+			[]string{}, // read by the next iteration
+		},
+		{
+			[]byte("func f() { var x = 1; print(x); x = 2; return x; }; f();"),
+			[]string{},
+		},
+		{
+			[]byte("func f() { var x = 1; print(x); x = 2; return 1; }; f();"),
+			[]string{"value assigned to x is never read"},
+		},
+		{
+			[]byte("var x = 1; if (x == x) { print(1); };"),
+			[]string{"(x == x) compares a value with itself"},
+		},
+		{
+			[]byte("var x = [1]; if (x[0] <= x[0]) { print(1); };"),
+			[]string{"compares a value with itself"},
+		},
+		{
+			[]byte("func f() { return 1; }; if (f() == f()) { print(1); };"),
+			[]string{}, // calls may return different values
+		},
+		{
+			[]byte("var x = 1;\nvar y = 2;\nprint(y);"),
+			[]string{"x declared but not used"},
+		},
+		{
+			[]byte("export var x = 3; export func add(a, b) { return a + b; };"),
+			[]string{}, // used by the files that import the program
+		},
+		{
+			[]byte("var x = 1; try { x = 2; print(x); x = 3; } catch (e) { print(x); };"),
+			[]string{}, // the catch block may read any value written by the try block, and e need not be used
+		},
+		{
+			[]byte("var x = 1; try { x = 2; } finally { print(1); }; x = 3; print(x);"),
+			[]string{"value assigned to x is never read"},
+		},
+		{
+			[]byte("struct P { x }; var p = P(1); var q = P{x: 2}; p.x = 2; func f(a) { return a.x; }; print(f(q));"),
+			[]string{}, // assigning a field uses the struct, as does reading one
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("test_files/warnings/test_%v.txt", i), tC.input)
+
+		sA := NewSemanticAnalyser()
+		if errs := sA.Analyse(prog); len(errs) != 0 {
+			t.Fatalf(internal.ErrInvalidSemanticsEvaluationTestCases, i+1, len(errs))
+		}
+
+		warnings := sA.Warnings()
+		if len(warnings) != len(tC.warnings) {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest+" %v", i+1, len(tC.warnings), len(warnings), warnings)
+			continue
+		}
+
+		for j, w := range warnings {
+			if w.(*internal.Error).Severity() != internal.WarningSeverity {
+				t.Errorf("test case %v | expected severity %v, received %v", i+1, internal.WarningSeverity,
+					w.(*internal.Error).Severity())
+			}
+
+			if !strings.Contains(w.Error(), tC.warnings[j]) {
+				t.Errorf("test case %v | expected warning containing %q, received %q", i+1, tC.warnings[j], w.Error())
+			}
+		}
+	}
+}
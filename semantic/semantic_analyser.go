@@ -3,29 +3,63 @@ package semantic
 import (
 	"github.com/EricNRodriguez/yum/ast"
 	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/lexer"
 	"github.com/EricNRodriguez/yum/object"
 	"github.com/EricNRodriguez/yum/symbol_table"
+	"github.com/EricNRodriguez/yum/token"
 	"fmt"
+	"sort"
 )
 
 type analysisMethod func(node ast.Node)
 
 type SemanticAnalyser interface {
-	Analyse(node ast.Node) []error
+	Analyse(node ast.Node) []error // fatal errors
+	Warnings() []error             // non fatal diagnostics, available after Analyse
+}
+
+type Option func(*semanticAnalyser)
+
+// WithCapabilities restricts the native functions a program may call to those whose
+// capabilities are contained in cs. All capabilities are granted by default
+func WithCapabilities(cs object.CapabilitySet) Option {
+	return func(sA *semanticAnalyser) {
+		sA.capabilities = cs
+	}
+}
+
+// WithNativeFunctions makes fs available to the program, in addition to the standard native functions
+func WithNativeFunctions(fs map[string]*object.NativeFunction) Option {
+	return func(sA *semanticAnalyser) {
+		for _, f := range fs {
+			sA.SetNativeFunc(f)
+		}
+	}
 }
 
 type semanticAnalyser struct {
 	symbol_table.SymbolTable
+	opts             []Option
+	modules          map[*ast.Program]*symbol_table.Module // imported modules, each analysed once
 	semanticErrors   []error
+	warnings         []error
 	methodRouter     map[ast.NodeType]analysisMethod
 	currentStatement ast.NodeType
+	capabilities     object.CapabilitySet
 }
 
-func NewSemanticAnalyser() (sA *semanticAnalyser) {
+func NewSemanticAnalyser(opts ...Option) (sA *semanticAnalyser) {
 	sA = &semanticAnalyser{
 		SymbolTable:    symbol_table.NewSymbolTable(),
+		opts:           opts,
+		modules:        make(map[*ast.Program]*symbol_table.Module),
 		semanticErrors: make([]error, 0),
 		methodRouter:   make(map[ast.NodeType]analysisMethod),
+		capabilities:   object.AllCapabilities,
+	}
+
+	for _, opt := range opts {
+		opt(sA)
 	}
 
 	sA.methodRouter = map[ast.NodeType]analysisMethod{
@@ -33,16 +67,25 @@ func NewSemanticAnalyser() (sA *semanticAnalyser) {
 		ast.PrefixExpressionNode:             sA.analysePrefixExpression,
 		ast.InfixExpressionNode:              sA.analyseInfixExpression,
 		ast.FunctionCallExpressionNode:       sA.analyseFunctionCallExpression,
+		ast.SafeCallExpressionNode:           sA.analyseSafeCallExpression,
 		ast.VarStatementNode:                 sA.analyseVarStatement,
 		ast.ReturnStatementNode:              sA.analyseReturnStatement,
 		ast.IfStatementNode:                  sA.analyseIfStatement,
 		ast.WhileStatementNode:               sA.analyseWhileStatement,
+		ast.ThrowStatementNode:               sA.analyseThrowStatement,
+		ast.TryStatementNode:                 sA.analyseTryStatement,
 		ast.FunctionDeclarationStatementNode: sA.analyseFunctionDeclarationStatement,
 		ast.FunctionCallStatementNode:        sA.analyseFunctionCallStatement,
 		ast.AssignmentStatementNode:          sA.analyseAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     sA.analyseFieldAssignmentStatement,
+		ast.StructDeclarationStatementNode:   sA.analyseStructDeclarationStatement,
 		ast.IdentifierExpressionNode:         sA.analyseIdentifierExpression,
+		ast.FieldExpressionNode:              sA.analyseFieldExpression,
 		ast.ArrayIndexExpressionNode:         sA.analyseArrayIndexExpression,
 		ast.ArrayExpressionNode:              sA.analyseArrayExpression,
+		ast.StructExpressionNode:             sA.analyseStructExpression,
+		ast.InterpolatedStringExpressionNode: sA.analyseInterpolatedStringExpression,
+		ast.ImportStatementNode:              sA.analyseImportStatement,
 	}
 
 	return
@@ -50,9 +93,29 @@ func NewSemanticAnalyser() (sA *semanticAnalyser) {
 
 func (sA *semanticAnalyser) Analyse(node ast.Node) []error {
 	sA.analyse(node)
+
+	// values must match their type annotations, checked once the program is otherwise valid
+	if len(sA.semanticErrors) == 0 && hasTypeAnnotations(node) {
+		tC := NewTypeChecker()
+		tC.annotationsOnly = true
+		sA.semanticErrors = append(sA.semanticErrors, tC.Analyse(node)...)
+	}
+
+	if prog, ok := node.(*ast.Program); ok && len(sA.semanticErrors) == 0 {
+		sA.warnings = append(newLinter().Lint(prog), newControlFlowAnalyser().Analyse(prog)...)
+
+		// unused variables are reported when their scope closes, order warnings by position instead
+		sort.SliceStable(sA.warnings, func(i, j int) bool {
+			return sA.warnings[i].(*internal.Error).LineNumber() < sA.warnings[j].(*internal.Error).LineNumber()
+		})
+	}
 	return sA.semanticErrors
 }
 
+func (sA *semanticAnalyser) Warnings() []error {
+	return sA.warnings
+}
+
 func (sA *semanticAnalyser) analyse(node ast.Node) {
 	if method, ok := sA.methodRouter[node.Type()]; ok {
 		method(node)
@@ -80,12 +143,83 @@ func (sA *semanticAnalyser) analyseIdentifierExpression(node ast.Node) {
 	return
 }
 
+func (sA *semanticAnalyser) analyseFieldExpression(node ast.Node) {
+	f := node.(*ast.FieldExpression)
+
+	// the name of an import qualifies the variables of the module
+	if q, ok := f.Qualifier(); ok {
+		if m, ok := sA.GetImport(q); ok {
+			if _, ok := m.Globals[f.Field]; !ok {
+				errMsg := fmt.Sprintf(internal.ErrUndeclaredIdentifierNode, f.String())
+				sA.recordError(internal.NewError(f.Metadata, errMsg, internal.SemanticErr))
+			} else if !m.Exports[f.Field] {
+				sA.recordError(internal.NewError(f.Metadata, fmt.Sprintf(internal.ErrUnexported, f.Field, q),
+					internal.SemanticErr))
+			}
+			return
+		}
+	}
+
+	sA.analyse(f.Target)
+	sA.analyseField(f)
+	return
+}
+
+// checks that the struct held by the variable f reads from has the field, if the struct is known
+func (sA *semanticAnalyser) analyseField(f *ast.FieldExpression) {
+	iden, ok := f.Target.(*ast.IdentifierExpression)
+	if !ok {
+		return
+	}
+
+	v, _ := sA.GetVar(iden.Name)
+	if s, ok := v.(*object.Struct); ok && !s.HasField(f.Field) {
+		errMsg := fmt.Sprintf(internal.ErrUndeclaredField, s.Name, f.Field)
+		sA.recordError(internal.NewError(f.Metadata, errMsg, internal.SemanticErr))
+	}
+	return
+}
+
+// valueOf returns the value that expr is known to construct, an empty array for array literals or an instance of the
+// struct it constructs. It is null if expr does not construct either
+func (sA *semanticAnalyser) valueOf(expr ast.Expression) object.Object {
+	if expr.Type() == ast.ArrayExpressionNode {
+		return object.NewArrayNode([]object.Object{})
+	}
+	return sA.structOf(expr)
+}
+
+// structOf returns an instance of the struct constructed by expr, to check the fields read from the variable it is
+// assigned to. It is null if expr does not construct a struct
+func (sA *semanticAnalyser) structOf(expr ast.Expression) object.Object {
+	var name string
+	switch e := expr.(type) {
+	case *ast.StructExpression:
+		name = e.Name
+	case *ast.FunctionCallExpression:
+		if e.Module != "" {
+			return object.NewNull()
+		}
+		name = e.FunctionName
+	}
+
+	if d, ok := sA.GetStruct(name); ok {
+		return d.New()
+	}
+	return object.NewNull()
+}
+
 func (sA *semanticAnalyser) analysePrefixExpression(node ast.Node) {
 	pExpr := node.(*ast.PrefixExpression)
 	sA.analyse(pExpr.Expression)
 	return
 }
 
+func (sA *semanticAnalyser) analyseSafeCallExpression(node ast.Node) {
+	sA.analyse(node.(*ast.SafeCallExpression).Call)
+	return
+}
+
 func (sA *semanticAnalyser) analyseInfixExpression(node ast.Node) {
 	pExpr := node.(*ast.InfixExpression)
 	sA.analyse(pExpr.LeftExpression)
@@ -101,35 +235,76 @@ func (sA *semanticAnalyser) analyseArrayIndexExpression(node ast.Node) {
 		return
 	}
 
+	// strings are valid indexes of maps, so are only reported if the variable is known to hold an array
+	v, _ := sA.GetVar(aIExpr.ArrayName)
 	if aIExpr.IndexExpr.Type() == ast.ArrayExpressionNode || aIExpr.IndexExpr.Type() == ast.FloatingPointExpressionNode ||
-		aIExpr.IndexExpr.Type() == ast.StringExpressionNode || aIExpr.IndexExpr.Type() == ast.BooleanExpressionNode {
+		aIExpr.IndexExpr.Type() == ast.BooleanExpressionNode ||
+		(aIExpr.IndexExpr.Type() == ast.StringExpressionNode && v != nil && v.Type() == object.ArrayObject) {
 		errMsg := fmt.Sprintf(internal.ErrInvalidIndexType, aIExpr.IndexExpr.Type())
 		sA.recordError(internal.NewError(aIExpr.Metadata, errMsg, internal.SemanticErr))
 		return
 	}
 
-	sA.Analyse(aIExpr.IndexExpr)
+	sA.analyse(aIExpr.IndexExpr)
 
 }
 
 func (sA *semanticAnalyser) analyseArrayExpression(node ast.Node) {
 	arrExpr := node.(*ast.ArrayExpression)
 	for _, expr := range arrExpr.Data {
-		if expr.Type() == ast.IdentifierExpressionNode {
-			expr := expr.(*ast.IdentifierExpression)
-			if sA.AvailableVar(expr.Name, true) {
-				errMsg := fmt.Sprintf(internal.ErrUndeclaredFunction, expr.Name)
-				sA.recordError(internal.NewError(expr.Metadata, errMsg,
-					internal.SemanticErr))
-			}
+		sA.analyse(expr)
+	}
+}
+
+// checks that the struct is declared, and that each field it is constructed with is one of its fields
+func (sA *semanticAnalyser) analyseStructExpression(node ast.Node) {
+	sExpr := node.(*ast.StructExpression)
+
+	d, ok := sA.GetStruct(sExpr.Name)
+	if !ok {
+		errMsg := fmt.Sprintf(internal.ErrUndeclaredIdentifierNode, sExpr.Name)
+		sA.recordError(internal.NewError(sExpr.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	set := make(map[string]bool, len(sExpr.Fields))
+	for _, f := range sExpr.Fields {
+		if !d.HasField(f.Name) {
+			errMsg := fmt.Sprintf(internal.ErrUndeclaredField, d.Name, f.Name)
+			sA.recordError(internal.NewError(f.Metadata, errMsg, internal.SemanticErr))
+		} else if set[f.Name] {
+			errMsg := fmt.Sprintf(internal.ErrRepeatedField, f.Name, d.Name)
+			sA.recordError(internal.NewError(f.Metadata, errMsg, internal.SemanticErr))
 		}
+		set[f.Name] = true
+	}
+
+	sA.analyseBlockExpression(sExpr.Values...)
+}
+
+func (sA *semanticAnalyser) analyseInterpolatedStringExpression(node ast.Node) {
+	for _, expr := range node.(*ast.InterpolatedStringExpression).Expressions {
+		sA.analyse(expr)
 	}
 }
 
 func (sA *semanticAnalyser) analyseFunctionCallExpression(node ast.Node) {
 	fCall := node.(*ast.FunctionCallExpression)
 
-	if uf, ok := sA.GetUserFunc(fCall.FunctionName); !ok {
+	if fCall.Module != "" {
+		sA.analyseQualifiedFunctionCallExpression(fCall)
+		return
+	}
+
+	if d, ok := sA.GetStruct(fCall.FunctionName); ok {
+		// struct construction, given a value for each field
+		if len(fCall.Parameters) != len(d.Fields) {
+			errMsg := fmt.Sprintf(internal.ErrInvalidFunctionCallParameters, fCall.FunctionName, len(d.Fields), len(fCall.Parameters))
+			sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
+			return
+		}
+
+	} else if uf, ok := sA.GetUserFunc(fCall.FunctionName); !ok {
 		// not a user defined func
 		if nf, ok := sA.GetNativeFunc(fCall.FunctionName); !ok {
 			// not a function
@@ -143,6 +318,12 @@ func (sA *semanticAnalyser) analyseFunctionCallExpression(node ast.Node) {
 			sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
 			return
 
+		} else if c, missing := sA.capabilities.Missing(nf); missing {
+			// check that the host has granted the function's capabilities
+			errMsg := fmt.Sprintf(internal.ErrMissingCapability, fCall.FunctionName, c)
+			sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
+			return
+
 		}
 
 	} else if len(fCall.Parameters) != len(uf.Parameters) {
@@ -159,6 +340,91 @@ func (sA *semanticAnalyser) analyseFunctionCallExpression(node ast.Node) {
 	return
 }
 
+// checks that a function of an imported module exists and is exported, native functions can not be qualified
+func (sA *semanticAnalyser) analyseQualifiedFunctionCallExpression(fCall *ast.FunctionCallExpression) {
+	m, ok := sA.importedModule(fCall.Module, fCall)
+	if !ok {
+		return
+	}
+
+	if uf, ok := m.Functions[fCall.FunctionName]; !ok {
+		errMsg := fmt.Sprintf(internal.ErrUndeclaredFunction, qualifiedName(fCall.Module, fCall.FunctionName))
+		sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
+		return
+
+	} else if !m.Exports[fCall.FunctionName] {
+		errMsg := fmt.Sprintf(internal.ErrUnexported, fCall.FunctionName, fCall.Module)
+		sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
+		return
+
+	} else if len(fCall.Parameters) != len(uf.Parameters) {
+		errMsg := fmt.Sprintf(internal.ErrInvalidFunctionCallParameters, qualifiedName(fCall.Module, fCall.FunctionName),
+			len(uf.Parameters), len(fCall.Parameters))
+		sA.recordError(internal.NewError(fCall.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	sA.analyseBlockExpression(fCall.Parameters...)
+	return
+}
+
+// importedModule returns the module imported as name, reporting an error at md if there is none
+func (sA *semanticAnalyser) importedModule(name string, md token.Metadata) (*symbol_table.Module, bool) {
+	m, ok := sA.GetImport(name)
+	if !ok {
+		sA.recordError(internal.NewError(md, fmt.Sprintf(internal.ErrUndeclaredModule, name), internal.SemanticErr))
+	}
+	return m, ok
+}
+
+func qualifiedName(module, name string) string {
+	return module + "." + name
+}
+
+// analyses the imported module once, in its own namespace, and makes it available by its name
+func (sA *semanticAnalyser) analyseImportStatement(node ast.Node) {
+	stmt := node.(*ast.ImportStatement)
+
+	if sA.GetScope() != 0 || sA.InFunctionCall() {
+		sA.recordError(internal.NewError(stmt.Metadata, internal.ErrImportLocation, internal.SemanticErr))
+		return
+	}
+
+	name := stmt.Name()
+	if !lexer.IsIdentifier(name) {
+		errMsg := fmt.Sprintf(internal.ErrInvalidImportName, name)
+		sA.recordError(internal.NewError(stmt.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	if _, ok := sA.GetImport(name); ok {
+		errMsg := fmt.Sprintf(internal.ErrDeclaredImport, name)
+		sA.recordError(internal.NewError(stmt.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	if stmt.Program == nil {
+		errMsg := fmt.Sprintf(internal.ErrUnresolvedImport, stmt.Path)
+		sA.recordError(internal.NewError(stmt.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	m, ok := sA.modules[stmt.Program]
+	if !ok {
+		mSA := NewSemanticAnalyser(sA.opts...)
+		mSA.modules = sA.modules
+		for _, err := range mSA.Analyse(stmt.Program) {
+			sA.recordError(err)
+		}
+
+		m = mSA.Module()
+		sA.modules[stmt.Program] = m
+	}
+
+	sA.SetImport(name, m)
+	return
+}
+
 func (sA *semanticAnalyser) analyseReturnStatement(node ast.Node) {
 	rS := node.(*ast.ReturnStatement)
 	if !sA.InFunctionCall() {
@@ -206,12 +472,48 @@ func (sA *semanticAnalyser) analyseWhileStatement(node ast.Node) {
 	return
 }
 
+func (sA *semanticAnalyser) analyseThrowStatement(node ast.Node) {
+	tS := node.(*ast.ThrowStatement)
+	sA.analyse(tS.Expression)
+	return
+}
+
+func (sA *semanticAnalyser) analyseTryStatement(node ast.Node) {
+	tStmt := node.(*ast.TryStatement)
+
+	// analyse try block
+	sA.EnterScope()
+	sA.analyseBlockStatement(tStmt.TryBlock...)
+	sA.ExitScope()
+
+	// analyse catch block, in which the caught error is declared
+	if tStmt.CatchName != nil {
+		sA.EnterScope()
+		sA.analyseVarName(tStmt.CatchName.Name, tStmt.CatchName.Metadata)
+		sA.SetVar(tStmt.CatchName.Name, object.NewNull())
+		sA.analyseBlockStatement(tStmt.CatchBlock...)
+		sA.ExitScope()
+	}
+
+	// analyse finally block
+	sA.EnterScope()
+	sA.analyseBlockStatement(tStmt.FinallyBlock...)
+	sA.ExitScope()
+
+	return
+}
+
 // checks that the variable has not been previously declared in the current scope
 func (sA *semanticAnalyser) analyseVarStatement(node ast.Node) {
 	stmt := node.(*ast.VarStatement)
 
 	// analyse expression
 	sA.analyse(stmt.Expression)
+	sA.analyseTypeAnnotation(stmt.TypeAnnotation)
+
+	if !sA.analyseVarName(stmt.IdentifierNode.Name, stmt.Metadata) {
+		return
+	}
 
 	if !sA.AvailableVar(stmt.IdentifierNode.Name, false) {
 		errMsg := fmt.Sprintf(internal.ErrDeclaredVariable, stmt.IdentifierNode.Name)
@@ -221,7 +523,33 @@ func (sA *semanticAnalyser) analyseVarStatement(node ast.Node) {
 	}
 
 	// save var
-	sA.SetVar(stmt.IdentifierNode.Name, object.NewNull())
+	sA.SetVar(stmt.IdentifierNode.Name, sA.valueOf(stmt.Expression))
+	sA.analyseExport(stmt.IdentifierNode.Name, stmt.Exported, stmt.Metadata)
+	return
+}
+
+// checks that a variable is not named after an import, as the name qualifies the module's variables and functions.
+// Imports are hoisted, so they are analysed before any variable is declared
+func (sA *semanticAnalyser) analyseVarName(name string, md token.Metadata) bool {
+	if _, ok := sA.GetImport(name); ok {
+		sA.recordError(internal.NewError(md, fmt.Sprintf(internal.ErrImportedName, name), internal.SemanticErr))
+		return false
+	}
+	return true
+}
+
+// exports a top level declaration from the module being analysed
+func (sA *semanticAnalyser) analyseExport(name string, exported bool, md token.Metadata) {
+	if !exported {
+		return
+	}
+
+	if sA.GetScope() != 0 || sA.InFunctionCall() {
+		sA.recordError(internal.NewError(md, internal.ErrExportLocation, internal.SemanticErr))
+		return
+	}
+
+	sA.Module().Exports[name] = true
 	return
 }
 
@@ -237,6 +565,57 @@ func (sA *semanticAnalyser) analyseAssignmentStatement(node ast.Node) {
 	// analyse expression
 	sA.analyse(stmt.Expression)
 
+	// the value held by the variable is only known while every assignment constructs an array, or the same struct
+	v, _ := sA.GetVar(stmt.IdentifierNode.Name)
+	if n := sA.valueOf(stmt.Expression); n.Type() != v.Type() ||
+		(n.Type() == object.StructObject && v.(*object.Struct).Name != n.(*object.Struct).Name) {
+		sA.UpdateVar(stmt.IdentifierNode.Name, object.NewNull())
+	}
+
+	return
+}
+
+func (sA *semanticAnalyser) analyseFieldAssignmentStatement(node ast.Node) {
+	stmt := node.(*ast.FieldAssignmentStatement)
+
+	if q, ok := stmt.Field.Qualifier(); ok {
+		if _, ok := sA.GetImport(q); ok {
+			errMsg := fmt.Sprintf(internal.ErrAssignImported, stmt.Field.Field, q)
+			sA.recordError(internal.NewError(stmt.Metadata, errMsg, internal.SemanticErr))
+			return
+		}
+	}
+
+	sA.analyse(stmt.Field.Target)
+	sA.analyseField(stmt.Field)
+	sA.analyse(stmt.Expression)
+	return
+}
+
+func (sA *semanticAnalyser) analyseStructDeclarationStatement(node ast.Node) {
+	sDec := node.(*ast.StructDeclarationStatement)
+
+	if sA.GetScope() != 0 || sA.InFunctionCall() {
+		sA.recordError(internal.NewError(sDec.Metadata, internal.ErrStructLocation, internal.SemanticErr))
+		return
+	}
+
+	if !sA.AvailableFunc(sDec.Name) {
+		errMsg := fmt.Sprintf(internal.ErrDeclaredFunction, sDec.Name)
+		sA.recordError(internal.NewError(sDec.Metadata, errMsg, internal.SemanticErr))
+		return
+	}
+
+	declared := make(map[string]bool, len(sDec.Fields))
+	for _, f := range sDec.Fields {
+		if declared[f.Name] {
+			errMsg := fmt.Sprintf(internal.ErrDeclaredField, f.Name, sDec.Name)
+			sA.recordError(internal.NewError(f.Metadata, errMsg, internal.SemanticErr))
+		}
+		declared[f.Name] = true
+	}
+
+	sA.SetStruct(object.NewStructDefinition(sDec.Name, sDec.FieldNames()))
 	return
 }
 
@@ -248,13 +627,20 @@ func (sA *semanticAnalyser) analyseFunctionDeclarationStatement(node ast.Node) {
 		return
 	}
 
+	for _, pt := range fDec.ParameterTypes {
+		sA.analyseTypeAnnotation(pt)
+	}
+	sA.analyseTypeAnnotation(fDec.ReturnType)
+
 	// declare func
 	sA.SetUserFunc(object.NewUserFunction(fDec.Name, make([]string, len(fDec.Parameters)), []ast.Statement{}))
+	sA.analyseExport(fDec.Name, fDec.Exported, fDec.Metadata)
 
 	// analyse function body
 	sA.EnterFunction()
 	// declare params in new scope
 	for _, p := range fDec.Parameters {
+		sA.analyseVarName(p.Name, p.Metadata)
 		sA.SetVar(p.Name, object.NewNull())
 	}
 
@@ -270,6 +656,19 @@ func (sA *semanticAnalyser) analyseFunctionCallStatement(node ast.Node) {
 	return
 }
 
+// checks that every type named in an annotation exists
+func (sA *semanticAnalyser) analyseTypeAnnotation(ta *ast.TypeAnnotation) {
+	switch {
+	case ta == nil:
+	case ta.IsArray():
+		sA.analyseTypeAnnotation(ta.Elem)
+	case !ast.BuiltinTypeNames[ta.Name]:
+		errMsg := fmt.Sprintf(internal.ErrUnknownType, ta.Name)
+		sA.recordError(internal.NewError(ta.Metadata, errMsg, internal.SemanticErr))
+	}
+	return
+}
+
 func (sA *semanticAnalyser) recordError(err error) {
 	if err != nil {
 		sA.semanticErrors = append(sA.semanticErrors, err)
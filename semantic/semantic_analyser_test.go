@@ -3,10 +3,13 @@ package semantic
 import (
 	"github.com/EricNRodriguez/yum/ast"
 	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/internal/testutil"
 	"github.com/EricNRodriguez/yum/lexer"
+	"github.com/EricNRodriguez/yum/object"
 	"github.com/EricNRodriguez/yum/parser"
 	"fmt"
 	"github.com/spf13/afero"
+	"strings"
 	"testing"
 )
 
@@ -35,6 +38,10 @@ func TestSemanticAnalyser(t *testing.T) {
 			[]byte("var x = \"hello\";x = 230;"),
 			0,
 		},
+		{
+			[]byte("var x = \"${x} ${y} ${\"${z}\"}\";"),
+			3, // embedded expressions are analysed
+		},
 		{
 			[]byte("if (a < 3) { print(23 + 33);};"),
 			1, // a not declared
@@ -71,6 +78,10 @@ func TestSemanticAnalyser(t *testing.T) {
 			[]byte("var x = [1,2,3,a];"),
 			1, // a not declared
 		},
+		{
+			[]byte("var x = [1,a+1,-b,[c]];"),
+			3, // nested elements are analysed
+		},
 		{
 			[]byte("var x = [1,2,3,4]; x = x[1];"),
 			0,
@@ -81,7 +92,11 @@ func TestSemanticAnalyser(t *testing.T) {
 		},
 		{
 			[]byte("var x = [1,2,3,4]; x = x[\"word\"];"),
-			1, // strings are not valid indexes
+			1, // strings are not valid indexes of arrays
+		},
+		{
+			[]byte("var x = jsonParse(\"{}\"); var y = [1]; y = x; var a = x[\"word\"]; var b = y[\"word\"];"),
+			0, // strings index maps, so are left to the type checker unless the variable is known to hold an array
 		},
 		{
 			[]byte("var x = [1,2,3,4]; x = x[22.33];"),
@@ -133,6 +148,34 @@ func TestSemanticAnalyser(t *testing.T) {
 			[]byte("func hello() { if (true) { print(123); return 6;};};"),
 			0, // return statement inside of function
 		},
+		{
+			[]byte("try { var x = 1; throw x; } catch (e) { print(e, x); } finally { print(e); };"),
+			2, // x and e are not declared outside of their blocks
+		},
+		{
+			[]byte("var e = 1; try { print(e); } catch (e) { var e = 2; }; throw y;"),
+			2, // e already declared in the catch block and y not declared
+		},
+		{
+			[]byte("var r = try f(1); var s = try length(1, 2);"),
+			2, // f not declared and invalid number of params
+		},
+		{
+			[]byte("struct P { x, y }; var p = P{x: 1}; var q = P(1, 2); p.y = q.x; func f(a) { return a.z; }; print(f(p));"),
+			0, // the fields of parameters are not known
+		},
+		{
+			[]byte("struct P { x, x }; struct print { a }; func P() {}; var p = P{y: 1, x: 1, x: 2}; var q = Q{};"),
+			6, // duplicate field, native and struct redeclared, unknown and repeated fields, Q not declared
+		},
+		{
+			[]byte("struct P { x }; var p = P(1, 2); var q = P(1); print(q.y); q.y = 1; r.x = 1; if (true) { struct R {}; };"),
+			5, // invalid number of fields, unknown fields, r not declared and a nested declaration
+		},
+		{
+			[]byte("struct P { x }; struct Q { y }; var p = P(1); p = Q(2); print(p.y); var q = Q{}; q = Q(1); print(q.x);"),
+			1, // p may hold either struct, q only holds a Q
+		},
 	}
 
 	var (
@@ -194,3 +237,49 @@ func TestSemanticAnalyser(t *testing.T) {
 	}
 	return
 }
+
+func TestSemanticAnalyserCapabilities(t *testing.T) {
+	tCs := []struct {
+		input   []byte
+		granted object.CapabilitySet
+		errMsgs []string
+	}{
+		{
+			[]byte("print(1);"),
+			object.AllCapabilities,
+			[]string{},
+		},
+		{
+			[]byte("print(1);"),
+			object.NewCapabilitySet(),
+			[]string{"print requires the io capability"},
+		},
+		{
+			[]byte("var x = [1,2]; print(length(x), isNull(x));"),
+			object.NewCapabilitySet(object.TimeCapability),
+			[]string{"print requires the io capability"},
+		},
+		{
+			[]byte("var x = length([1,2]);"),
+			object.NewCapabilitySet(),
+			[]string{}, // length requires no capabilities
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("test_files/capabilities/test_%v.txt", i), tC.input)
+
+		errs := NewSemanticAnalyser(WithCapabilities(tC.granted)).Analyse(prog)
+		if len(errs) != len(tC.errMsgs) {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest, i+1, len(tC.errMsgs), len(errs))
+			continue
+		}
+
+		for j, err := range errs {
+			if !strings.Contains(err.Error(), tC.errMsgs[j]) {
+				t.Errorf("test case %v | expected error containing %q, received %q", i+1, tC.errMsgs[j], err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,665 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/token"
+	"fmt"
+	"strings"
+)
+
+// maximum number of times a recursive function body is re-checked while its return type converges
+const maxInferenceIterations = 4
+
+// maximum number of nested recursive calls checked with their own argument types, deeper calls are checked with
+// arguments of any type, as otherwise calls such as f([x]) would be checked with ever growing types
+const maxRecursionDepth = 4
+
+type typeMethod func(node ast.Node) *Type
+
+// variable is the flow-sensitive type of a variable, and its annotated type if one was declared
+type variable struct {
+	t        *Type
+	declared *Type
+}
+
+type nativeSignature struct {
+	params []*Type // nil for variadic functions accepting any type
+	ret    *Type
+}
+
+var nativeSignatures = map[string]nativeSignature{
+	"length": {[]*Type{NewArrayType(AnyType)}, IntType}, // maps are never known, so are also accepted
+	"isNull": {[]*Type{AnyType}, BoolType},
+	"keys":   {[]*Type{AnyType}, NewArrayType(StringType)},
+
+	"error":        {[]*Type{StringType}, AnyType},
+	"isError":      {[]*Type{AnyType}, BoolType},
+	"errorMessage": {[]*Type{AnyType}, StringType},
+
+	"print":   {nil, NullType},
+	"write":   {nil, NullType},
+	"printf":  {nil, NullType},
+	"sprintf": {nil, StringType},
+
+	"readFile":   {[]*Type{StringType}, StringType},
+	"writeFile":  {[]*Type{StringType, StringType}, NullType},
+	"appendFile": {[]*Type{StringType, StringType}, NullType},
+	"readLines":  {[]*Type{StringType}, NewArrayType(StringType)},
+	"exists":     {[]*Type{StringType}, BoolType},
+	"listDir":    {[]*Type{StringType}, NewArrayType(StringType)},
+	"mkdir":      {[]*Type{StringType}, NullType},
+	"remove":     {[]*Type{StringType}, NullType},
+
+	"args":     {[]*Type{}, NewArrayType(StringType)},
+	"env":      {[]*Type{StringType}, AnyType}, // null if the variable is not set
+	"input":    {[]*Type{AnyType}, AnyType},    // null at the end of the input
+	"readLine": {[]*Type{}, AnyType},
+	"exit":     {[]*Type{IntType}, NullType},
+
+	"jsonParse":     {[]*Type{StringType}, AnyType},
+	"jsonStringify": {[]*Type{AnyType, IntType}, StringType},
+}
+
+// typeChecker is an optional pass, run after the semantic analyser, that infers the types of variables, function
+// parameters and return values, reporting operations that are guaranteed to fail at runtime. Variables are typed
+// flow-sensitively, as yum allows a variable to be re-assigned a value of a different type, and function bodies are
+// re-checked for each distinct set of argument types they are called with.
+type typeChecker struct {
+	methodRouter  map[ast.NodeType]typeMethod
+	scopes        []map[string]variable
+	functions     map[string]*ast.FunctionDeclarationStatement
+	returnTypes   []*Type // return type of each function currently being checked, innermost last
+	functionStack []*ast.FunctionDeclarationStatement
+	inferred      map[string]*Type
+	literalElems  map[*ast.ArrayExpression][]*Type // element types of each array literal, as joining loses detail
+	typeErrors    []error
+	reported      map[string]bool
+
+	// only report values that do not match their type annotations, used by the semantic analyser
+	annotationsOnly bool
+}
+
+func NewTypeChecker() (tC *typeChecker) {
+	tC = &typeChecker{
+		scopes:       []map[string]variable{{}},
+		functions:    make(map[string]*ast.FunctionDeclarationStatement),
+		inferred:     make(map[string]*Type),
+		literalElems: make(map[*ast.ArrayExpression][]*Type),
+		typeErrors:   make([]error, 0),
+		reported:     make(map[string]bool),
+	}
+
+	tC.methodRouter = map[ast.NodeType]typeMethod{
+		ast.ProgramNode:                      tC.checkProgram,
+		ast.IdentifierExpressionNode:         tC.checkIdentifierExpression,
+		ast.FieldExpressionNode:              tC.checkFieldExpression,
+		ast.ArrayExpressionNode:              tC.checkArrayExpression,
+		ast.ArrayIndexExpressionNode:         tC.checkArrayIndexExpression,
+		ast.StructExpressionNode:             tC.checkStructExpression,
+		ast.PrefixExpressionNode:             tC.checkPrefixExpression,
+		ast.InfixExpressionNode:              tC.checkInfixExpression,
+		ast.IntegerExpressionNode:            func(ast.Node) *Type { return IntType },
+		ast.FloatingPointExpressionNode:      func(ast.Node) *Type { return FloatType },
+		ast.StringExpressionNode:             func(ast.Node) *Type { return StringType },
+		ast.InterpolatedStringExpressionNode: tC.checkInterpolatedStringExpression,
+		ast.BooleanExpressionNode:            func(ast.Node) *Type { return BoolType },
+		ast.FunctionCallExpressionNode:       tC.checkFunctionCallExpression,
+		ast.SafeCallExpressionNode:           tC.checkSafeCallExpression,
+		ast.VarStatementNode:                 tC.checkVarStatement,
+		ast.AssignmentStatementNode:          tC.checkAssignmentStatement,
+		ast.FieldAssignmentStatementNode:     tC.checkFieldAssignmentStatement,
+		ast.ReturnStatementNode:              tC.checkReturnStatement,
+		ast.IfStatementNode:                  tC.checkIfStatement,
+		ast.WhileStatementNode:               tC.checkWhileStatement,
+		ast.ThrowStatementNode:               tC.checkThrowStatement,
+		ast.TryStatementNode:                 tC.checkTryStatement,
+		ast.FunctionDeclarationStatementNode: tC.checkFunctionDeclarationStatement,
+		ast.FunctionCallStatementNode:        tC.checkFunctionCallStatement,
+	}
+
+	return
+}
+
+// Analyse assumes that node has passed semantic analysis, undeclared identifiers are treated as having any type
+func (tC *typeChecker) Analyse(node ast.Node) []error {
+	tC.check(node)
+	return tC.typeErrors
+}
+
+// hasTypeAnnotations is true if any variable, parameter or return value in the tree rooted at node is annotated
+func hasTypeAnnotations(node ast.Node) (found bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.VarStatement:
+			found = found || n.TypeAnnotation != nil
+		case *ast.FunctionDeclarationStatement:
+			found = found || n.ReturnType != nil
+			for _, pt := range n.ParameterTypes {
+				found = found || pt != nil
+			}
+		}
+		return !found
+	})
+	return
+}
+
+func (tC *typeChecker) check(node ast.Node) *Type {
+	if method, ok := tC.methodRouter[node.Type()]; ok {
+		return method(node)
+	}
+	return AnyType
+}
+
+func (tC *typeChecker) checkProgram(node ast.Node) *Type {
+	prog := node.(*ast.Program)
+	tC.declareFunctions(prog)
+	tC.checkBlock(prog.Statements)
+	return NullType
+}
+
+// functions are global, regardless of the block they are declared in
+func (tC *typeChecker) declareFunctions(prog *ast.Program) {
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if fDec, ok := n.(*ast.FunctionDeclarationStatement); ok {
+			if _, declared := tC.functions[fDec.Name]; !declared {
+				tC.functions[fDec.Name] = fDec
+			}
+		}
+		return true
+	})
+}
+
+func (tC *typeChecker) checkBlock(stmts []ast.Statement) {
+	for _, s := range stmts {
+		tC.check(s)
+	}
+}
+
+func (tC *typeChecker) checkIdentifierExpression(node ast.Node) *Type {
+	iden := node.(*ast.IdentifierExpression)
+	if v, ok := tC.lookup(iden.Name); ok {
+		return v.t
+	}
+	return AnyType
+}
+
+// fields are not typed, and modules are checked separately
+func (tC *typeChecker) checkFieldExpression(node ast.Node) *Type {
+	tC.check(node.(*ast.FieldExpression).Target)
+	return AnyType
+}
+
+func (tC *typeChecker) checkArrayExpression(node ast.Node) *Type {
+	arrExpr := node.(*ast.ArrayExpression)
+	elems := make([]*Type, len(arrExpr.Data))
+	elem := BottomType
+	for i, e := range arrExpr.Data {
+		elems[i] = tC.check(e)
+		elem = Join(elem, elems[i])
+	}
+	tC.literalElems[arrExpr] = elems
+	return NewArrayType(elem)
+}
+
+// every value can be interpolated, so only the embedded expressions themselves are checked
+func (tC *typeChecker) checkInterpolatedStringExpression(node ast.Node) *Type {
+	for _, e := range node.(*ast.InterpolatedStringExpression).Expressions {
+		tC.check(e)
+	}
+	return StringType
+}
+
+func (tC *typeChecker) checkArrayIndexExpression(node ast.Node) *Type {
+	aIExpr := node.(*ast.ArrayIndexExpression)
+
+	// strings index maps, which are never known to the type checker
+	iT := tC.check(aIExpr.IndexExpr)
+	if iT.Known() && iT.Kind != IntKind && iT.Kind != StringKind {
+		tC.recordError(aIExpr, fmt.Sprintf(internal.ErrInvalidIndexType, iT))
+	}
+
+	arr, ok := tC.lookup(aIExpr.ArrayName)
+	arrT := arr.t
+	if !ok || !arrT.Known() {
+		return AnyType
+	}
+
+	if arrT.Kind == ArrayKind && iT.Known() && iT.Kind != IntKind {
+		tC.recordError(aIExpr, fmt.Sprintf(internal.ErrInvalidIndexType, iT))
+		return arrT.Elem
+	}
+
+	if arrT.Kind != ArrayKind {
+		tC.recordError(aIExpr, fmt.Sprintf(internal.ErrType, aIExpr.ArrayName, NewArrayType(AnyType)))
+		return AnyType
+	}
+	return arrT.Elem
+}
+
+// structs have no type of their own, so only the values of their fields are checked
+func (tC *typeChecker) checkStructExpression(node ast.Node) *Type {
+	for _, v := range node.(*ast.StructExpression).Values {
+		tC.check(v)
+	}
+	return AnyType
+}
+
+// a safe call's value is either the call's value or an error, errors have no type of their own
+func (tC *typeChecker) checkSafeCallExpression(node ast.Node) *Type {
+	tC.check(node.(*ast.SafeCallExpression).Call)
+	return AnyType
+}
+
+func (tC *typeChecker) checkPrefixExpression(node ast.Node) *Type {
+	pExpr := node.(*ast.PrefixExpression)
+	t := tC.check(pExpr.Expression)
+	if !t.Known() {
+		if pExpr.Token.Type() == token.NegateToken {
+			return BoolType
+		}
+		return t
+	}
+
+	switch {
+	case t.Numeric() && (pExpr.Token.Type() == token.AddToken || pExpr.Token.Type() == token.SubToken):
+		return t
+	case t.Kind == BoolKind && pExpr.Token.Type() == token.NegateToken:
+		return t
+	}
+
+	tC.recordError(pExpr, fmt.Sprintf(internal.ErrTypeOperation, pExpr.Token.Literal(), t))
+	return AnyType
+}
+
+func (tC *typeChecker) checkInfixExpression(node ast.Node) *Type {
+	iExpr := node.(*ast.InfixExpression)
+	lT := tC.check(iExpr.LeftExpression)
+	rT := tC.check(iExpr.RightExpression)
+
+	if t, ok := infixType(iExpr.Token.Type(), lT, rT); ok {
+		return t
+	}
+
+	tC.recordError(iExpr, fmt.Sprintf(internal.ErrTypeOperation, iExpr.Token.Literal(),
+		fmt.Sprintf("%v and %v", lT, rT)))
+	return AnyType
+}
+
+// infixType mirrors the operations supported by the evaluator, ok is false if op is guaranteed to fail
+func infixType(op token.TokenType, lT, rT *Type) (t *Type, ok bool) {
+	var (
+		arithmetic = op == token.AddToken || op == token.SubToken || op == token.MultToken || op == token.DivToken
+		comparison = op == token.LThanToken || op == token.GThanToken || op == token.LThanEqualToken ||
+			op == token.GThanEqualToken
+		equality = op == token.EqualToken || op == token.NotEqualToken
+		logical  = op == token.AndToken || op == token.OrToken
+	)
+
+	switch {
+	case lT.Kind == BottomKind || rT.Kind == BottomKind:
+		return BottomType, true
+	case !lT.Known() || !rT.Known():
+		if arithmetic {
+			return AnyType, true
+		}
+		return BoolType, true
+	case lT.Kind == IntKind && rT.Kind == IntKind:
+		if arithmetic {
+			return IntType, true
+		}
+		return BoolType, comparison || equality
+	case lT.Numeric() && rT.Numeric():
+		if arithmetic {
+			return FloatType, true
+		}
+		return BoolType, comparison || equality
+	case lT.Kind == BoolKind && rT.Kind == BoolKind:
+		return BoolType, equality || logical
+	case lT.Kind == StringKind && rT.Kind == StringKind:
+		if op == token.AddToken {
+			return StringType, true
+		}
+		return BoolType, op == token.EqualToken
+	}
+	return AnyType, false
+}
+
+func (tC *typeChecker) checkFunctionCallExpression(node ast.Node) *Type {
+	fCall := node.(*ast.FunctionCallExpression)
+
+	args := make([]*Type, len(fCall.Parameters))
+	for i, p := range fCall.Parameters {
+		args[i] = tC.check(p)
+	}
+
+	if fCall.Module != "" {
+		return AnyType // modules are checked separately
+	}
+
+	if fDec, ok := tC.functions[fCall.FunctionName]; ok {
+		if len(args) != len(fDec.Parameters) {
+			return AnyType // reported by the semantic analyser
+		}
+
+		for i, pt := range fDec.ParameterTypes {
+			if declared := TypeOf(pt); !tC.assignableExpression(declared, fCall.Parameters[i], args[i]) {
+				tC.recordAnnotationError(fCall.Parameters[i], fmt.Sprintf(internal.ErrAnnotatedType, args[i],
+					fmt.Sprintf("parameter %v of %v", fDec.Parameters[i].Name, fDec.Name), declared))
+				args[i] = declared
+			} else if !args[i].Known() {
+				args[i] = declared
+			}
+		}
+
+		if tC.annotationsOnly {
+			return TypeOf(fDec.ReturnType) // bodies are checked once, at their declaration
+		}
+
+		ret := tC.inferReturnType(fDec, args)
+		if fDec.ReturnType != nil {
+			return TypeOf(fDec.ReturnType)
+		}
+		return ret
+	}
+
+	sig, ok := nativeSignatures[fCall.FunctionName]
+	if !ok {
+		return AnyType
+	}
+
+	for i := 0; sig.params != nil && i < len(sig.params) && i < len(args); i++ {
+		if !assignable(sig.params[i], args[i]) {
+			tC.recordError(fCall, fmt.Sprintf(internal.ErrType, fCall.Parameters[i].String(), sig.params[i]))
+		}
+	}
+	return sig.ret
+}
+
+// assignable is false if a value of type got can never be used where want is expected
+func assignable(want, got *Type) bool {
+	switch {
+	case !want.Known() || !got.Known():
+		return true
+	case want.Kind == ArrayKind && got.Kind == ArrayKind:
+		return assignable(want.Elem, got.Elem)
+	default:
+		return want.Equal(got)
+	}
+}
+
+// inferReturnType checks the body of fDec with the given argument types, memoising the result. Recursive calls
+// observe the return type inferred so far, and the body is re-checked until the return type converges
+func (tC *typeChecker) inferReturnType(fDec *ast.FunctionDeclarationStatement, args []*Type) *Type {
+	depth := 0
+	for _, f := range tC.functionStack {
+		if f == fDec {
+			depth++
+		}
+	}
+
+	names := make([]string, len(args))
+	for i, a := range args {
+		if a.Kind == BottomKind || depth >= maxRecursionDepth {
+			a = AnyType
+		}
+		args[i] = a
+		names[i] = a.String()
+	}
+	key := fmt.Sprintf("%v(%v)", fDec.Name, strings.Join(names, ", "))
+
+	if t, ok := tC.inferred[key]; ok {
+		return t
+	}
+
+	tC.inferred[key] = BottomType
+	for i := 0; i < maxInferenceIterations; i++ {
+		t := tC.checkFunctionBody(fDec, args)
+		if t.Equal(tC.inferred[key]) {
+			return t
+		}
+		tC.inferred[key] = t
+	}
+
+	tC.inferred[key] = AnyType
+	return AnyType
+}
+
+func (tC *typeChecker) checkFunctionBody(fDec *ast.FunctionDeclarationStatement, args []*Type) (ret *Type) {
+	cachedScopes := tC.scopes
+
+	params := make(map[string]variable, len(fDec.Parameters))
+	for i, p := range fDec.Parameters {
+		params[p.Name] = variable{t: args[i]}
+	}
+	tC.scopes = []map[string]variable{params}
+	tC.returnTypes = append(tC.returnTypes, BottomType)
+	tC.functionStack = append(tC.functionStack, fDec)
+
+	tC.checkBlock(fDec.Body)
+
+	ret = tC.returnTypes[len(tC.returnTypes)-1]
+	if !terminates(fDec.Body) {
+		ret = Join(ret, NullType)
+		if declared := TypeOf(fDec.ReturnType); !assignable(declared, NullType) {
+			tC.recordAnnotationError(fDec, fmt.Sprintf(internal.ErrMissingReturn, fDec.Name, declared))
+		}
+	}
+
+	tC.functionStack = tC.functionStack[:len(tC.functionStack)-1]
+
+	tC.returnTypes = tC.returnTypes[:len(tC.returnTypes)-1]
+	tC.scopes = cachedScopes
+	return
+}
+
+func (tC *typeChecker) checkVarStatement(node ast.Node) *Type {
+	stmt := node.(*ast.VarStatement)
+	v := variable{t: tC.check(stmt.Expression)}
+
+	if stmt.TypeAnnotation != nil {
+		v.declared = TypeOf(stmt.TypeAnnotation)
+		tC.checkAnnotated(stmt, stmt.IdentifierNode.Name, v.declared, stmt.Expression, v.t)
+		if !v.t.Known() {
+			v.t = v.declared
+		}
+	}
+
+	tC.scopes[len(tC.scopes)-1][stmt.IdentifierNode.Name] = v
+	return NullType
+}
+
+func (tC *typeChecker) checkAssignmentStatement(node ast.Node) *Type {
+	stmt := node.(*ast.AssignmentStatement)
+	t := tC.check(stmt.Expression)
+	for s := len(tC.scopes) - 1; s >= 0; s-- {
+		if v, ok := tC.scopes[s][stmt.IdentifierNode.Name]; ok {
+			if v.declared != nil {
+				tC.checkAnnotated(stmt, stmt.IdentifierNode.Name, v.declared, stmt.Expression, t)
+				if !t.Known() {
+					t = v.declared
+				}
+			}
+			v.t = t
+			tC.scopes[s][stmt.IdentifierNode.Name] = v
+			break
+		}
+	}
+	return NullType
+}
+
+func (tC *typeChecker) checkFieldAssignmentStatement(node ast.Node) *Type {
+	stmt := node.(*ast.FieldAssignmentStatement)
+	tC.check(stmt.Field.Target)
+	tC.check(stmt.Expression)
+	return NullType
+}
+
+func (tC *typeChecker) checkReturnStatement(node ast.Node) *Type {
+	rS := node.(*ast.ReturnStatement)
+	t := NullType
+	if rS.Expression != nil {
+		t = tC.check(rS.Expression)
+	}
+
+	if len(tC.returnTypes) != 0 {
+		tC.returnTypes[len(tC.returnTypes)-1] = Join(tC.returnTypes[len(tC.returnTypes)-1], t)
+
+		fDec := tC.functionStack[len(tC.functionStack)-1]
+		if declared := TypeOf(fDec.ReturnType); !tC.assignableExpression(declared, rS.Expression, t) {
+			tC.recordAnnotationError(rS, fmt.Sprintf(internal.ErrAnnotatedType, t,
+				fmt.Sprintf("return value of %v", fDec.Name), declared))
+		}
+	}
+	return NullType
+}
+
+func (tC *typeChecker) checkCondition(md token.Metadata, cond ast.Expression) {
+	if t := tC.check(cond); t.Known() && t.Kind != BoolKind {
+		tC.recordError(md, internal.ErrConditionType)
+	}
+}
+
+func (tC *typeChecker) checkIfStatement(node ast.Node) *Type {
+	ifStmt := node.(*ast.IfStatement)
+	tC.checkCondition(ifStmt.Metadata, ifStmt.Condition)
+
+	before := tC.snapshot()
+	tC.checkNestedBlock(ifStmt.IfBlock)
+	afterIf := tC.scopes
+
+	tC.scopes = before
+	tC.checkNestedBlock(ifStmt.ElseBlock)
+
+	tC.merge(afterIf)
+	return NullType
+}
+
+func (tC *typeChecker) checkWhileStatement(node ast.Node) *Type {
+	wStmt := node.(*ast.WhileStatement)
+	tC.checkCondition(wStmt.Metadata, wStmt.Condition)
+
+	// the body may run zero or more times
+	before := tC.snapshot()
+	tC.checkNestedBlock(wStmt.Block)
+	tC.merge(before)
+	return NullType
+}
+
+func (tC *typeChecker) checkThrowStatement(node ast.Node) *Type {
+	tC.check(node.(*ast.ThrowStatement).Expression)
+	return NullType
+}
+
+func (tC *typeChecker) checkTryStatement(node ast.Node) *Type {
+	tStmt := node.(*ast.TryStatement)
+
+	// the try block may be interrupted by an error before any of its statements
+	before := tC.snapshot()
+	tC.checkNestedBlock(tStmt.TryBlock)
+	tC.merge(before)
+
+	if tStmt.CatchName != nil {
+		afterTry := tC.snapshot()
+		tC.scopes = append(tC.scopes, map[string]variable{tStmt.CatchName.Name: {t: AnyType}})
+		tC.checkBlock(tStmt.CatchBlock)
+		tC.scopes = tC.scopes[:len(tC.scopes)-1]
+		tC.merge(afterTry)
+	}
+
+	tC.checkNestedBlock(tStmt.FinallyBlock)
+	return NullType
+}
+
+// the body is checked with unknown parameter types when declared, and again for each call site
+func (tC *typeChecker) checkFunctionDeclarationStatement(node ast.Node) *Type {
+	fDec := node.(*ast.FunctionDeclarationStatement)
+	args := make([]*Type, len(fDec.Parameters))
+	for i := range args {
+		args[i] = TypeOf(fDec.ParameterTypes[i])
+	}
+
+	if tC.annotationsOnly {
+		tC.checkFunctionBody(fDec, args)
+	} else {
+		tC.inferReturnType(fDec, args)
+	}
+	return NullType
+}
+
+func (tC *typeChecker) checkFunctionCallStatement(node ast.Node) *Type {
+	tC.check(node.(*ast.FunctionCallStatement).FunctionCallExpression)
+	return NullType
+}
+
+func (tC *typeChecker) checkNestedBlock(stmts []ast.Statement) {
+	tC.scopes = append(tC.scopes, map[string]variable{})
+	tC.checkBlock(stmts)
+	tC.scopes = tC.scopes[:len(tC.scopes)-1]
+}
+
+func (tC *typeChecker) lookup(name string) (v variable, ok bool) {
+	for s := len(tC.scopes) - 1; s >= 0 && !ok; s-- {
+		v, ok = tC.scopes[s][name]
+	}
+	return
+}
+
+func (tC *typeChecker) checkAnnotated(md token.Metadata, name string, declared *Type, expr ast.Expression, t *Type) {
+	if !tC.assignableExpression(declared, expr, t) {
+		tC.recordAnnotationError(md, fmt.Sprintf(internal.ErrAnnotatedType, t, name, declared))
+	}
+}
+
+// assignableExpression extends assignable to check each element of an array literal individually
+func (tC *typeChecker) assignableExpression(want *Type, expr ast.Expression, got *Type) bool {
+	arrExpr, ok := expr.(*ast.ArrayExpression)
+	if !ok || want.Kind != ArrayKind {
+		return assignable(want, got)
+	}
+
+	for i, e := range arrExpr.Data {
+		if !tC.assignableExpression(want.Elem, e, tC.literalElems[arrExpr][i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshot returns a copy of the current scopes, leaving the originals in place
+func (tC *typeChecker) snapshot() []map[string]variable {
+	cp := make([]map[string]variable, len(tC.scopes))
+	for i, scope := range tC.scopes {
+		cp[i] = make(map[string]variable, len(scope))
+		for k, v := range scope {
+			cp[i][k] = v
+		}
+	}
+	return cp
+}
+
+// merge joins the variable types of an alternate control flow path into the current scopes
+func (tC *typeChecker) merge(other []map[string]variable) {
+	for i, scope := range tC.scopes {
+		for k, v := range scope {
+			if o, ok := other[i][k]; ok {
+				v.t = Join(v.t, o.t)
+				scope[k] = v
+			}
+		}
+	}
+}
+
+func (tC *typeChecker) recordError(md token.Metadata, msg string) {
+	if !tC.annotationsOnly {
+		tC.recordAnnotationError(md, msg)
+	}
+}
+
+func (tC *typeChecker) recordAnnotationError(md token.Metadata, msg string) {
+	err := internal.NewError(token.NewMetatadata(md.LineNumber(), md.FileName()), msg, internal.SemanticErr)
+	if !tC.reported[err.Error()] {
+		tC.reported[err.Error()] = true
+		tC.typeErrors = append(tC.typeErrors, err)
+	}
+}
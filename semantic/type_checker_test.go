@@ -0,0 +1,235 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/internal/testutil"
+	"fmt"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestTypeChecker(t *testing.T) {
+	tCs := []struct {
+		input   []byte
+		numErrs int
+	}{
+		{
+			[]byte("var x = 3 - 1; var y = x * 2.5; var z = \"a\" + \"b\";"),
+			0,
+		},
+		{
+			[]byte("var x = \"a\" - 1;"),
+			1, // strings do not support -
+		},
+		{
+			[]byte("var x = !5;"),
+			1, // ! requires a boolean
+		},
+		{
+			[]byte("var x = -true;"),
+			1, // - requires a number
+		},
+		{
+			[]byte("if (3) { print(1); };"),
+			1, // condition must be a boolean
+		},
+		{
+			[]byte("var i = 0; while (i) { i = i + 1; };"),
+			1, // condition must be a boolean
+		},
+		{
+			[]byte("var x = 3; x = \"hello\"; var y = x + \"!\";"),
+			0, // variables are typed by their latest assignment
+		},
+		{
+			[]byte("var x = 3; x = \"hello\"; var y = x - 1;"),
+			1,
+		},
+		{
+			[]byte("var x = 3; if (true) { x = \"a\"; }; var y = x - 1;"),
+			0, // x may be either an int or a string
+		},
+		{
+			[]byte("var x = 3; if (true) { x = 4; } else { x = 5; }; var y = !x;"),
+			1, // x is an int on both paths
+		},
+		{
+			[]byte("func add(a, b) { return a + b; }; var x = add(1, 2) - 1;"),
+			0,
+		},
+		{
+			[]byte("func add(a, b) { return a + b; }; var x = !add(1, 2);"),
+			1, // return type inferred as int
+		},
+		{
+			[]byte("func sub(a, b) { return a - b; }; var x = sub(\"a\", \"b\");"),
+			1, // parameter types inferred at the call site
+		},
+		{
+			[]byte("func id(a) { return a; }; var x = id(1) + 1; var y = id(\"a\") + \"b\";"),
+			0, // each call site is checked independently
+		},
+		{
+			[]byte("func fact(n) { if (n == 1) { return 1; }; return n * fact(n - 1); }; var x = !fact(5);"),
+			1, // recursive return type inferred as int
+		},
+		{
+			[]byte("func f(a) { if (a) { return 1; }; }; var x = f(true) + 1;"),
+			0, // f may return null, which is checked at runtime
+		},
+		{
+			[]byte("var x = [1, 2, 3]; var i = \"a\"; var y = x[0] + 1; var z = x[i];"),
+			1, // invalid index type
+		},
+		{
+			[]byte("var x = 1; var y = x[0];"),
+			1, // x is not an array
+		},
+		{
+			[]byte("var m = jsonParse(\"{}\"); var x = m[\"a\"]; var b = true; var y = m[b];"),
+			1, // maps are indexed by strings
+		},
+		{
+			[]byte("var x = [\"a\", \"b\"]; var y = !x[0];"),
+			1, // element type inferred as string
+		},
+		{
+			[]byte("var x = length(3);"),
+			1, // length requires an array
+		},
+		{
+			[]byte("var x = length([1]) + 1; var y = isNull(x) & true;"),
+			0,
+		},
+		{
+			[]byte("var x = 1 & true;"),
+			1,
+		},
+		{
+			[]byte("var x = \"${1 - \"a\"}\"; var y = !\"${x}\";"),
+			2, // embedded expressions are checked, and interpolated strings are strings
+		},
+		{
+			[]byte("func f() { var x = \"a\" * 2; }; f(); f();"),
+			1, // errors are reported once
+		},
+		{
+			[]byte("var x = 1; try { x = \"a\"; } catch (e) { var y = e + 1; }; var z = x - 1; throw -\"a\";"),
+			1, // x may be a string after the try block, the caught error is unknown
+		},
+		{
+			[]byte("var r = try length([1]); var s = r - 1; var t = errorMessage(r) * 2; var u = isError(r) & true;"),
+			1, // a safe call may give an error, whose message is a string
+		},
+		{
+			[]byte("struct P { x }; var p = P{x: 1 - \"a\"}; var q = P(-true); p.x = !1; var y = p.x + q - 1;"),
+			3, // the values of fields are checked, structs and fields have no known type
+		},
+		{
+			[]byte("func id(a) { return a; }; var x: string = id(1);"),
+			1, // inferred return type does not match annotation
+		},
+		{
+			[]byte("func f(x) { return f([x]); }; var y = f(1) - \"a\";"),
+			0, // argument types of recursive calls keep growing, and are widened
+		},
+		{
+			[]byte("func f(x, n) { if (n == 0) { return x; }; return f([x], n - 1); }; var y = f(1, 3) - 1;"),
+			0,
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("test_files/type_checking/test_%v.txt", i), tC.input)
+
+		if errs := NewSemanticAnalyser().Analyse(prog); len(errs) != 0 {
+			t.Fatalf(internal.ErrInvalidSemanticsEvaluationTestCases, i+1, len(errs))
+		}
+
+		if errs := NewTypeChecker().Analyse(prog); len(errs) != tC.numErrs {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest+" %v", i+1, tC.numErrs, len(errs), errs)
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	tCs := []struct {
+		input   []byte
+		numErrs int
+	}{
+		{
+			[]byte("var x: int = 3; x = 4; var y: [string] = [\"a\"]; var z: any = 1; z = \"a\";"),
+			0,
+		},
+		{
+			[]byte("var x: int = \"a\";"),
+			1, // string is not an int
+		},
+		{
+			[]byte("var x: int = 3; x = 2.5;"),
+			1, // assignment does not match annotation
+		},
+		{
+			[]byte("var x: [int] = [1, 2, \"a\"];"),
+			1, // element types do not all match
+		},
+		{
+			[]byte("var x: [int] = [];"),
+			0,
+		},
+		{
+			[]byte("var x: integer = 3;"),
+			1, // unknown type
+		},
+		{
+			[]byte("func f(a: [number]): str { return a; };"),
+			2, // unknown types
+		},
+		{
+			[]byte("func add(a: int, b: int): int { return a + b; }; var x = add(1, 2.5);"),
+			1, // argument does not match parameter annotation
+		},
+		{
+			[]byte("func name(): string { return 3; };"),
+			1, // return value does not match annotation
+		},
+		{
+			[]byte("func pos(a: int): bool { if (a > 0) { return true; }; };"),
+			1, // may not return a value
+		},
+		{
+			[]byte("func pos(a: int): bool { if (a > 0) { return true; } else { return false; }; };"),
+			0,
+		},
+		{
+			[]byte("func log(a: string): null { print(a); };"),
+			0, // falling through returns null
+		},
+		{
+			[]byte("func inc(a: int): int { return a + 1; }; var x = \"a\" - 1; var y = inc(1);"),
+			0, // only annotations are enforced without the type checking pass
+		},
+		{
+			[]byte("func id(a) { return a; }; var x: string = id(1);"),
+			0, // return types are only inferred by the type checking pass
+		},
+		{
+			[]byte("func f(x) { return f([x]); }; print(1);"),
+			0,
+		},
+		{
+			[]byte("func f(x) { return f([x]); }; var y: int = f(1);"),
+			0, // recursive calls are not followed
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for i, tC := range tCs {
+		prog := testutil.Parse(t, fs, fmt.Sprintf("test_files/type_annotations/test_%v.txt", i), tC.input)
+
+		if errs := NewSemanticAnalyser().Analyse(prog); len(errs) != tC.numErrs {
+			t.Errorf(internal.ErrInvalidNumberOfErrorsTest+" %v", i+1, tC.numErrs, len(errs), errs)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package semantic
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"fmt"
+)
+
+type Kind string
+
+const (
+	BottomKind   Kind = "bottom" // no information yet, used while inferring recursive functions
+	AnyKind      Kind = "any"    // statically unknown, checked at runtime
+	IntKind      Kind = "int"
+	FloatKind    Kind = "float"
+	BoolKind     Kind = "bool"
+	StringKind   Kind = "string"
+	NullKind     Kind = "null"
+	ArrayKind    Kind = "array"
+	FunctionKind Kind = "func"
+)
+
+// Type is the static type of a yum value, Elem is only set for arrays
+type Type struct {
+	Kind Kind
+	Elem *Type
+}
+
+var (
+	BottomType = &Type{Kind: BottomKind}
+	AnyType    = &Type{Kind: AnyKind}
+	IntType    = &Type{Kind: IntKind}
+	FloatType  = &Type{Kind: FloatKind}
+	BoolType   = &Type{Kind: BoolKind}
+	StringType = &Type{Kind: StringKind}
+	NullType   = &Type{Kind: NullKind}
+)
+
+func NewArrayType(elem *Type) *Type {
+	return &Type{
+		Kind: ArrayKind,
+		Elem: elem,
+	}
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case ArrayKind:
+		return fmt.Sprintf("[%v]", t.Elem)
+	default:
+		return string(t.Kind)
+	}
+}
+
+func (t *Type) Equal(o *Type) bool {
+	if t.Kind != o.Kind {
+		return false
+	}
+	if t.Kind == ArrayKind {
+		return t.Elem.Equal(o.Elem)
+	}
+	return true
+}
+
+// Known is false for types that can not be checked statically
+func (t *Type) Known() bool {
+	return t.Kind != AnyKind && t.Kind != BottomKind
+}
+
+func (t *Type) Numeric() bool {
+	return t.Kind == IntKind || t.Kind == FloatKind
+}
+
+// Join returns the least precise type describing values of both t and o
+func Join(t, o *Type) *Type {
+	switch {
+	case t.Kind == BottomKind:
+		return o
+	case o.Kind == BottomKind:
+		return t
+	case t.Kind == ArrayKind && o.Kind == ArrayKind:
+		return NewArrayType(Join(t.Elem, o.Elem))
+	case t.Equal(o):
+		return t
+	default:
+		return AnyType
+	}
+}
+
+var builtinTypes = map[string]*Type{
+	ast.IntTypeName:    IntType,
+	ast.FloatTypeName:  FloatType,
+	ast.BoolTypeName:   BoolType,
+	ast.StringTypeName: StringType,
+	ast.NullTypeName:   NullType,
+	ast.AnyTypeName:    AnyType,
+}
+
+// TypeOf converts a type annotation into a static type, missing annotations and unknown names have any type
+func TypeOf(ta *ast.TypeAnnotation) *Type {
+	if ta == nil {
+		return AnyType
+	}
+	if ta.IsArray() {
+		return NewArrayType(TypeOf(ta.Elem))
+	}
+	if t, ok := builtinTypes[ta.Name]; ok {
+		return t
+	}
+	return AnyType
+}
@@ -14,32 +14,73 @@ type SymbolTable interface {
 	GetVarInScope(string, int) (object.Object, bool)
 	SetUserFunc(*object.UserFunction)
 	GetNativeFunc(string) (*object.NativeFunction, bool)
+	SetNativeFunc(*object.NativeFunction)
 	GetUserFunc(string) (*object.UserFunction, bool)
+	SetStruct(*object.StructDefinition)
+	GetStruct(string) (*object.StructDefinition, bool)
 	AvailableVar(string, bool) (ok bool)
 	AvailableFunc(string) (ok bool)
 	EnterFunction()
 	ExitFunction()
 	InFunctionCall() bool
+	Frames() [][]map[string]object.Object
+	Frame(int) SymbolTable
+	Module() *Module
+	SetImport(string, *Module)
+	GetImport(string) (*Module, bool)
+	EnterModule(*Module)
+	EnterModuleFunction(*Module)
+}
+
+// Module is the namespace of a file: the functions and structs it declares, its global variables and the modules it
+// imports.
+// Only the exported functions and variables may be used by the files that import it
+type Module struct {
+	Functions map[string]*object.UserFunction
+	Structs   map[string]*object.StructDefinition
+	Globals   map[string]object.Object
+	Imports   map[string]*Module // by qualifier
+	Exports   map[string]bool
+}
+
+func NewModule() *Module {
+	return &Module{
+		Functions: make(map[string]*object.UserFunction),
+		Structs:   make(map[string]*object.StructDefinition),
+		Globals:   make(map[string]object.Object),
+		Imports:   make(map[string]*Module),
+		Exports:   make(map[string]bool),
+	}
 }
 
 type symbolTable struct {
-	nameSpace            []map[string]object.Object
-	functionDeclarations map[string]*object.UserFunction
-	nativeFunctions      map[string]*object.NativeFunction
-	cachedNameSpaces     [][]map[string]object.Object
-	cachedScope          []int
-	scope                int
+	nameSpace        []map[string]object.Object
+	module           *Module // function declarations are global to the module of the code being executed
+	nativeFunctions  map[string]*object.NativeFunction
+	function         bool // false at the top level of a module
+	cachedNameSpaces [][]map[string]object.Object
+	cachedModules    []*Module
+	cachedFunction   []bool
+	cachedScope      []int
+	scope            int
 }
 
 func NewSymbolTable() *symbolTable {
-	globalScope := make(map[string]object.Object)
+	nativeFunctions := make(map[string]*object.NativeFunction, len(object.NativeFunctions))
+	for name, f := range object.NativeFunctions {
+		nativeFunctions[name] = f
+	}
+
+	module := NewModule()
 	return &symbolTable{
-		nameSpace:            []map[string]object.Object{globalScope}, // initialise global scope
-		functionDeclarations: map[string]*object.UserFunction{},       // function declarations are global
-		nativeFunctions:      object.NativeFunctions,
-		scope:                0,
-		cachedNameSpaces:     make([][]map[string]object.Object, 0),
-		cachedScope:          make([]int, 0),
+		nameSpace:        []map[string]object.Object{module.Globals}, // initialise global scope
+		module:           module,
+		nativeFunctions:  nativeFunctions,
+		scope:            0,
+		cachedNameSpaces: make([][]map[string]object.Object, 0),
+		cachedModules:    make([]*Module, 0),
+		cachedFunction:   make([]bool, 0),
+		cachedScope:      make([]int, 0),
 	}
 }
 
@@ -93,20 +134,32 @@ func (st *symbolTable) AvailableVar(name string, includeLowerScopes bool) bool {
 }
 
 func (st *symbolTable) SetUserFunc(f *object.UserFunction) {
-	st.functionDeclarations[f.Name] = f
+	st.module.Functions[f.Name] = f
 	return
 }
 
 // checks if func is available in the current scope
-// native functions are not able to be overrided
+// native functions are not able to be overrided, and structs share the names of functions as they are constructed
+// by calling them
 func (st *symbolTable) AvailableFunc(name string) bool {
-	_, okU := st.functionDeclarations[name]
+	_, okU := st.module.Functions[name]
 	_, okN := st.nativeFunctions[name]
-	return !(okU || okN)
+	_, okS := st.module.Structs[name]
+	return !(okU || okN || okS)
 }
 
 func (st *symbolTable) GetUserFunc(name string) (o *object.UserFunction, ok bool) {
-	o, ok = st.functionDeclarations[name]
+	o, ok = st.module.Functions[name]
+	return
+}
+
+func (st *symbolTable) SetStruct(d *object.StructDefinition) {
+	st.module.Structs[d.Name] = d
+	return
+}
+
+func (st *symbolTable) GetStruct(name string) (d *object.StructDefinition, ok bool) {
+	d, ok = st.module.Structs[name]
 	return
 }
 
@@ -115,6 +168,12 @@ func (st *symbolTable) GetNativeFunc(name string) (o *object.NativeFunction, ok
 	return
 }
 
+// adds a native function to those of this table, such as the assertions available to tests
+func (st *symbolTable) SetNativeFunc(f *object.NativeFunction) {
+	st.nativeFunctions[f.Name] = f
+	return
+}
+
 func (st *symbolTable) EnterScope() {
 	st.scope++
 	st.nameSpace = append(st.nameSpace, make(map[string]object.Object))
@@ -132,22 +191,95 @@ func (st *symbolTable) GetScope() int {
 }
 
 func (st *symbolTable) EnterFunction() {
+	st.enter(st.module, make(map[string]object.Object), true)
+	return
+}
+
+// enters a function declared in module m
+func (st *symbolTable) EnterModuleFunction(m *Module) {
+	st.enter(m, make(map[string]object.Object), true)
+	return
+}
+
+// enters the top level of module m, in its global scope. It is exited by ExitFunction
+func (st *symbolTable) EnterModule(m *Module) {
+	st.enter(m, m.Globals, false)
+	return
+}
+
+func (st *symbolTable) enter(m *Module, globalScope map[string]object.Object, function bool) {
 	st.cachedNameSpaces = append(st.cachedNameSpaces, st.nameSpace)
+	st.cachedModules = append(st.cachedModules, st.module)
+	st.cachedFunction = append(st.cachedFunction, st.function)
 	st.cachedScope = append(st.cachedScope, st.scope)
-	st.nameSpace = []map[string]object.Object{make(map[string]object.Object)}
+	st.nameSpace = []map[string]object.Object{globalScope}
+	st.module = m
+	st.function = function
 	st.scope = 0
 	return
 }
 
 func (st *symbolTable) ExitFunction() {
-	st.nameSpace = st.cachedNameSpaces[len(st.cachedNameSpaces)-1]
-	st.scope = st.cachedScope[len(st.cachedScope)-1]
-	st.cachedNameSpaces = st.cachedNameSpaces[:len(st.cachedNameSpaces)-1]
-	st.cachedScope = st.cachedScope[:len(st.cachedScope)-1]
+	last := len(st.cachedNameSpaces) - 1
+	st.nameSpace = st.cachedNameSpaces[last]
+	st.module = st.cachedModules[last]
+	st.function = st.cachedFunction[last]
+	st.scope = st.cachedScope[last]
+	st.cachedNameSpaces = st.cachedNameSpaces[:last]
+	st.cachedModules = st.cachedModules[:last]
+	st.cachedFunction = st.cachedFunction[:last]
+	st.cachedScope = st.cachedScope[:last]
 	return
 }
 
-// true if current execution is within a function call, false if main file
+// true if current execution is within a function call, false at the top level of a file
 func (st *symbolTable) InFunctionCall() bool {
-	return len(st.cachedNameSpaces) != 0
+	return st.function
+}
+
+// returns the module of the code being executed
+func (st *symbolTable) Module() *Module {
+	return st.module
+}
+
+// makes m available to the current module as name
+func (st *symbolTable) SetImport(name string, m *Module) {
+	st.module.Imports[name] = m
+	return
+}
+
+func (st *symbolTable) GetImport(name string) (m *Module, ok bool) {
+	m, ok = st.module.Imports[name]
+	return
+}
+
+// returns the namespace of the main file followed by that of each function call, the current call last
+func (st *symbolTable) Frames() [][]map[string]object.Object {
+	frames := append([][]map[string]object.Object{}, st.cachedNameSpaces...)
+	return append(frames, st.nameSpace)
+}
+
+// returns a symbol table sharing the functions and variables of a frame, numbered as by Frames. Scopes and function
+// calls entered through it do not affect the original
+func (st *symbolTable) Frame(i int) SymbolTable {
+	var (
+		nameSpace = append([]map[string]object.Object{}, st.Frames()[i]...)
+		module    = st.module
+		function  = st.function
+	)
+	if i < len(st.cachedModules) {
+		module, function = st.cachedModules[i], st.cachedFunction[i]
+	}
+
+	return &symbolTable{
+		nameSpace:        nameSpace,
+		module:           module,
+		nativeFunctions:  st.nativeFunctions,
+		function:         function,
+		scope:            len(nameSpace) - 1,
+		cachedNameSpaces: make([][]map[string]object.Object, 0),
+		cachedModules:    make([]*Module, 0),
+		cachedFunction:   make([]bool, 0),
+		cachedScope:      make([]int, 0),
+	}
 }
@@ -0,0 +1,239 @@
+package test
+
+import (
+	"github.com/EricNRodriguez/yum/ast"
+	"github.com/EricNRodriguez/yum/eval"
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/module"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/semantic"
+	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FileSuffix identifies the files that contain tests
+const FileSuffix = "_test.yum"
+
+// Runner runs the test functions of yum test files, reporting their results in the style of go test
+type Runner interface {
+	// Run runs the tests of each file, returning false if any test failed or any file could not be loaded
+	Run(files ...string) bool
+}
+
+type Option func(*runner)
+
+// WithFilter only runs the tests whose names match re
+func WithFilter(re *regexp.Regexp) Option {
+	return func(r *runner) {
+		r.filter = re
+	}
+}
+
+// WithVerbose reports every test as it runs, rather than only failures
+func WithVerbose() Option {
+	return func(r *runner) {
+		r.verbose = true
+	}
+}
+
+// WithCapabilities restricts the native functions tests may call, all capabilities are granted by default
+func WithCapabilities(cs object.CapabilitySet) Option {
+	return func(r *runner) {
+		r.capabilities = cs
+	}
+}
+
+type runner struct {
+	fs           afero.Fs
+	out          io.Writer
+	filter       *regexp.Regexp
+	verbose      bool
+	capabilities object.CapabilitySet
+	now          func() time.Time
+}
+
+// NewRunner returns a runner that reads test files from fs, which is also the file system of their file functions
+func NewRunner(fs afero.Fs, out io.Writer, opts ...Option) Runner {
+	r := &runner{
+		fs:           fs,
+		out:          out,
+		capabilities: object.AllCapabilities,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Discover returns the test files in each path, directories are searched recursively
+func Discover(fs afero.Fs, paths ...string) ([]string, error) {
+	files := make([]string, 0)
+	for _, path := range paths {
+		err := afero.Walk(fs, path, func(fp string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && (fp == path || strings.HasSuffix(fp, FileSuffix)) {
+				files = append(files, fp)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// IsTest reports whether a function is a test: named test, optionally followed by a name that does not start with a
+// lower case letter, and without parameters
+func IsTest(fDec *ast.FunctionDeclarationStatement) bool {
+	if !strings.HasPrefix(fDec.Name, "test") || len(fDec.Parameters) != 0 {
+		return false
+	}
+
+	rest := []rune(strings.TrimPrefix(fDec.Name, "test"))
+	return len(rest) == 0 || !unicode.IsLower(rest[0])
+}
+
+func (r *runner) Run(files ...string) bool {
+	passed := true
+	for _, fp := range files {
+		passed = r.runFile(fp) && passed
+	}
+
+	if passed {
+		fmt.Fprintln(r.out, "PASS")
+	} else {
+		fmt.Fprintln(r.out, "FAIL")
+	}
+	return passed
+}
+
+func (r *runner) runFile(fp string) bool {
+	start := r.now()
+
+	prog, errs := r.load(fp)
+	if len(errs) != 0 {
+		for _, err := range errs {
+			fmt.Fprintln(r.out, err)
+		}
+		fmt.Fprintf(r.out, "FAIL\t%v [setup failed]\n", fp)
+		return false
+	}
+
+	tests := make([]*ast.FunctionDeclarationStatement, 0)
+	for _, stmt := range prog.Statements {
+		if fDec, ok := stmt.(*ast.FunctionDeclarationStatement); ok && IsTest(fDec) &&
+			(r.filter == nil || r.filter.MatchString(fDec.Name)) {
+			tests = append(tests, fDec)
+		}
+	}
+
+	// the program hoists function declarations, so tests are run in the order they are written
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].LineNumber() < tests[j].LineNumber()
+	})
+
+	passed := true
+	for _, fDec := range tests {
+		passed = r.runTest(prog, fDec) && passed
+	}
+
+	duration := r.now().Sub(start)
+	switch {
+	case !passed:
+		fmt.Fprintf(r.out, "FAIL\t%v\t%.3fs\n", fp, duration.Seconds())
+	case len(tests) == 0:
+		fmt.Fprintf(r.out, "ok  \t%v\t%.3fs [no tests to run]\n", fp, duration.Seconds())
+	default:
+		fmt.Fprintf(r.out, "ok  \t%v\t%.3fs\n", fp, duration.Seconds())
+	}
+	return passed
+}
+
+// runTest evaluates the program in a fresh interpreter and then calls the test
+func (r *runner) runTest(prog *ast.Program, fDec *ast.FunctionDeclarationStatement) bool {
+	if r.verbose {
+		fmt.Fprintf(r.out, "=== RUN   %v\n", fDec.Name)
+	}
+
+	start := r.now()
+	err := r.evaluate(prog, ast.NewFunctionCallExpression(fDec.Metadata, fDec.Name))
+	duration := r.now().Sub(start)
+
+	if err == nil {
+		if r.verbose {
+			fmt.Fprintf(r.out, "--- PASS: %v (%.2fs)\n", fDec.Name, duration.Seconds())
+		}
+		return true
+	}
+
+	fmt.Fprintf(r.out, "--- FAIL: %v (%.2fs)\n", fDec.Name, duration.Seconds())
+	if e, ok := err.(*internal.Error); ok {
+		fmt.Fprintf(r.out, "    %v:%v: %v\n", e.FileName(), e.LineNumber(), e.Message())
+	} else {
+		fmt.Fprintf(r.out, "    %v\n", err)
+	}
+	return false
+}
+
+// evaluate returns the first runtime error, which ends the test
+func (r *runner) evaluate(prog *ast.Program, call ast.Expression) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			rErr, ok := rec.(error)
+			if !ok {
+				panic(rec)
+			}
+			err = rErr
+		}
+	}()
+
+	e := eval.NewEvaluator(
+		eval.WithCapabilities(r.capabilities),
+		eval.WithNativeFunctions(object.TestFunctions),
+		eval.WithFileSystem(r.fs),
+		eval.WithProcess(nil, strings.NewReader("")), // tests have no input
+		eval.WithOutput(r.out),
+		eval.WithRecoverableErrors(),
+	)
+	e.Evaluate(prog)
+	if e.ExitCode() == 0 {
+		e.Evaluate(call) // the test is not called once the program's top level has exited
+	}
+
+	// a test may exit early, but only successfully
+	if code := e.ExitCode(); code != 0 {
+		return &object.ExitError{Code: code}
+	}
+	return nil
+}
+
+// load parses and analyses the test file at fp and the modules it imports
+func (r *runner) load(fp string) (*ast.Program, []error) {
+	prog, errs := module.Load(r.fs, fp)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	sA := semantic.NewSemanticAnalyser(
+		semantic.WithCapabilities(r.capabilities),
+		semantic.WithNativeFunctions(object.TestFunctions),
+	)
+	if errs = sA.Analyse(prog); len(errs) != 0 {
+		return nil, errs
+	}
+	return prog, nil
+}
@@ -0,0 +1,184 @@
+package test
+
+import (
+	"bytes"
+	"github.com/spf13/afero"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const testFile = `func add(a, b) {
+    return a + b;
+};
+
+func testAdd() {
+    assertEqual(3, add(1, 2));
+};
+
+func testSub() {
+    assertEqual(1, add(1, 2));
+};
+
+func testAssert() {
+    assert(length([1, 2]) == 2);
+    assert(add(1, 1) == 3);
+};
+
+func testFail() {
+    fail("not implemented");
+};
+
+func testing() {
+    fail("not a test");
+};
+
+func testWithParameter(x) {
+    fail("not a test");
+};
+`
+
+func newTestRunner(fs afero.Fs, out *bytes.Buffer, opts ...Option) *runner {
+	r := NewRunner(fs, out, opts...).(*runner)
+	r.now = func() time.Time { return time.Unix(0, 0) }
+	return r
+}
+
+func TestRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "math_test.yum", []byte(testFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if newTestRunner(fs, &out).Run("math_test.yum") {
+		t.Error("expected the run to fail")
+	}
+
+	expected := `--- FAIL: testSub (0.00s)
+    math_test.yum:10: expected 1 : integer, got 3 : integer
+--- FAIL: testAssert (0.00s)
+    math_test.yum:15: assertion failed
+--- FAIL: testFail (0.00s)
+    math_test.yum:19: not implemented
+FAIL	math_test.yum	0.000s
+FAIL
+`
+	if out.String() != expected {
+		t.Errorf("expected output\n%v\ngot\n%v", expected, out.String())
+	}
+}
+
+func TestRunFiltered(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "math_test.yum", []byte(testFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if !newTestRunner(fs, &out, WithFilter(regexp.MustCompile("Add")), WithVerbose()).Run("math_test.yum") {
+		t.Errorf("expected the run to pass, got\n%v", out.String())
+	}
+
+	expected := `=== RUN   testAdd
+--- PASS: testAdd (0.00s)
+ok  	math_test.yum	0.000s
+PASS
+`
+	if out.String() != expected {
+		t.Errorf("expected output\n%v\ngot\n%v", expected, out.String())
+	}
+}
+
+func TestRunInvalidFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "bad_test.yum", []byte("func testX() {\n    undeclared(1);\n};\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if newTestRunner(fs, &out).Run("bad_test.yum") {
+		t.Error("expected the run to fail")
+	}
+
+	expected := "semantic error bad_test.yum 2 | undeclared not declared\nFAIL\tbad_test.yum [setup failed]\nFAIL\n"
+	if out.String() != expected {
+		t.Errorf("expected output\n%v\ngot\n%v", expected, out.String())
+	}
+}
+
+func TestRunFileFunctions(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	src := "func testRead() {\n    writeFile(\"out.txt\", \"data\");\n    assertEqual(\"data\", readFile(\"out.txt\"));\n};\n"
+	if err := afero.WriteFile(fs, "io_test.yum", []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if !newTestRunner(fs, &out).Run("io_test.yum") {
+		t.Errorf("expected the run to pass, got\n%v", out.String())
+	}
+
+	// the test's files are written to the runner's file system
+	if ok, _ := afero.Exists(fs, "out.txt"); !ok {
+		t.Error("expected out.txt to be written to the runner's file system")
+	}
+}
+
+func TestRunCaughtFailure(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	src := "func testCaught() {\n    try {\n        assert(false);\n    } catch (e) {};\n};\n"
+	if err := afero.WriteFile(fs, "caught_test.yum", []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if newTestRunner(fs, &out).Run("caught_test.yum") {
+		t.Error("expected the run to fail")
+	}
+
+	// a failed assertion is not caught by the test's try statement
+	expected := "--- FAIL: testCaught (0.00s)\n    caught_test.yum:3: assertion failed\nFAIL\tcaught_test.yum\t0.000s\nFAIL\n"
+	if out.String() != expected {
+		t.Errorf("expected output\n%v\ngot\n%v", expected, out.String())
+	}
+}
+
+func TestRunExit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	src := "exit(2);\nfunc testCalled() {\n    writeFile(\"called.txt\", \"\");\n};\n"
+	if err := afero.WriteFile(fs, "exit_test.yum", []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if newTestRunner(fs, &out).Run("exit_test.yum") {
+		t.Error("expected the run to fail")
+	}
+
+	// the test is not called once the top level has exited
+	if ok, _ := afero.Exists(fs, "called.txt"); ok {
+		t.Error("expected testCalled not to be called")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, fp := range []string{"a_test.yum", "a.yum", "lib/b_test.yum", "lib/b.yum", "c.yum"} {
+		if err := afero.WriteFile(fs, fp, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Discover(fs, ".", "c.yum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// files named explicitly are run even without the suffix
+	expected := []string{"a_test.yum", "c.yum", "lib/b_test.yum"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
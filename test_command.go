@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/EricNRodriguez/yum/internal"
+	"github.com/EricNRodriguez/yum/object"
+	"github.com/EricNRodriguez/yum/test"
+	"flag"
+	"fmt"
+	"github.com/spf13/afero"
+	"log"
+	"os"
+	"regexp"
+)
+
+// testCommand implements yum test, running the tests of each *_test.yum file in the given files and directories
+func testCommand(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	allow := flags.String("allow", object.AllCapabilities.String(),
+		"comma separated capabilities granted to the tests' native functions")
+	run := flags.String("run", "", "only run tests whose names match this regular expression")
+	verbose := flags.Bool("v", false, "report every test, not only failures")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: yum test [-allow capabilities] [-run regexp] [-v] [path...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	caps, unknown, ok := object.ParseCapabilitySet(*allow)
+	if !ok {
+		fmt.Printf(internal.ErrUnknownCapability+"\n", unknown)
+		return 2
+	}
+
+	opts := []test.Option{test.WithCapabilities(caps)}
+	if *run != "" {
+		re, err := regexp.Compile(*run)
+		if err != nil {
+			fmt.Printf(internal.ErrInvalidTestFilter+"\n", err)
+			return 2
+		}
+		opts = append(opts, test.WithFilter(re))
+	}
+	if *verbose {
+		opts = append(opts, test.WithVerbose())
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	appFs := afero.NewOsFs()
+	files, err := test.Discover(appFs, paths...)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Println(internal.ErrNoTestFiles)
+		return 0
+	}
+
+	if !test.NewRunner(appFs, os.Stdout, opts...).Run(files...) {
+		return 1
+	}
+	return 0
+}
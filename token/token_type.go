@@ -6,12 +6,19 @@ const (
 	EOFToken     TokenType = "EOF"
 	IllegalToken TokenType = "illegal token"
 
-	FuncToken   TokenType = "func"
-	VarToken    TokenType = "var"
-	IfToken     TokenType = "if"
-	ElseToken   TokenType = "else"
-	ReturnToken TokenType = "return"
-	WhileToken  TokenType = "while"
+	FuncToken    TokenType = "func"
+	VarToken     TokenType = "var"
+	IfToken      TokenType = "if"
+	ElseToken    TokenType = "else"
+	ReturnToken  TokenType = "return"
+	WhileToken   TokenType = "while"
+	ImportToken  TokenType = "import"
+	ExportToken  TokenType = "export"
+	ThrowToken   TokenType = "throw"
+	TryToken     TokenType = "try"
+	CatchToken   TokenType = "catch"
+	FinallyToken TokenType = "finally"
+	StructToken  TokenType = "struct"
 
 	// Arithmetic operations
 	AddToken        TokenType = "+"
@@ -36,6 +43,8 @@ const (
 	// delimiters
 	SemicolonToken TokenType = ";"
 	CommaToken     TokenType = ","
+	ColonToken     TokenType = ":"
+	DotToken       TokenType = "."
 
 	LeftParenToken  TokenType = "("
 	RightParenToken TokenType = ")"
@@ -49,8 +58,26 @@ const (
 	// string
 	QuotationMarkToken TokenType = "\""
 
+	// the expressions embedded in a string are delimited by ${ and }, e.g. "total: ${a + b}"
+	InterpolationStartToken TokenType = "${"
+	InterpolationEndToken   TokenType = "interpolation end"
+
+	// within a string, \ escapes the character that follows it, e.g. \n or \"
+	EscapeToken TokenType = "escape"
+
+	// line comments run from // to the end of the line, they are not passed to the parser's grammar
+	CommentToken TokenType = "comment"
+
 	IdentifierToken    TokenType = "identifier"
 	IntegerToken       TokenType = "integer"
 	FloatingPointToken TokenType = "floating point number"
 	BooleanToken       TokenType = "boolean"
 )
+
+// Escapes maps the escape sequences of a string to the characters they stand for
+var Escapes = map[string]string{
+	`\n`: "\n",
+	`\t`: "\t",
+	`\"`: "\"",
+	`\\`: "\\",
+}